@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"maps"
+	"os"
 	"sync"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/charmbracelet/crush/internal/db"
 	"github.com/charmbracelet/crush/internal/format"
 	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/job"
 	"github.com/charmbracelet/crush/internal/llm/agent"
 	"github.com/charmbracelet/crush/internal/log"
 	"github.com/charmbracelet/crush/internal/pubsub"
@@ -25,12 +27,19 @@ import (
 	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/permission"
 	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/todo"
 )
 
+// ErrNoSessions is returned when a session is requested but the project has
+// none yet.
+var ErrNoSessions = errors.New("no sessions found for this project")
+
 type App struct {
 	Sessions    session.Service
 	Messages    message.Service
 	History     history.Service
+	Todos       todo.Service
+	Jobs        job.Service
 	Permissions permission.Service
 
 	CoderAgent agent.Service
@@ -69,12 +78,18 @@ func New(ctx context.Context, conn *sql.DB, cfg *config.Config) (*App, error) {
 	if cfg.Permissions != nil && cfg.Permissions.AllowedTools != nil {
 		allowedTools = cfg.Permissions.AllowedTools
 	}
+	var permissionRules []config.PermissionRule
+	if cfg.Permissions != nil {
+		permissionRules = cfg.Permissions.Rules
+	}
 
 	app := &App{
 		Sessions:    sessions,
 		Messages:    messages,
 		History:     files,
-		Permissions: permission.NewPermissionService(cfg.WorkingDir(), skipPermissionsRequests, allowedTools),
+		Todos:       todo.NewService(q, conn),
+		Jobs:        job.NewService(),
+		Permissions: permission.NewPermissionService(cfg.WorkingDir(), skipPermissionsRequests, allowedTools, permissionRules, q),
 		LSPClients:  make(map[string]*lsp.Client),
 
 		globalCtx: ctx,
@@ -134,14 +149,31 @@ func (app *App) Context() context.Context {
 	return app.globalCtx
 }
 
+// NonInteractiveOptions configures RunNonInteractive.
+type NonInteractiveOptions struct {
+	// Quiet hides the spinner.
+	Quiet bool
+	// OutputFormat selects how progress and results are printed.
+	OutputFormat OutputFormat
+	// SessionID continues an existing session instead of creating a new
+	// one. Its message history and summary are kept.
+	SessionID string
+}
+
 // RunNonInteractive handles the execution flow when a prompt is provided via
 // CLI flag.
-func (app *App) RunNonInteractive(ctx context.Context, prompt string, quiet bool) error {
+func (app *App) RunNonInteractive(ctx context.Context, prompt string, opts NonInteractiveOptions) error {
 	slog.Info("Running in non-interactive mode")
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	if opts.OutputFormat == "" {
+		opts.OutputFormat = OutputFormatText
+	}
+	// Structured output must not be interleaved with the spinner.
+	quiet := opts.Quiet || opts.OutputFormat != OutputFormatText
+
 	// Start spinner if not in quiet mode.
 	var spinner *format.Spinner
 	if !quiet {
@@ -158,78 +190,87 @@ func (app *App) RunNonInteractive(ctx context.Context, prompt string, quiet bool
 	}
 	defer stopSpinner()
 
-	const maxPromptLengthForTitle = 100
-	titlePrefix := "Non-interactive: "
-	var titleSuffix string
-
-	if len(prompt) > maxPromptLengthForTitle {
-		titleSuffix = prompt[:maxPromptLengthForTitle] + "..."
-	} else {
-		titleSuffix = prompt
-	}
-	title := titlePrefix + titleSuffix
-
-	sess, err := app.Sessions.Create(ctx, title)
+	sess, err := app.nonInteractiveSession(ctx, prompt, opts.SessionID)
 	if err != nil {
-		return fmt.Errorf("failed to create session for non-interactive mode: %w", err)
+		return err
 	}
-	slog.Info("Created session for non-interactive run", "session_id", sess.ID)
 
 	// Automatically approve all permission requests for this non-interactive session
 	app.Permissions.AutoApproveSession(sess.ID)
 
-	done, err := app.CoderAgent.Run(ctx, sess.ID, prompt)
+	reporter := newRunReporter(opts.OutputFormat, os.Stdout, sess.ID)
+	reporter.init(app.CoderAgent.Model().ID, app.ProviderStatus().ProviderID)
+	// A continued session already has messages, which were printed before.
+	existing, err := app.Messages.List(ctx, sess.ID)
 	if err != nil {
-		return fmt.Errorf("failed to start agent processing stream: %w", err)
+		return fmt.Errorf("failed to list session messages: %w", err)
 	}
+	reporter.skip(existing)
 
+	// Subscribe before starting the agent so no early events are missed.
 	messageEvents := app.Messages.Subscribe(ctx)
-	messageReadBytes := make(map[string]int)
+	sessionEvents := app.Sessions.Subscribe(ctx)
+	permissionEvents := app.Permissions.SubscribeNotifications(ctx)
+
+	done, err := app.CoderAgent.Run(ctx, sess.ID, prompt)
+	if err != nil {
+		return fmt.Errorf("failed to start agent processing stream: %w", err)
+	}
 
 	for {
 		select {
 		case result := <-done:
 			stopSpinner()
 
+			// Events can be dropped by slow subscribers, so reconcile with
+			// the stored messages before reporting the result.
+			if msgs, err := app.Messages.List(context.Background(), sess.ID); err == nil {
+				for _, msg := range msgs {
+					if err := reporter.message(msg); err != nil {
+						slog.Error("Non-interactive: failed to report message", "error", err)
+					}
+				}
+			}
+			if err := reporter.message(result.Message); err != nil {
+				slog.Error("Non-interactive: failed to report message", "error", err)
+				return err
+			}
+			if updated, err := app.Sessions.Get(context.Background(), sess.ID); err == nil {
+				sess = updated
+			}
+
 			if result.Error != nil {
 				if errors.Is(result.Error, context.Canceled) || errors.Is(result.Error, agent.ErrRequestCancelled) {
 					slog.Info("Non-interactive: agent processing cancelled", "session_id", sess.ID)
+					reporter.result(result.Message, sess, result.Error)
 					return nil
 				}
+				reporter.result(result.Message, sess, result.Error)
 				return fmt.Errorf("agent processing failed: %w", result.Error)
 			}
 
-			msgContent := result.Message.Content().String()
-			readBts := messageReadBytes[result.Message.ID]
-
-			if len(msgContent) < readBts {
-				slog.Error("Non-interactive: message content is shorter than read bytes", "message_length", len(msgContent), "read_bytes", readBts)
-				return fmt.Errorf("message content is shorter than read bytes: %d < %d", len(msgContent), readBts)
-			}
-			fmt.Println(msgContent[readBts:])
-			messageReadBytes[result.Message.ID] = len(msgContent)
-
+			reporter.result(result.Message, sess, nil)
 			slog.Info("Non-interactive: run completed", "session_id", sess.ID)
 			return nil
 
 		case event := <-messageEvents:
 			msg := event.Payload
-			if msg.SessionID == sess.ID && msg.Role == message.Assistant && len(msg.Parts) > 0 {
-				stopSpinner()
-
-				content := msg.Content().String()
-				readBytes := messageReadBytes[msg.ID]
-
-				if len(content) < readBytes {
-					slog.Error("Non-interactive: message content is shorter than read bytes", "message_length", len(content), "read_bytes", readBytes)
-					return fmt.Errorf("message content is shorter than read bytes: %d < %d", len(content), readBytes)
+			if msg.SessionID == sess.ID && len(msg.Parts) > 0 {
+				if msg.Role == message.Assistant {
+					stopSpinner()
+				}
+				if err := reporter.message(msg); err != nil {
+					slog.Error("Non-interactive: message content is shorter than read bytes", "error", err)
+					return err
 				}
-
-				part := content[readBytes:]
-				fmt.Print(part)
-				messageReadBytes[msg.ID] = len(content)
 			}
 
+		case event := <-sessionEvents:
+			reporter.usage(event.Payload)
+
+		case event := <-permissionEvents:
+			reporter.permission(event.Payload)
+
 		case <-ctx.Done():
 			stopSpinner()
 			return ctx.Err()
@@ -237,6 +278,56 @@ func (app *App) RunNonInteractive(ctx context.Context, prompt string, quiet bool
 	}
 }
 
+// nonInteractiveSession returns the session a non-interactive run should use:
+// the existing one when sessionID is set, or a new one titled after prompt.
+func (app *App) nonInteractiveSession(ctx context.Context, prompt, sessionID string) (session.Session, error) {
+	if sessionID != "" {
+		sess, err := app.Sessions.Get(ctx, sessionID)
+		if err != nil {
+			return session.Session{}, fmt.Errorf("failed to load session %s: %w", sessionID, err)
+		}
+		slog.Info("Continuing session for non-interactive run", "session_id", sess.ID)
+		return sess, nil
+	}
+
+	const maxPromptLengthForTitle = 100
+	titlePrefix := "Non-interactive: "
+	var titleSuffix string
+
+	if len(prompt) > maxPromptLengthForTitle {
+		titleSuffix = prompt[:maxPromptLengthForTitle] + "..."
+	} else {
+		titleSuffix = prompt
+	}
+	title := titlePrefix + titleSuffix
+
+	sess, err := app.Sessions.Create(ctx, title)
+	if err != nil {
+		return session.Session{}, fmt.Errorf("failed to create session for non-interactive mode: %w", err)
+	}
+	slog.Info("Created session for non-interactive run", "session_id", sess.ID)
+	return sess, nil
+}
+
+// LatestSession returns the most recently updated top-level session of the
+// project.
+func (app *App) LatestSession(ctx context.Context) (session.Session, error) {
+	sessions, err := app.Sessions.List(ctx)
+	if err != nil {
+		return session.Session{}, err
+	}
+	if len(sessions) == 0 {
+		return session.Session{}, ErrNoSessions
+	}
+	latest := sessions[0]
+	for _, sess := range sessions[1:] {
+		if sess.UpdatedAt > latest.UpdatedAt {
+			latest = sess
+		}
+	}
+	return latest, nil
+}
+
 func (app *App) UpdateAgentModel() error {
 	if app.CoderAgent == nil {
 		return nil
@@ -256,6 +347,8 @@ func (app *App) setupEvents() {
 	setupSubscriber(ctx, app.serviceEventsWG, "permissions", app.Permissions.Subscribe, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "permissions-notifications", app.Permissions.SubscribeNotifications, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "history", app.History.Subscribe, app.events)
+	setupSubscriber(ctx, app.serviceEventsWG, "todos", app.Todos.Subscribe, app.events)
+	setupSubscriber(ctx, app.serviceEventsWG, "jobs", app.Jobs.Subscribe, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "mcp", agent.SubscribeMCPEvents, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "lsp", SubscribeLSPEvents, app.events)
 	setupSubscriber(ctx, app.serviceEventsWG, "provider-status", app.SubscribeProviderStatus, app.events)
@@ -313,6 +406,8 @@ func (app *App) InitCoderAgent() error {
 		app.Sessions,
 		app.Messages,
 		app.History,
+		app.Todos,
+		app.Jobs,
 		app.LSPClients,
 	)
 	if err != nil {
@@ -334,10 +429,23 @@ func (app *App) Subscribe(program *tea.Program) {
 		program.Quit()
 	})
 
+	app.forwardEvents("TUI", program.Send)
+}
+
+// SubscribeHeadless delivers application events to handler until the app
+// shuts down. It is meant for front-ends that don't run a tea.Program, such
+// as the HTTP server. Only one consumer should be attached at a time.
+func (app *App) SubscribeHeadless(handler func(tea.Msg)) {
+	defer log.RecoverPanic("app.SubscribeHeadless", nil)
+
+	app.forwardEvents("headless", handler)
+}
+
+func (app *App) forwardEvents(name string, send func(tea.Msg)) {
 	app.tuiWG.Add(1)
 	tuiCtx, tuiCancel := context.WithCancel(app.globalCtx)
 	app.cleanupFuncs = append(app.cleanupFuncs, func() error {
-		slog.Debug("Cancelling TUI message handler")
+		slog.Debug("Cancelling message handler", "name", name)
 		tuiCancel()
 		app.tuiWG.Wait()
 		return nil
@@ -347,14 +455,14 @@ func (app *App) Subscribe(program *tea.Program) {
 	for {
 		select {
 		case <-tuiCtx.Done():
-			slog.Debug("TUI message handler shutting down")
+			slog.Debug("Message handler shutting down", "name", name)
 			return
 		case msg, ok := <-app.events:
 			if !ok {
-				slog.Debug("TUI message channel closed")
+				slog.Debug("Message channel closed", "name", name)
 				return
 			}
-			program.Send(msg)
+			send(msg)
 		}
 	}
 }
@@ -390,6 +498,9 @@ func (app *App) Shutdown() {
 	// Shutdown the global watcher
 	watcher.Shutdown()
 
+	// Stop background jobs so they don't outlive the application.
+	app.Jobs.Shutdown()
+
 	// Call call cleanup functions.
 	for _, cleanup := range app.cleanupFuncs {
 		if cleanup != nil {
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/message"
+)
+
+// ErrNothingToUndo is returned when no checkpoint of a session has file
+// changes left to revert.
+var ErrNothingToUndo = errors.New("no file changes to undo")
+
+// Checkpoint is a point in a session that file changes can be reverted to.
+// Every user message marks a checkpoint.
+type Checkpoint struct {
+	SessionID string
+	Message   message.Message
+	Files     []history.FileRestore
+}
+
+// UndoCheckpoint returns the most recent checkpoint of the session that still
+// has file changes to revert. Checkpoints that were already restored have no
+// changes, so calling it repeatedly walks back through the session's turns.
+func (app *App) UndoCheckpoint(ctx context.Context, sessionID string) (Checkpoint, error) {
+	msgs, err := app.Messages.List(ctx, sessionID)
+	if err != nil {
+		return Checkpoint{}, fmt.Errorf("failed to list messages: %w", err)
+	}
+	// The IDs of the messages from the checkpoint on.
+	var since []string
+	for i := len(msgs) - 1; i >= 0; i-- {
+		since = append(since, msgs[i].ID)
+		if msgs[i].Role != message.User {
+			continue
+		}
+		plan, err := app.History.RestorePlan(ctx, sessionID, since)
+		if err != nil {
+			return Checkpoint{}, err
+		}
+		if len(plan) > 0 {
+			return Checkpoint{SessionID: sessionID, Message: msgs[i], Files: plan}, nil
+		}
+	}
+	return Checkpoint{}, ErrNothingToUndo
+}
+
+// RestoreCheckpoint reverts the files of a checkpoint returned by
+// UndoCheckpoint.
+func (app *App) RestoreCheckpoint(ctx context.Context, cp Checkpoint) error {
+	if app.CoderAgent != nil && app.CoderAgent.IsSessionBusy(cp.SessionID) {
+		return fmt.Errorf("agent is busy in session %s", cp.SessionID)
+	}
+	return app.History.Restore(ctx, cp.SessionID, cp.Files)
+}
@@ -0,0 +1,313 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/session"
+)
+
+// OutputFormat controls how non-interactive runs report their progress.
+type OutputFormat string
+
+const (
+	// OutputFormatText prints the assistant's text as it streams in.
+	OutputFormatText OutputFormat = "text"
+	// OutputFormatJSON prints a single JSON document once the run finishes.
+	OutputFormatJSON OutputFormat = "json"
+	// OutputFormatStreamJSON prints one JSON event per line (NDJSON) as the
+	// run progresses.
+	OutputFormatStreamJSON OutputFormat = "stream-json"
+)
+
+// ParseOutputFormat validates an output format name.
+func ParseOutputFormat(value string) (OutputFormat, error) {
+	switch OutputFormat(value) {
+	case "", OutputFormatText:
+		return OutputFormatText, nil
+	case OutputFormatJSON, OutputFormatStreamJSON:
+		return OutputFormat(value), nil
+	default:
+		return "", fmt.Errorf("invalid output format %q (expected text, json or stream-json)", value)
+	}
+}
+
+// RunEventType identifies the kind of a non-interactive output event.
+type RunEventType string
+
+const (
+	RunEventInit       RunEventType = "init"
+	RunEventText       RunEventType = "text"
+	RunEventToolCall   RunEventType = "tool_call"
+	RunEventToolResult RunEventType = "tool_result"
+	RunEventPermission RunEventType = "permission"
+	RunEventUsage      RunEventType = "usage"
+	RunEventResult     RunEventType = "result"
+)
+
+// RunUsage reports token usage and cost for a session.
+type RunUsage struct {
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// RunEvent is a single event emitted by a non-interactive run.
+type RunEvent struct {
+	Type      RunEventType `json:"type"`
+	SessionID string       `json:"session_id"`
+	MessageID string       `json:"message_id,omitempty"`
+
+	// init
+	Model    string `json:"model,omitempty"`
+	Provider string `json:"provider,omitempty"`
+
+	// text
+	Text string `json:"text,omitempty"`
+
+	// tool_call, tool_result and permission
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+	ToolName   string          `json:"tool_name,omitempty"`
+	Input      json.RawMessage `json:"input,omitempty"`
+	Content    string          `json:"content,omitempty"`
+	Metadata   json.RawMessage `json:"metadata,omitempty"`
+	IsError    bool            `json:"is_error,omitempty"`
+	Granted    *bool           `json:"granted,omitempty"`
+
+	// usage and result
+	Usage *RunUsage `json:"usage,omitempty"`
+
+	// result
+	Result       string               `json:"result,omitempty"`
+	FinishReason message.FinishReason `json:"finish_reason,omitempty"`
+	Error        string               `json:"error,omitempty"`
+	DurationMS   int64                `json:"duration_ms,omitempty"`
+	Events       []RunEvent           `json:"events,omitempty"`
+}
+
+// runReporter turns application events into non-interactive output. Text
+// output only prints assistant text; JSON formats also report tool calls,
+// tool results, permission decisions and usage.
+type runReporter struct {
+	mu        sync.Mutex
+	format    OutputFormat
+	out       io.Writer
+	sessionID string
+	startedAt time.Time
+
+	readBytes   map[string]int
+	toolNames   map[string]string
+	seenCalls   map[string]bool
+	seenResults map[string]bool
+	lastUsage   RunUsage
+	events      []RunEvent
+}
+
+func newRunReporter(format OutputFormat, out io.Writer, sessionID string) *runReporter {
+	return &runReporter{
+		format:      format,
+		out:         out,
+		sessionID:   sessionID,
+		startedAt:   time.Now(),
+		readBytes:   make(map[string]int),
+		toolNames:   make(map[string]string),
+		seenCalls:   make(map[string]bool),
+		seenResults: make(map[string]bool),
+	}
+}
+
+func (r *runReporter) isJSON() bool {
+	return r.format == OutputFormatJSON || r.format == OutputFormatStreamJSON
+}
+
+func (r *runReporter) init(model, provider string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.emit(RunEvent{Type: RunEventInit, Model: model, Provider: provider})
+}
+
+// message reports new content of an assistant or tool message. It is safe
+// to call repeatedly with the same message; only unseen content is emitted.
+func (r *runReporter) message(msg message.Message) error {
+	if msg.SessionID != r.sessionID {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch msg.Role {
+	case message.Assistant:
+		content := msg.Content().String()
+		readBytes := r.readBytes[msg.ID]
+		if len(content) < readBytes {
+			return fmt.Errorf("message content is shorter than read bytes: %d < %d", len(content), readBytes)
+		}
+		if delta := content[readBytes:]; delta != "" {
+			r.readBytes[msg.ID] = len(content)
+			if r.isJSON() {
+				r.emit(RunEvent{Type: RunEventText, MessageID: msg.ID, Text: delta})
+			} else {
+				fmt.Fprint(r.out, delta)
+			}
+		}
+		for _, tc := range msg.ToolCalls() {
+			r.toolNames[tc.ID] = tc.Name
+			if !tc.Finished || r.seenCalls[tc.ID] || !r.isJSON() {
+				continue
+			}
+			r.seenCalls[tc.ID] = true
+			r.emit(RunEvent{
+				Type:       RunEventToolCall,
+				MessageID:  msg.ID,
+				ToolCallID: tc.ID,
+				ToolName:   tc.Name,
+				Input:      rawJSON(tc.Input),
+			})
+		}
+	case message.Tool:
+		if !r.isJSON() {
+			return nil
+		}
+		for _, tr := range msg.ToolResults() {
+			if r.seenResults[tr.ToolCallID] {
+				continue
+			}
+			r.seenResults[tr.ToolCallID] = true
+			name := tr.Name
+			if name == "" {
+				name = r.toolNames[tr.ToolCallID]
+			}
+			r.emit(RunEvent{
+				Type:       RunEventToolResult,
+				MessageID:  msg.ID,
+				ToolCallID: tr.ToolCallID,
+				ToolName:   name,
+				Content:    tr.Content,
+				Metadata:   rawJSON(tr.Metadata),
+				IsError:    tr.IsError,
+			})
+		}
+	}
+	return nil
+}
+
+// skip marks the content of msgs, which were in the session before the run,
+// as reported so that it isn't printed again.
+func (r *runReporter) skip(msgs []message.Message) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range msgs {
+		r.readBytes[msg.ID] = len(msg.Content().String())
+		for _, tc := range msg.ToolCalls() {
+			r.toolNames[tc.ID] = tc.Name
+			r.seenCalls[tc.ID] = tc.Finished
+		}
+		for _, tr := range msg.ToolResults() {
+			r.seenResults[tr.ToolCallID] = true
+		}
+	}
+}
+
+func (r *runReporter) permission(n permission.PermissionNotification) {
+	if !r.isJSON() || (!n.Granted && !n.Denied) {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	granted := n.Granted
+	r.emit(RunEvent{
+		Type:       RunEventPermission,
+		ToolCallID: n.ToolCallID,
+		ToolName:   r.toolNames[n.ToolCallID],
+		Granted:    &granted,
+	})
+}
+
+func (r *runReporter) usage(sess session.Session) {
+	if !r.isJSON() || sess.ID != r.sessionID {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	usage := usageFromSession(sess)
+	if usage == r.lastUsage {
+		return
+	}
+	r.lastUsage = usage
+	r.emit(RunEvent{Type: RunEventUsage, Usage: &usage})
+}
+
+// result emits the final event. For the JSON format this is the only thing
+// written, and it carries every event collected during the run.
+func (r *runReporter) result(final message.Message, sess session.Session, runErr error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.isJSON() {
+		fmt.Fprintln(r.out)
+		return
+	}
+
+	usage := usageFromSession(sess)
+	ev := RunEvent{
+		Type:         RunEventResult,
+		MessageID:    final.ID,
+		Result:       final.Content().String(),
+		FinishReason: final.FinishReason(),
+		Usage:        &usage,
+		DurationMS:   time.Since(r.startedAt).Milliseconds(),
+	}
+	if runErr != nil {
+		ev.IsError = true
+		ev.Error = runErr.Error()
+	}
+	if r.format == OutputFormatJSON {
+		ev.Events = r.events
+	}
+	r.write(ev)
+}
+
+func (r *runReporter) emit(ev RunEvent) {
+	switch r.format {
+	case OutputFormatStreamJSON:
+		r.write(ev)
+	case OutputFormatJSON:
+		ev.SessionID = r.sessionID
+		r.events = append(r.events, ev)
+	}
+}
+
+func (r *runReporter) write(ev RunEvent) {
+	ev.SessionID = r.sessionID
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(r.out, string(data))
+}
+
+func usageFromSession(sess session.Session) RunUsage {
+	return RunUsage{
+		PromptTokens:     sess.PromptTokens,
+		CompletionTokens: sess.CompletionTokens,
+		Cost:             sess.Cost,
+	}
+}
+
+// rawJSON returns s as raw JSON when it is valid, or as a JSON string
+// otherwise.
+func rawJSON(s string) json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	data, _ := json.Marshal(s)
+	return data
+}
@@ -0,0 +1,158 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeRunEvents(t *testing.T, data []byte) []RunEvent {
+	t.Helper()
+	var events []RunEvent
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var ev RunEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &ev))
+		events = append(events, ev)
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func TestRunReporterStreamJSON(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	r := newRunReporter(OutputFormatStreamJSON, &out, "s1")
+
+	assistant := message.Message{
+		ID:        "m1",
+		SessionID: "s1",
+		Role:      message.Assistant,
+		Parts:     []message.ContentPart{message.TextContent{Text: "Hel"}},
+	}
+	require.NoError(t, r.message(assistant))
+	assistant.Parts = []message.ContentPart{
+		message.TextContent{Text: "Hello"},
+		message.ToolCall{ID: "c1", Name: "view", Input: `{"file_path":"go.mod"}`, Finished: true},
+	}
+	require.NoError(t, r.message(assistant))
+	// Replaying the same message must not duplicate events.
+	require.NoError(t, r.message(assistant))
+
+	r.permission(permission.PermissionNotification{ToolCallID: "c1", Granted: true})
+	require.NoError(t, r.message(message.Message{
+		ID:        "m2",
+		SessionID: "s1",
+		Role:      message.Tool,
+		Parts:     []message.ContentPart{message.ToolResult{ToolCallID: "c1", Content: "module x"}},
+	}))
+	r.usage(session.Session{ID: "s1", PromptTokens: 10, CompletionTokens: 5, Cost: 0.01})
+	r.usage(session.Session{ID: "other", PromptTokens: 99})
+
+	assistant.Parts = append(assistant.Parts, message.Finish{Reason: message.FinishReasonEndTurn})
+	r.result(assistant, session.Session{ID: "s1", PromptTokens: 10, CompletionTokens: 5, Cost: 0.01}, nil)
+
+	events := decodeRunEvents(t, out.Bytes())
+	types := make([]RunEventType, len(events))
+	for i, ev := range events {
+		types[i] = ev.Type
+		require.Equal(t, "s1", ev.SessionID)
+	}
+	require.Equal(t, []RunEventType{
+		RunEventText,
+		RunEventText,
+		RunEventToolCall,
+		RunEventPermission,
+		RunEventToolResult,
+		RunEventUsage,
+		RunEventResult,
+	}, types)
+
+	require.Equal(t, "lo", events[1].Text)
+	require.JSONEq(t, `{"file_path":"go.mod"}`, string(events[2].Input))
+	require.True(t, *events[3].Granted)
+	require.Equal(t, "view", events[4].ToolName)
+	require.Equal(t, message.FinishReasonEndTurn, events[6].FinishReason)
+	require.Equal(t, "Hello", events[6].Result)
+}
+
+func TestRunReporterJSON(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	r := newRunReporter(OutputFormatJSON, &out, "s1")
+	require.NoError(t, r.message(message.Message{
+		ID:        "m1",
+		SessionID: "s1",
+		Role:      message.Assistant,
+		Parts:     []message.ContentPart{message.TextContent{Text: "done"}},
+	}))
+	r.result(message.Message{ID: "m1"}, session.Session{ID: "s1"}, nil)
+
+	events := decodeRunEvents(t, out.Bytes())
+	require.Len(t, events, 1)
+	require.Equal(t, RunEventResult, events[0].Type)
+	require.Len(t, events[0].Events, 1)
+	require.Equal(t, RunEventText, events[0].Events[0].Type)
+}
+
+func TestRunReporterSkip(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	r := newRunReporter(OutputFormatStreamJSON, &out, "s1")
+	earlier := []message.Message{
+		{
+			ID:        "m1",
+			SessionID: "s1",
+			Role:      message.Assistant,
+			Parts: []message.ContentPart{
+				message.TextContent{Text: "Earlier answer"},
+				message.ToolCall{ID: "c1", Name: "view", Finished: true},
+			},
+		},
+		{
+			ID:        "m2",
+			SessionID: "s1",
+			Role:      message.Tool,
+			Parts:     []message.ContentPart{message.ToolResult{ToolCallID: "c1", Content: "module x"}},
+		},
+	}
+	r.skip(earlier)
+	for _, msg := range earlier {
+		require.NoError(t, r.message(msg))
+	}
+	require.NoError(t, r.message(message.Message{
+		ID:        "m3",
+		SessionID: "s1",
+		Role:      message.Assistant,
+		Parts:     []message.ContentPart{message.TextContent{Text: "New answer"}},
+	}))
+
+	events := decodeRunEvents(t, out.Bytes())
+	require.Len(t, events, 1)
+	require.Equal(t, "m3", events[0].MessageID)
+	require.Equal(t, "New answer", events[0].Text)
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	t.Parallel()
+
+	f, err := ParseOutputFormat("")
+	require.NoError(t, err)
+	require.Equal(t, OutputFormatText, f)
+
+	f, err = ParseOutputFormat("stream-json")
+	require.NoError(t, err)
+	require.Equal(t, OutputFormatStreamJSON, f)
+
+	_, err = ParseOutputFormat("xml")
+	require.Error(t, err)
+}
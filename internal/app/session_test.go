@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSessionApp(t *testing.T) (*App, *sql.DB) {
+	t.Helper()
+	conn, err := db.Connect(context.Background(), t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return &App{Sessions: session.NewService(db.New(conn))}, conn
+}
+
+func TestLatestSession(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	a, conn := newTestSessionApp(t)
+
+	_, err := a.LatestSession(ctx)
+	require.ErrorIs(t, err, ErrNoSessions)
+
+	first, err := a.Sessions.Create(ctx, "first")
+	require.NoError(t, err)
+	second, err := a.Sessions.Create(ctx, "second")
+	require.NoError(t, err)
+
+	// Make the first session the most recently updated one. The trigger
+	// would reset updated_at to the current second, so drop it.
+	_, err = conn.ExecContext(ctx, "DROP TRIGGER update_sessions_updated_at")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, "UPDATE sessions SET updated_at = 100 WHERE id = ?", second.ID)
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, "UPDATE sessions SET updated_at = 200 WHERE id = ?", first.ID)
+	require.NoError(t, err)
+
+	latest, err := a.LatestSession(ctx)
+	require.NoError(t, err)
+	require.Equal(t, first.ID, latest.ID)
+
+	// Task sessions are never returned.
+	_, err = a.Sessions.CreateTaskSession(ctx, "task-1", second.ID, "task")
+	require.NoError(t, err)
+	latest, err = a.LatestSession(ctx)
+	require.NoError(t, err)
+	require.NotEqual(t, "task-1", latest.ID)
+}
+
+func TestNonInteractiveSessionReuse(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	a, _ := newTestSessionApp(t)
+
+	existing, err := a.Sessions.Create(ctx, "existing")
+	require.NoError(t, err)
+
+	sess, err := a.nonInteractiveSession(ctx, "follow up", existing.ID)
+	require.NoError(t, err)
+	require.Equal(t, existing.ID, sess.ID)
+	require.Equal(t, "existing", sess.Title)
+
+	sess, err = a.nonInteractiveSession(ctx, "fresh prompt", "")
+	require.NoError(t, err)
+	require.NotEqual(t, existing.ID, sess.ID)
+	require.Equal(t, "Non-interactive: fresh prompt", sess.Title)
+
+	_, err = a.nonInteractiveSession(ctx, "prompt", "missing")
+	require.Error(t, err)
+}
@@ -19,6 +19,7 @@ type providerRow struct {
 }
 
 func init() {
+	modelsListCmd.Flags().Bool("refresh", false, "Discover the models of providers with discover_models again")
 	modelsCmd.AddCommand(modelsListCmd)
 	modelsUseCmd.Flags().StringP("type", "t", string(config.SelectedModelTypeLarge), "Model type to update: large or small")
 	modelsUseCmd.Flags().Int64("max-tokens", 0, "Override max tokens for the selected model (optional)")
@@ -46,6 +47,14 @@ var modelsListCmd = &cobra.Command{
 			return err
 		}
 
+		if refresh, _ := cmd.Flags().GetBool("refresh"); refresh {
+			config.ForceModelDiscovery()
+			cfg, err = config.Init(cwd, "", false)
+			if err != nil {
+				return err
+			}
+		}
+
 		fmt.Fprintln(os.Stdout, "Providers:")
 		providers := make([]providerRow, 0)
 		for id, p := range cfg.Providers.Seq2() {
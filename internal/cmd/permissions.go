@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	permissionsListCmd.Flags().Bool("json", false, "Output grants as JSON")
+	permissionsRevokeCmd.Flags().Bool("all", false, "Revoke every grant")
+
+	permissionsCmd.AddCommand(permissionsListCmd)
+	permissionsCmd.AddCommand(permissionsRevokeCmd)
+	rootCmd.AddCommand(permissionsCmd)
+}
+
+var permissionsCmd = &cobra.Command{
+	Use:     "permissions",
+	Aliases: []string{"permission", "grants"},
+	Short:   "Manage always allowed permissions",
+	Long: `Manage the permissions you chose to always allow in the current project.
+Grant IDs can be abbreviated to any unique prefix.`,
+	Example: `
+# List always allowed permissions
+crush permissions list
+
+# Revoke a grant
+crush permissions revoke 3f2a
+
+# Revoke every grant
+crush permissions revoke --all
+  `,
+}
+
+var permissionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List always allowed permissions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openSessionStore(cmd)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		grants, err := permission.NewGrantStore(db.New(store.conn)).List(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(grants)
+		}
+
+		if len(grants) == 0 {
+			cmd.Println("No permissions are always allowed.")
+			return nil
+		}
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tTOOL\tACTION\tSCOPE\tGRANTED")
+		for _, g := range grants {
+			scope := fsext.PrettyPath(g.Path)
+			if g.Command != "" {
+				scope = strings.Join(strings.Fields(g.Command), " ")
+			}
+			fmt.Fprintf(
+				w,
+				"%s\t%s\t%s\t%s\t%s\n",
+				g.ID[:min(8, len(g.ID))],
+				g.ToolName,
+				g.Action,
+				scope,
+				g.CreatedAt.Format("2006-01-02 15:04"),
+			)
+		}
+		return w.Flush()
+	},
+}
+
+var permissionsRevokeCmd = &cobra.Command{
+	Use:   "revoke [id]",
+	Short: "Revoke an always allowed permission",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+		if all == (len(args) == 1) {
+			return errors.New("specify either a grant ID or --all")
+		}
+
+		store, err := openSessionStore(cmd)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		grants := permission.NewGrantStore(db.New(store.conn))
+		if all {
+			if err := grants.RevokeAll(cmd.Context()); err != nil {
+				return err
+			}
+			cmd.Println("Revoked all grants")
+			return nil
+		}
+
+		grant, err := grants.Revoke(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		cmd.Printf("Revoked %s %s grant %s\n", grant.ToolName, grant.Action, grant.ID[:min(8, len(grant.ID))])
+		return nil
+	},
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -12,6 +14,8 @@ import (
 	"github.com/charmbracelet/crush/internal/app"
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/providerstatus"
+	"github.com/charmbracelet/crush/internal/session"
 	"github.com/charmbracelet/crush/internal/tui"
 	"github.com/charmbracelet/crush/internal/version"
 	"github.com/charmbracelet/fang"
@@ -27,9 +31,12 @@ func init() {
 
 	rootCmd.Flags().BoolP("help", "h", false, "Help")
 	rootCmd.Flags().BoolP("yolo", "y", false, "Automatically accept all permissions (dangerous mode)")
+	addSessionFlags(rootCmd)
 
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(updateProvidersCmd)
+
+	config.SetProviderStarter(providerstatus.EnsureProviderReady)
 }
 
 var rootCmd = &cobra.Command{
@@ -59,6 +66,12 @@ crush run "Explain the use of context in Go"
 
 # Run in dangerous mode (auto-accept all permissions)
 crush -y
+
+# Continue the most recent session
+crush --continue
+
+# Open a specific session
+crush --session <session-id>
   `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		app, err := setupApp(cmd)
@@ -67,9 +80,14 @@ crush -y
 		}
 		defer app.Shutdown()
 
+		sess, err := resolveSession(cmd, app)
+		if err != nil {
+			return err
+		}
+
 		// Set up the TUI.
 		program := tea.NewProgram(
-			tui.New(app),
+			tui.New(app, sess),
 			tea.WithAltScreen(),
 			tea.WithContext(cmd.Context()),
 			tea.WithMouseCellMotion(),            // Use cell motion instead of all motion to reduce event flooding
@@ -158,6 +176,40 @@ func setupApp(cmd *cobra.Command) (*app.App, error) {
 	return appInstance, nil
 }
 
+func addSessionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("session", "s", "", "Continue the session with the given ID")
+	cmd.Flags().Bool("continue", false, "Continue the most recent session in this project")
+	cmd.MarkFlagsMutuallyExclusive("session", "continue")
+}
+
+// resolveSession returns the session selected with --session or --continue,
+// or nil when a new session should be started.
+func resolveSession(cmd *cobra.Command, app *app.App) (*session.Session, error) {
+	sessionID, _ := cmd.Flags().GetString("session")
+	cont, _ := cmd.Flags().GetBool("continue")
+	ctx := cmd.Context()
+
+	switch {
+	case sessionID != "":
+		sess, err := app.Sessions.Get(ctx, sessionID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("session %s not found", sessionID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load session %s: %w", sessionID, err)
+		}
+		return &sess, nil
+	case cont:
+		sess, err := app.LatestSession(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find a session to continue: %w", err)
+		}
+		return &sess, nil
+	default:
+		return nil, nil
+	}
+}
+
 func MaybePrependStdin(prompt string) (string, error) {
 	if term.IsTerminal(os.Stdin.Fd()) {
 		return prompt, nil
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"strings"
 
+	"github.com/charmbracelet/crush/internal/app"
 	"github.com/spf13/cobra"
 )
 
@@ -22,17 +23,32 @@ echo "What is this code doing?" | crush run
 
 # Run with quiet mode (no spinner)
 crush run -q "Generate a README for this project"
+
+# Ask a follow-up question in the most recent session
+crush run --continue "Now add tests for it"
+
+# Continue a specific session
+crush run --session <session-id> "What did you change?"
+
+# Emit NDJSON events for tool calls, results, usage and the final result
+crush run --output-format stream-json "Fix the failing tests"
   `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		quiet, _ := cmd.Flags().GetBool("quiet")
+		outputFormat, _ := cmd.Flags().GetString("output-format")
+
+		format, err := app.ParseOutputFormat(outputFormat)
+		if err != nil {
+			return err
+		}
 
-		app, err := setupApp(cmd)
+		appInstance, err := setupApp(cmd)
 		if err != nil {
 			return err
 		}
-		defer app.Shutdown()
+		defer appInstance.Shutdown()
 
-		if !app.Config().IsConfigured() {
+		if !appInstance.Config().IsConfigured() {
 			return fmt.Errorf("no providers configured - please run 'crush' to set up a provider interactively")
 		}
 
@@ -48,11 +64,26 @@ crush run -q "Generate a README for this project"
 			return fmt.Errorf("no prompt provided")
 		}
 
+		sess, err := resolveSession(cmd, appInstance)
+		if err != nil {
+			return err
+		}
+		var sessionID string
+		if sess != nil {
+			sessionID = sess.ID
+		}
+
 		// Run non-interactive flow using the App method
-		return app.RunNonInteractive(cmd.Context(), prompt, quiet)
+		return appInstance.RunNonInteractive(cmd.Context(), prompt, app.NonInteractiveOptions{
+			Quiet:        quiet,
+			OutputFormat: format,
+			SessionID:    sessionID,
+		})
 	},
 }
 
 func init() {
 	runCmd.Flags().BoolP("quiet", "q", false, "Hide spinner")
+	addSessionFlags(runCmd)
+	runCmd.Flags().String("output-format", string(app.OutputFormatText), "Output format: text, json or stream-json")
 }
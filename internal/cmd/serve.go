@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/charmbracelet/crush/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a headless HTTP API server",
+	Long: `Run Crush without the TUI and expose sessions, messages, the coder agent and
+permission decisions over a local HTTP/JSON API. Application events are
+streamed as Server-Sent Events from /v1/events.`,
+	Example: `
+# Serve on the default address (127.0.0.1:7878) with a generated token
+crush serve
+
+# Serve on a custom port with a token of your own
+crush serve --port 9000 --token "$CRUSH_SERVE_TOKEN"
+
+# Follow the event stream
+curl -N -H "Authorization: Bearer $CRUSH_SERVE_TOKEN" http://127.0.0.1:9000/v1/events
+  `,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, _ := cmd.Flags().GetString("host")
+		port, _ := cmd.Flags().GetInt("port")
+		token, _ := cmd.Flags().GetString("token")
+		if token == "" {
+			token = os.Getenv("CRUSH_SERVE_TOKEN")
+		}
+		generated := token == ""
+		if generated {
+			if !server.IsLoopback(host) {
+				return fmt.Errorf("a token is required to serve on %s; pass --token or set $CRUSH_SERVE_TOKEN", host)
+			}
+			token = rand.Text()
+		}
+
+		app, err := setupApp(cmd)
+		if err != nil {
+			return err
+		}
+		defer app.Shutdown()
+
+		if !app.Config().IsConfigured() {
+			return fmt.Errorf("no providers configured - please run 'crush' to set up a provider interactively")
+		}
+
+		addr := net.JoinHostPort(host, strconv.Itoa(port))
+		fmt.Fprintf(os.Stderr, "Crush API listening on http://%s\n", addr)
+		if generated {
+			fmt.Fprintf(os.Stderr, "Bearer token: %s\n", token)
+		}
+
+		srv := server.New(app, server.Options{
+			Addr:  addr,
+			Token: token,
+		})
+		return srv.ListenAndServe(cmd.Context())
+	},
+}
+
+func init() {
+	serveCmd.Flags().String("host", "127.0.0.1", "Host to bind the API server to")
+	serveCmd.Flags().IntP("port", "p", 7878, "Port to bind the API server to")
+	serveCmd.Flags().String("token", "", "Bearer token required by clients (defaults to $CRUSH_SERVE_TOKEN, or a generated one)")
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,452 @@
+package cmd
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/diff"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/transcript"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	sessionsListCmd.Flags().Bool("json", false, "Output sessions as JSON")
+	sessionsShowCmd.Flags().Bool("json", false, "Output the session as JSON")
+	sessionsExportCmd.Flags().StringP("format", "f", "markdown", "Export format (markdown, json)")
+	sessionsExportCmd.Flags().StringP("output", "o", "", "Write the export to a file instead of stdout")
+	sessionsRevertCmd.Flags().String("to", "", "ID (or unique prefix) of the message to revert to; files changed at or after it are restored")
+	sessionsRevertCmd.Flags().Bool("dry-run", false, "Show the changes without touching any files")
+	_ = sessionsRevertCmd.MarkFlagRequired("to")
+
+	sessionsCmd.AddCommand(sessionsListCmd)
+	sessionsCmd.AddCommand(sessionsShowCmd)
+	sessionsCmd.AddCommand(sessionsExportCmd)
+	sessionsCmd.AddCommand(sessionsDeleteCmd)
+	sessionsCmd.AddCommand(sessionsRenameCmd)
+	sessionsCmd.AddCommand(sessionsRevertCmd)
+	rootCmd.AddCommand(sessionsCmd)
+}
+
+var sessionsCmd = &cobra.Command{
+	Use:     "sessions",
+	Aliases: []string{"session"},
+	Short:   "Manage saved sessions",
+	Long: `Manage the sessions stored for the current project.
+Session IDs can be abbreviated to any unique prefix.`,
+	Example: `
+# List sessions in the current project
+crush sessions list
+
+# Export a session to Markdown
+crush sessions export 3f2a -o session.md
+
+# Rename a session
+crush sessions rename 3f2a "Refactor the parser"
+
+# Preview reverting files to before a message, then apply it
+crush sessions revert 3f2a --to 9c1e --dry-run
+crush sessions revert 3f2a --to 9c1e
+  `,
+}
+
+var sessionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List sessions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openSessionStore(cmd)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		sessions, err := store.sessions.List(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to list sessions: %w", err)
+		}
+
+		out := cmd.OutOrStdout()
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			if sessions == nil {
+				sessions = []session.Session{}
+			}
+			return writeIndentedJSON(out, sessions)
+		}
+
+		if len(sessions) == 0 {
+			fmt.Fprintln(out, "No sessions found.")
+			return nil
+		}
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tTITLE\tMESSAGES\tTOKENS\tCOST\tUPDATED")
+		for _, s := range sessions {
+			fmt.Fprintf(
+				tw,
+				"%s\t%s\t%d\t%d\t$%.4f\t%s\n",
+				s.ID,
+				truncateTitle(s.Title, 50),
+				s.MessageCount,
+				s.PromptTokens+s.CompletionTokens,
+				s.Cost,
+				formatUnix(s.UpdatedAt),
+			)
+		}
+		return tw.Flush()
+	},
+}
+
+var sessionsShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show details about a session",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openSessionStore(cmd)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		ctx := cmd.Context()
+		sess, err := store.find(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		msgs, err := store.messages.List(ctx, sess.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list messages: %w", err)
+		}
+
+		out := cmd.OutOrStdout()
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			return writeIndentedJSON(out, sess)
+		}
+
+		roles := make(map[message.MessageRole]int)
+		tools := make(map[string]int)
+		for _, msg := range msgs {
+			roles[msg.Role]++
+			for _, tc := range msg.ToolCalls() {
+				tools[tc.Name]++
+			}
+		}
+
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "ID:\t%s\n", sess.ID)
+		fmt.Fprintf(tw, "Title:\t%s\n", sess.Title)
+		fmt.Fprintf(tw, "Created:\t%s\n", formatUnix(sess.CreatedAt))
+		fmt.Fprintf(tw, "Updated:\t%s\n", formatUnix(sess.UpdatedAt))
+		fmt.Fprintf(
+			tw,
+			"Messages:\t%d (%d user, %d assistant, %d tool)\n",
+			len(msgs),
+			roles[message.User],
+			roles[message.Assistant],
+			roles[message.Tool],
+		)
+		fmt.Fprintf(tw, "Tokens:\t%d prompt, %d completion\n", sess.PromptTokens, sess.CompletionTokens)
+		fmt.Fprintf(tw, "Cost:\t$%.4f\n", sess.Cost)
+		if len(tools) > 0 {
+			names := make([]string, 0, len(tools))
+			for name, n := range tools {
+				names = append(names, fmt.Sprintf("%s (%d)", name, n))
+			}
+			slices.Sort(names)
+			fmt.Fprintf(tw, "Tools:\t%s\n", strings.Join(names, ", "))
+		}
+		return tw.Flush()
+	},
+}
+
+var sessionsExportCmd = &cobra.Command{
+	Use:   "export <id>",
+	Short: "Export a session transcript as Markdown or JSON",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		formatFlag, _ := cmd.Flags().GetString("format")
+		format, err := transcript.ParseFormat(formatFlag)
+		if err != nil {
+			return err
+		}
+		output, _ := cmd.Flags().GetString("output")
+
+		store, err := openSessionStore(cmd)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		sess, err := store.find(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		msgs, err := store.messages.List(cmd.Context(), sess.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list messages: %w", err)
+		}
+
+		if output == "" {
+			return transcript.Write(cmd.OutOrStdout(), format, sess, msgs)
+		}
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", output, err)
+		}
+		if err := transcript.Write(f, format, sess, msgs); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write %s: %w", output, err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to write %s: %w", output, err)
+		}
+		cmd.Printf("Exported session %s to %s\n", sess.ID, output)
+		return nil
+	},
+}
+
+var sessionsDeleteCmd = &cobra.Command{
+	Use:   "delete <id>...",
+	Short: "Delete one or more sessions and their messages",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openSessionStore(cmd)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		for _, arg := range args {
+			sess, err := store.find(cmd, arg)
+			if err != nil {
+				return err
+			}
+			if err := store.sessions.Delete(cmd.Context(), sess.ID); err != nil {
+				return fmt.Errorf("failed to delete session %s: %w", sess.ID, err)
+			}
+			cmd.Printf("Deleted session %s\n", sess.ID)
+		}
+		return nil
+	},
+}
+
+var sessionsRenameCmd = &cobra.Command{
+	Use:   "rename <id> <title>",
+	Short: "Rename a session",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		title := strings.TrimSpace(args[1])
+		if title == "" {
+			return errors.New("title must not be empty")
+		}
+
+		store, err := openSessionStore(cmd)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		sess, err := store.find(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		sess.Title = title
+		if _, err := store.sessions.Save(cmd.Context(), sess); err != nil {
+			return fmt.Errorf("failed to rename session %s: %w", sess.ID, err)
+		}
+		cmd.Printf("Renamed session %s to %q\n", sess.ID, title)
+		return nil
+	},
+}
+
+var sessionsRevertCmd = &cobra.Command{
+	Use:   "revert <id> --to <message-id>",
+	Short: "Restore files changed since a message",
+	Long: `Restore every file the agent changed at or after the given message to the
+content it had before that message. Files the agent created are deleted.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openSessionStore(cmd)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		sess, err := store.find(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		to, _ := cmd.Flags().GetString("to")
+		msg, err := store.findMessage(cmd, sess.ID, to)
+		if err != nil {
+			return err
+		}
+
+		msgs, err := store.messages.List(cmd.Context(), sess.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list messages: %w", err)
+		}
+		start := slices.IndexFunc(msgs, func(m message.Message) bool { return m.ID == msg.ID })
+		var since []string
+		for _, m := range msgs[max(start, 0):] {
+			since = append(since, m.ID)
+		}
+
+		plan, err := store.history.RestorePlan(cmd.Context(), sess.ID, since)
+		if err != nil {
+			return fmt.Errorf("failed to plan revert: %w", err)
+		}
+		if len(plan) == 0 {
+			cmd.Println("No file changes to revert.")
+			return nil
+		}
+
+		for _, restore := range plan {
+			if restore.Delete {
+				cmd.Printf("delete %s\n", restore.Path)
+				continue
+			}
+			unified, _, _ := diff.GenerateDiff(restore.Current, restore.Content, restore.Path)
+			cmd.Print(unified)
+		}
+
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			return nil
+		}
+		if err := store.history.Restore(cmd.Context(), sess.ID, plan); err != nil {
+			return err
+		}
+		cmd.Printf("Restored %d file(s)\n", len(plan))
+		return nil
+	},
+}
+
+// sessionStore gives commands access to stored sessions without starting
+// the full application (agents, LSP clients, MCP servers).
+type sessionStore struct {
+	conn     *sql.DB
+	sessions session.Service
+	messages message.Service
+	history  history.Service
+}
+
+func openSessionStore(cmd *cobra.Command) (*sessionStore, error) {
+	cwd, err := ResolveCwd(cmd)
+	if err != nil {
+		return nil, err
+	}
+	debug, _ := cmd.Root().PersistentFlags().GetBool("debug")
+	dataDir, _ := cmd.Root().PersistentFlags().GetString("data-dir")
+	cfg, err := config.Init(cwd, dataDir, debug)
+	if err != nil {
+		return nil, err
+	}
+	if err := createDotCrushDir(cfg.Options.DataDirectory); err != nil {
+		return nil, err
+	}
+	conn, err := db.Connect(cmd.Context(), cfg.Options.DataDirectory)
+	if err != nil {
+		return nil, err
+	}
+	q := db.New(conn)
+	return &sessionStore{
+		conn:     conn,
+		sessions: session.NewService(q),
+		messages: message.NewService(q),
+		history:  history.NewService(q, conn),
+	}, nil
+}
+
+func (s *sessionStore) Close() error {
+	return s.conn.Close()
+}
+
+// find looks a session up by its full ID or by a unique ID prefix.
+func (s *sessionStore) find(cmd *cobra.Command, id string) (session.Session, error) {
+	ctx := cmd.Context()
+	sess, err := s.sessions.Get(ctx, id)
+	if err == nil {
+		return sess, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return session.Session{}, fmt.Errorf("failed to load session %s: %w", id, err)
+	}
+
+	sessions, err := s.sessions.List(ctx)
+	if err != nil {
+		return session.Session{}, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	var matches []session.Session
+	for _, candidate := range sessions {
+		if strings.HasPrefix(candidate.ID, id) {
+			matches = append(matches, candidate)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return session.Session{}, fmt.Errorf("session %s not found", id)
+	case 1:
+		return matches[0], nil
+	default:
+		return session.Session{}, fmt.Errorf("session ID %q is ambiguous (%d matches)", id, len(matches))
+	}
+}
+
+// findMessage looks a message of the session up by its full ID or by a
+// unique ID prefix.
+func (s *sessionStore) findMessage(cmd *cobra.Command, sessionID, id string) (message.Message, error) {
+	msgs, err := s.messages.List(cmd.Context(), sessionID)
+	if err != nil {
+		return message.Message{}, fmt.Errorf("failed to list messages: %w", err)
+	}
+	var matches []message.Message
+	for _, msg := range msgs {
+		if msg.ID == id {
+			return msg, nil
+		}
+		if strings.HasPrefix(msg.ID, id) {
+			matches = append(matches, msg)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return message.Message{}, fmt.Errorf("message %s not found in session %s", id, sessionID)
+	case 1:
+		return matches[0], nil
+	default:
+		return message.Message{}, fmt.Errorf("message ID %q is ambiguous (%d matches)", id, len(matches))
+	}
+}
+
+func writeIndentedJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func formatUnix(ts int64) string {
+	if ts == 0 {
+		return "-"
+	}
+	return time.Unix(ts, 0).Local().Format("2006-01-02 15:04")
+}
+
+func truncateTitle(title string, limit int) string {
+	title = strings.Join(strings.Fields(title), " ")
+	runes := []rune(title)
+	if len(runes) <= limit {
+		return title
+	}
+	return string(runes[:limit-1]) + "…"
+}
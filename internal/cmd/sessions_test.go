@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/transcript"
+)
+
+func TestSessionsCommands(t *testing.T) {
+	t.Setenv("CRUSH_DISABLE_PROVIDER_AUTO_UPDATE", "1")
+	tmp := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tmp)
+	if err := os.WriteFile(filepath.Join(tmp, ".crush.json"), []byte(`{
+      "providers": {"noop": {"name": "noop", "type": "openai", "base_url": "http://127.0.0.1:9", "models": [{"id":"x"}]}}
+    }`), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	// seed the project database
+	ctx := context.Background()
+	dataDir := filepath.Join(tmp, ".crush")
+	if err := createDotCrushDir(dataDir); err != nil {
+		t.Fatalf("create data dir: %v", err)
+	}
+	conn, err := db.Connect(ctx, dataDir)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	q := db.New(conn)
+	sess, err := session.NewService(q).Create(ctx, "original title")
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	msg, err := message.NewService(q).Create(ctx, sess.ID, message.CreateMessageParams{
+		Role:  message.User,
+		Parts: []message.ContentPart{message.TextContent{Text: "hello there"}},
+	})
+	if err != nil {
+		t.Fatalf("create message: %v", err)
+	}
+	// the agent created a file while answering the message
+	created := filepath.Join(tmp, "created.txt")
+	if _, err := history.NewService(q, conn).CreateNew(ctx, sess.ID, msg.ID, created); err != nil {
+		t.Fatalf("create file: %v", err)
+	}
+	if err := os.WriteFile(created, []byte("new\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	conn.Close()
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	sessionsCmd.SetOut(&buf)
+
+	// rename using an ID prefix
+	rootCmd.SetArgs([]string{"sessions", "rename", sess.ID[:8], "renamed", "-c", tmp})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("rename: %v\noutput: %s", err, buf.String())
+	}
+
+	buf.Reset()
+	rootCmd.SetArgs([]string{"sessions", "list", "--json", "-c", tmp})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	var listed []session.Session
+	if err := json.Unmarshal(buf.Bytes(), &listed); err != nil {
+		t.Fatalf("decode list: %v\noutput: %s", err, buf.String())
+	}
+	if len(listed) != 1 || listed[0].Title != "renamed" || listed[0].MessageCount != 1 {
+		t.Fatalf("unexpected sessions: %+v", listed)
+	}
+
+	exportPath := filepath.Join(tmp, "export.json")
+	rootCmd.SetArgs([]string{"sessions", "export", sess.ID, "-f", "json", "-o", exportPath, "-c", tmp})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	bts, err := os.ReadFile(exportPath)
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	var exported transcript.Transcript
+	if err := json.Unmarshal(bts, &exported); err != nil {
+		t.Fatalf("decode export: %v", err)
+	}
+	if len(exported.Messages) != 1 || exported.Messages[0].Content().Text != "hello there" {
+		t.Fatalf("unexpected export: %s", bts)
+	}
+
+	buf.Reset()
+	rootCmd.SetArgs([]string{"sessions", "revert", sess.ID, "--to", msg.ID[:8], "--dry-run", "-c", tmp})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("revert dry run: %v", err)
+	}
+	if !strings.Contains(buf.String(), "delete "+created) {
+		t.Fatalf("expected revert preview, got: %s", buf.String())
+	}
+	if _, err := os.Stat(created); err != nil {
+		t.Fatalf("dry run must not touch files: %v", err)
+	}
+
+	rootCmd.SetArgs([]string{"sessions", "revert", sess.ID, "--to", msg.ID, "--dry-run=false", "-c", tmp})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("revert: %v", err)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be deleted, got: %v", created, err)
+	}
+
+	buf.Reset()
+	rootCmd.SetArgs([]string{"sessions", "delete", sess.ID, "-c", tmp})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Deleted session "+sess.ID) {
+		t.Fatalf("expected delete confirmation, got: %s", buf.String())
+	}
+
+	rootCmd.SetArgs([]string{"sessions", "show", sess.ID, "-c", tmp})
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatalf("expected show to fail for a deleted session")
+	}
+}
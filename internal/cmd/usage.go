@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/usage"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	usageCmd.Flags().StringP("by", "b", "day", "Group usage by day, week, session, model or provider")
+	usageCmd.Flags().StringP("since", "s", "", "Only include usage since a date (2006-01-02) or a period ago (7d, 4w, 12h)")
+	usageCmd.Flags().StringP("format", "f", "table", "Output format (table, csv, json)")
+	rootCmd.AddCommand(usageCmd)
+}
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Report token usage and cost",
+	Long: `Report the token usage and cost of the model calls made in the current
+project, including cache reads and writes, aggregated by time, session, model
+or provider.`,
+	Example: `
+# Daily spend
+crush usage
+
+# Spend per model over the last 30 days
+crush usage --by model --since 30d
+
+# Weekly usage as CSV
+crush usage --by week --format csv > usage.csv
+  `,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		byFlag, _ := cmd.Flags().GetString("by")
+		by, err := usage.ParseGroupBy(byFlag)
+		if err != nil {
+			return err
+		}
+		formatFlag, _ := cmd.Flags().GetString("format")
+		format, err := usage.ParseFormat(formatFlag)
+		if err != nil {
+			return err
+		}
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		since, err := parseSince(sinceFlag, time.Now())
+		if err != nil {
+			return err
+		}
+
+		store, err := openSessionStore(cmd)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		records, err := usage.Load(cmd.Context(), db.New(store.conn), since)
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 && format == usage.FormatTable {
+			cmd.Println("No usage recorded.")
+			return nil
+		}
+		return usage.Write(cmd.OutOrStdout(), format, usage.Aggregate(records, by))
+	},
+}
+
+// parseSince accepts a date, a Go duration or a number of days or weeks
+// before now. An empty value means all time.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if n, ok := strings.CutSuffix(value, "d"); ok {
+		if days, err := strconv.Atoi(n); err == nil && days >= 0 {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if n, ok := strings.CutSuffix(value, "w"); ok {
+		if weeks, err := strconv.Atoi(n); err == nil && weeks >= 0 {
+			return now.AddDate(0, 0, -7*weeks), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since value %q (expected a date like 2006-01-02 or a period like 7d, 4w or 12h)", value)
+}
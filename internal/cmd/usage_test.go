@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSince(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2025, 10, 16, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"", time.Time{}},
+		{"2025-10-01", time.Date(2025, 10, 1, 0, 0, 0, 0, time.Local)},
+		{"7d", now.AddDate(0, 0, -7)},
+		{"2w", now.AddDate(0, 0, -14)},
+		{"12h", now.Add(-12 * time.Hour)},
+	}
+	for _, tt := range tests {
+		got, err := parseSince(tt.value, now)
+		require.NoError(t, err, tt.value)
+		require.True(t, tt.want.Equal(got), "%s: got %s, want %s", tt.value, got, tt.want)
+	}
+
+	for _, value := range []string{"yesterday", "-3d", "10/01/2025"} {
+		_, err := parseSince(value, now)
+		require.Error(t, err, value)
+	}
+}
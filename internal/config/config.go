@@ -115,6 +115,61 @@ type SelectedModel struct {
 
 	// Used by anthropic models that can reason to indicate if the model should think.
 	Think bool `json:"think,omitempty" jsonschema:"description=Enable thinking mode for Anthropic models that support reasoning"`
+
+	// Models to switch to, in order, when this one keeps failing.
+	Fallbacks []SelectedModel `json:"fallbacks,omitempty" jsonschema:"description=Models to switch to in order when the provider still fails after retries or returns a server error"`
+}
+
+// TypeOpenAIResponses is the provider type for OpenAI's Responses API.
+const TypeOpenAIResponses catwalk.Type = "openai-responses"
+
+// TypeReplay is the provider type that records the responses of another
+// provider and replays them offline.
+const TypeReplay catwalk.Type = "replay"
+
+type ReplayMode string
+
+const (
+	ReplayModeRecord ReplayMode = "record"
+	ReplayModeReplay ReplayMode = "replay"
+)
+
+// ReplayConfig configures a replay provider.
+type ReplayConfig struct {
+	// File the responses are recorded to and replayed from, relative to the
+	// working directory.
+	Cassette string `json:"cassette" jsonschema:"description=File the responses are recorded to and replayed from,example=testdata/session.json"`
+	// Whether to record or replay responses.
+	Mode ReplayMode `json:"mode,omitempty" jsonschema:"description=Whether to record responses or replay recorded ones,enum=record,enum=replay,default=replay"`
+	// The provider responses are recorded from.
+	Provider string `json:"provider,omitempty" jsonschema:"description=Provider to record responses from,example=anthropic"`
+}
+
+// CacheStrategy decides which parts of a request are marked as prompt cache
+// breakpoints.
+type CacheStrategy string
+
+const (
+	// CacheStrategyNone doesn't mark anything for caching.
+	CacheStrategyNone CacheStrategy = "none"
+	// CacheStrategySystem caches the system prompt.
+	CacheStrategySystem CacheStrategy = "system"
+	// CacheStrategySystemTools caches the system prompt and the tools.
+	CacheStrategySystemTools CacheStrategy = "system_tools"
+	// CacheStrategyRolling caches the system prompt, the tools and the most
+	// recent messages, so each turn reads the conversation so far from the
+	// cache.
+	CacheStrategyRolling CacheStrategy = "rolling"
+)
+
+// CacheConfig configures prompt caching for providers that take explicit
+// cache breakpoints, such as Anthropic. Other providers cache on their own.
+type CacheConfig struct {
+	// Which parts of a request to cache.
+	Strategy CacheStrategy `json:"strategy,omitempty" jsonschema:"description=Which parts of a request to mark for prompt caching,enum=none,enum=system,enum=system_tools,enum=rolling,default=rolling"`
+	// How many of the most recent messages to cache with the rolling
+	// strategy.
+	Messages int `json:"messages,omitempty" jsonschema:"description=Number of most recent messages to cache with the rolling strategy,default=2,minimum=1,maximum=2"`
 }
 
 type ProviderConfig struct {
@@ -125,7 +180,7 @@ type ProviderConfig struct {
 	// The provider's API endpoint.
 	BaseURL string `json:"base_url,omitempty" jsonschema:"description=Base URL for the provider's API,format=uri,example=https://api.openai.com/v1"`
 	// The provider type, e.g. "openai", "anthropic", etc. if empty it defaults to openai.
-	Type catwalk.Type `json:"type,omitempty" jsonschema:"description=Provider type that determines the API format,enum=openai,enum=anthropic,enum=gemini,enum=azure,enum=vertexai,default=openai"`
+	Type catwalk.Type `json:"type,omitempty" jsonschema:"description=Provider type that determines the API format,enum=openai,enum=openai-responses,enum=anthropic,enum=gemini,enum=azure,enum=vertexai,enum=replay,default=openai"`
 	// The provider's API key.
 	APIKey string `json:"api_key,omitempty" jsonschema:"description=API key for authentication with the provider,example=$OPENAI_API_KEY"`
 	// Marks the provider as disabled.
@@ -139,6 +194,15 @@ type ProviderConfig struct {
 	// Optional health path relative to base_url used to confirm readiness.
 	StartupHealthPath string `json:"startup_health_path,omitempty" jsonschema:"description=Relative path appended to the base URL to check readiness,default=/models"`
 
+	// Ask the provider for its models on startup.
+	DiscoverModels bool `json:"discover_models,omitempty" jsonschema:"description=Discover the models served by an Ollama or OpenAI-compatible provider instead of listing them,default=false"`
+
+	// Record or replay the responses of another provider.
+	Replay *ReplayConfig `json:"replay,omitempty" jsonschema:"description=Record and replay settings for replay providers"`
+
+	// Prompt caching settings.
+	Cache *CacheConfig `json:"cache,omitempty" jsonschema:"description=Prompt caching settings for providers that take explicit cache breakpoints"`
+
 	// Custom system prompt prefix.
 	SystemPromptPrefix string `json:"system_prompt_prefix,omitempty" jsonschema:"description=Custom prefix to add to system prompts for this provider"`
 
@@ -181,6 +245,33 @@ type LSPConfig struct {
 	Env       map[string]string `json:"env,omitempty" jsonschema:"description=Environment variables to set to the LSP server command"`
 	Options   any               `json:"options,omitempty" jsonschema:"description=LSP server-specific configuration options"`
 	FileTypes []string          `json:"filetypes,omitempty" jsonschema:"description=File types this LSP server handles,example=go,example=mod,example=rs,example=c,example=js,example=ts"`
+	// FormatOnWrite formats the files the edit, multiedit and write tools
+	// change with this server.
+	FormatOnWrite bool `json:"format_on_write,omitempty" jsonschema:"description=Format files with this LSP server after a tool writes them,default=false"`
+}
+
+// Formatter is an external command that formats the files tools write when
+// no LSP server formats them. It reads the content of the file from stdin and
+// writes the formatted content to stdout.
+type Formatter struct {
+	Command   string   `json:"command" jsonschema:"required,description=Command that reads a file from stdin and writes it formatted to stdout,example=gofmt,example=prettier"`
+	Args      []string `json:"args,omitempty" jsonschema:"description=Arguments to pass to the command; {file} is replaced with the path of the file,example=--stdin-filepath,example={file}"`
+	FileTypes []string `json:"filetypes" jsonschema:"required,description=File types this formatter handles,example=go,example=js,example=ts"`
+}
+
+// HandlesFile reports whether the file types of the formatter include path.
+func (f Formatter) HandlesFile(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	for _, fileType := range f.FileTypes {
+		suffix := strings.ToLower(fileType)
+		if !strings.HasPrefix(suffix, ".") {
+			suffix = "." + suffix
+		}
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
 }
 
 type TUIOptions struct {
@@ -189,9 +280,53 @@ type TUIOptions struct {
 	// Here we can add themes later or any TUI related options
 }
 
+type PermissionDecision string
+
+const (
+	PermissionAllow PermissionDecision = "allow"
+	PermissionDeny  PermissionDecision = "deny"
+	PermissionAsk   PermissionDecision = "ask"
+)
+
+// PermissionRule allows, denies or always asks for tool calls matching a
+// tool name and, optionally, a file path or shell command pattern.
+type PermissionRule struct {
+	Tool     string             `json:"tool,omitempty" jsonschema:"description=Tool the rule applies to; empty or * matches every tool,example=edit,example=bash"`
+	Path     string             `json:"path,omitempty" jsonschema:"description=Glob matched against the file a tool acts on; relative patterns are resolved against the working directory and patterns without a slash match the file name,example=internal/**,example=.env*"`
+	Command  string             `json:"command,omitempty" jsonschema:"description=Pattern matched against each command run by the bash tool; * matches anything,example=go test *,example=git push *"`
+	Decision PermissionDecision `json:"decision" jsonschema:"description=What to do with matching tool calls,enum=allow,enum=deny,enum=ask"`
+	Reason   string             `json:"reason,omitempty" jsonschema:"description=Explanation given to the model when the rule denies a tool call"`
+}
+
 type Permissions struct {
-	AllowedTools []string `json:"allowed_tools,omitempty" jsonschema:"description=List of tools that don't require permission prompts,example=bash,example=view"` // Tools that don't require permission prompts
-	SkipRequests bool     `json:"-"`                                                                                                                              // Automatically accept all permissions (YOLO mode)
+	AllowedTools []string         `json:"allowed_tools,omitempty" jsonschema:"description=List of tools that don't require permission prompts,example=bash,example=view"` // Tools that don't require permission prompts
+	Rules        []PermissionRule `json:"rules,omitempty" jsonschema:"description=Rules that allow or deny or always ask for matching tool calls; deny wins over ask and ask wins over allow"`
+	SkipRequests bool             `json:"-"` // Automatically accept all permissions (YOLO mode)
+}
+
+// Budget limits how much a single session or agent run may spend. Zero
+// values disable the corresponding limit.
+type Budget struct {
+	MaxSessionCost    float64 `json:"max_session_cost,omitempty" jsonschema:"description=Maximum cost in USD a session may accumulate,example=5"`
+	MaxTurnTokens     int64   `json:"max_turn_tokens,omitempty" jsonschema:"description=Maximum tokens a single prompt may consume across all of its model calls,example=500000"`
+	MaxToolIterations int     `json:"max_tool_iterations,omitempty" jsonschema:"description=Maximum number of tool-call rounds the agent may run for a single prompt,example=50"`
+}
+
+// Compaction controls how the agent frees up context when a session gets
+// close to the context window of its model. Zero values use the defaults.
+type Compaction struct {
+	Threshold     int `json:"threshold,omitempty" jsonschema:"description=Percentage of the context window at which the session is compacted,default=85,minimum=1,maximum=100"`
+	KeepTurns     int `json:"keep_turns,omitempty" jsonschema:"description=Number of most recent turns kept verbatim when the session is summarized,default=4,minimum=1"`
+	MaxToolOutput int `json:"max_tool_output,omitempty" jsonschema:"description=Characters kept of each large tool result from older turns,default=2000,minimum=1"`
+}
+
+// Sandbox restricts the programs shell commands run on Linux, so that they
+// can only write to the working directory, the temporary directories and
+// WritablePaths.
+type Sandbox struct {
+	Enabled       bool     `json:"enabled,omitempty" jsonschema:"description=Run the programs shell commands start in a sandbox that may only write to the working directory and temporary directories (Linux only),default=false"`
+	BlockNetwork  bool     `json:"block_network,omitempty" jsonschema:"description=Also cut sandboxed programs off from the network,default=false"`
+	WritablePaths []string `json:"writable_paths,omitempty" jsonschema:"description=Additional directories sandboxed programs may write to; relative paths are resolved against the working directory,example=~/.cache/go-build"`
 }
 
 type Options struct {
@@ -205,7 +340,12 @@ type Options struct {
 	DisableProviderAutoUpdate bool        `json:"disable_provider_auto_update,omitempty" jsonschema:"description=Disable providers auto-update,default=false"`
 	LSPIgnorePaths            []string    `json:"lsp_ignore_paths,omitempty" jsonschema:"description=Additional gitignore-style patterns to ignore when watching files for LSP events"`
 	// When true, foreground shell commands default to streaming output.
-	StreamShell bool `json:"stream_shell,omitempty" jsonschema:"description=Default to streaming output for foreground shell commands,default=false"`
+	StreamShell bool    `json:"stream_shell,omitempty" jsonschema:"description=Default to streaming output for foreground shell commands,default=false"`
+	Budget      *Budget `json:"budget,omitempty" jsonschema:"description=Spending limits enforced by the agent"`
+	// How many read-only tool calls the agent runs at once.
+	MaxParallelTools int         `json:"max_parallel_tools,omitempty" jsonschema:"description=Maximum number of read-only tool calls to run at once; 1 runs every tool call on its own,default=4,minimum=1"`
+	Compaction       *Compaction `json:"compaction,omitempty" jsonschema:"description=When and how the agent compacts sessions that get close to the context window"`
+	Sandbox          *Sandbox    `json:"sandbox,omitempty" jsonschema:"description=Restrict what the programs shell commands start may write to and reach"`
 }
 
 type MCPs map[string]MCPConfig
@@ -272,30 +412,36 @@ func (m MCPConfig) ResolvedHeaders() map[string]string {
 }
 
 type Agent struct {
-	ID          string `json:"id,omitempty"`
-	Name        string `json:"name,omitempty"`
-	Description string `json:"description,omitempty"`
+	ID          string `json:"id,omitempty" jsonschema:"-"`
+	Name        string `json:"name,omitempty" jsonschema:"description=Display name of the agent,example=Reviewer"`
+	Description string `json:"description,omitempty" jsonschema:"description=What the agent does; tells the coder agent when to delegate to it,example=Reviews changes for bugs and style issues"`
 	// This is the id of the system prompt used by the agent
-	Disabled bool `json:"disabled,omitempty"`
+	Disabled bool `json:"disabled,omitempty" jsonschema:"description=Whether this agent is disabled,default=false"`
+
+	// File with the system prompt of the agent, relative to the working
+	// directory.
+	Prompt string `json:"prompt,omitempty" jsonschema:"description=File with the system prompt of the agent,example=.crush/agents/reviewer.md"`
 
-	Model SelectedModelType `json:"model" jsonschema:"required,description=The model type to use for this agent,enum=large,enum=small,default=large"`
+	Model SelectedModelType `json:"model,omitempty" jsonschema:"description=The model type to use for this agent,enum=large,enum=small,default=large"`
 
 	// The available tools for the agent
 	//  if this is nil, all tools are available
-	AllowedTools []string `json:"allowed_tools,omitempty"`
+	AllowedTools []string `json:"allowed_tools,omitempty" jsonschema:"description=Tools the agent can use; all tools when not set,example=view,example=grep"`
 
 	// this tells us which MCPs are available for this agent
-	//  if this is empty all mcps are available
+	//  if this is nil all mcps are available; agents from the config get none
+	//  unless they are set
 	//  the string array is the list of tools from the AllowedMCP the agent has available
-	//  if the string array is nil, all tools from the AllowedMCP are available
-	AllowedMCP map[string][]string `json:"allowed_mcp,omitempty"`
+	//  if the string array is empty, all tools from the AllowedMCP are available
+	AllowedMCP map[string][]string `json:"allowed_mcp,omitempty" jsonschema:"description=MCP servers the agent can use mapped to the tools it can use (an empty list for all of them); none when not set"`
 
 	// The list of LSPs that this agent can use
-	//  if this is nil, all LSPs are available
-	AllowedLSP []string `json:"allowed_lsp,omitempty"`
+	//  if this is nil, all LSPs are available; agents from the config get none
+	//  unless they are set
+	AllowedLSP []string `json:"allowed_lsp,omitempty" jsonschema:"description=LSP servers the agent can use; none when not set"`
 
 	// Overrides the context paths for this agent
-	ContextPaths []string `json:"context_paths,omitempty"`
+	ContextPaths []string `json:"context_paths,omitempty" jsonschema:"description=Context files for this agent instead of the global ones"`
 }
 
 // Config holds the configuration for crush.
@@ -312,14 +458,18 @@ type Config struct {
 
 	LSP LSPs `json:"lsp,omitempty" jsonschema:"description=Language Server Protocol configurations"`
 
+	Formatters map[string]Formatter `json:"formatters,omitempty" jsonschema:"description=External formatters for the files tools write that no LSP server formats"`
+
 	Options *Options `json:"options,omitempty" jsonschema:"description=General application options"`
 
 	Permissions *Permissions `json:"permissions,omitempty" jsonschema:"description=Permission settings for tool usage"`
 
+	// The built-in coder and task agents, and the sub-agents the coder agent
+	// can delegate tasks to.
+	Agents map[string]Agent `json:"agents,omitempty" jsonschema:"description=Sub-agents the coder agent can delegate tasks to"`
+
 	// Internal
 	workingDir string `json:"-"`
-	// TODO: most likely remove this concept when I come back to it
-	Agents map[string]Agent `json:"-"`
 	// TODO: find a better way to do this this should probably not be part of the config
 	resolver       VariableResolver
 	dataConfigDir  string             `json:"-"`
@@ -432,12 +582,16 @@ func (c *Config) UpdatePreferredModel(modelType SelectedModelType, model Selecte
 		return fmt.Errorf("invalid reasoning effort: %s", model.ReasoningEffort)
 	}
 	// Preserve reasoning only for OpenAI-family providers (e.g., OpenAI, Azure OpenAI)
-	if prov.Type != catwalk.TypeOpenAI && prov.Type != catwalk.TypeAzure {
+	if prov.Type != catwalk.TypeOpenAI && prov.Type != TypeOpenAIResponses && prov.Type != catwalk.TypeAzure {
 		model.ReasoningEffort = ""
 	}
 	if c.Models == nil {
 		c.Models = make(map[SelectedModelType]SelectedModel)
 	}
+	// Switching models keeps the fallbacks configured for the model type.
+	if model.Fallbacks == nil {
+		model.Fallbacks = c.Models[modelType].Fallbacks
+	}
 	c.Models[modelType] = model
 	if err := c.SetConfigField(fmt.Sprintf("models.%s", modelType), model); err != nil {
 		return fmt.Errorf("failed to update preferred model: %w", err)
@@ -576,10 +730,16 @@ func allToolNames() []string {
 		"fetch",
 		"glob",
 		"grep",
+		"jobs",
+		"job_output",
+		"job_stop",
+		"job_wait",
 		"ls",
+		"refactor",
 		"sourcegraph",
 		"view",
 		"write",
+		"todos",
 	}
 }
 
@@ -648,6 +808,39 @@ func (c *Config) SetupAgents() {
 			AllowedLSP: []string{},
 		},
 	}
+	for id, agent := range c.Agents {
+		if _, ok := agents[id]; ok {
+			slog.Warn("Ignoring agent that replaces a built-in agent", "agent", id)
+			continue
+		}
+		if agent.Disabled {
+			continue
+		}
+		agent.ID = id
+		if agent.Name == "" {
+			agent.Name = id
+		}
+		if agent.Model == "" {
+			agent.Model = SelectedModelTypeLarge
+		}
+		if agent.AllowedTools == nil {
+			agent.AllowedTools = allowedTools
+		} else {
+			agent.AllowedTools = resolveAllowedTools(agent.AllowedTools, c.Options.DisabledTools)
+		}
+		// Like the task agent, agents only get the MCP and LSP servers they
+		// are given.
+		if agent.AllowedMCP == nil {
+			agent.AllowedMCP = map[string][]string{}
+		}
+		if agent.AllowedLSP == nil {
+			agent.AllowedLSP = []string{}
+		}
+		if agent.ContextPaths == nil {
+			agent.ContextPaths = c.Options.ContextPaths
+		}
+		agents[id] = agent
+	}
 	c.Agents = agents
 }
 
@@ -660,7 +853,7 @@ func (c *ProviderConfig) TestConnection(resolver VariableResolver) error {
 	headers := make(map[string]string)
 	apiKey, _ := resolver.ResolveValue(c.APIKey)
 	switch c.Type {
-	case catwalk.TypeOpenAI:
+	case catwalk.TypeOpenAI, TypeOpenAIResponses:
 		baseURL, _ := resolver.ResolveValue(c.BaseURL)
 		if baseURL == "" {
 			baseURL = "https://api.openai.com/v1"
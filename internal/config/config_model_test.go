@@ -82,4 +82,21 @@ func TestUpdatePreferredModelValidatesInputs(t *testing.T) {
 		stored := cfg.Models[SelectedModelTypeLarge]
 		require.Equal(t, "", stored.ReasoningEffort)
 	})
+
+	t.Run("keeps fallbacks", func(t *testing.T) {
+		cfg := newTestConfig(t)
+		cfg.Providers.Set("local", ProviderConfig{
+			ID:     "local",
+			Type:   catwalk.TypeOpenAI,
+			Models: []catwalk.Model{{ID: "m1"}, {ID: "m2"}},
+		})
+		fallbacks := []SelectedModel{{Provider: "local", Model: "m2"}}
+		cfg.Models[SelectedModelTypeLarge] = SelectedModel{Provider: "local", Model: "m2", Fallbacks: fallbacks}
+		err := cfg.UpdatePreferredModel(SelectedModelTypeLarge, SelectedModel{Provider: "local", Model: "m1"})
+		require.NoError(t, err)
+		require.Equal(t, fallbacks, cfg.Models[SelectedModelTypeLarge].Fallbacks)
+		data, readErr := os.ReadFile(cfg.dataConfigDir)
+		require.NoError(t, readErr)
+		require.Contains(t, string(data), `"fallbacks"`)
+	})
 }
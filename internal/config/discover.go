@@ -0,0 +1,319 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+const (
+	discoveryTimeout = 3 * time.Second
+	// Used when a server does not report the context window of a model.
+	defaultDiscoveredContextWindow = 8192
+	maxDiscoveredMaxTokens         = 8192
+)
+
+// DiscoveredModel is a model reported by a provider's API.
+type DiscoveredModel struct {
+	catwalk.Model
+	// SupportsTools is false for models the server reports as unable to call
+	// tools. Crush does not offer them since the agent relies on tools.
+	SupportsTools bool `json:"supports_tools"`
+}
+
+type discoveryCacheEntry struct {
+	BaseURL   string            `json:"base_url"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	Models    []DiscoveredModel `json:"models"`
+}
+
+var forceDiscovery atomic.Bool
+
+// ForceModelDiscovery makes Load ask providers with discover_models set for
+// their models instead of using the cached ones.
+func ForceModelDiscovery() {
+	forceDiscovery.Store(true)
+}
+
+// providerStarter starts a provider with a startup command. It is set with
+// SetProviderStarter.
+var providerStarter func(ctx context.Context, cwd string, prov ProviderConfig) error
+
+// SetProviderStarter sets how Load starts providers with a startup command
+// before their models are discovered.
+func SetProviderStarter(start func(ctx context.Context, cwd string, prov ProviderConfig) error) {
+	providerStarter = start
+}
+
+// file to cache discovered models, next to the provider cache
+func discoveryCacheFile() string {
+	return filepath.Join(filepath.Dir(providerCacheFileData()), "discovered_models.json")
+}
+
+func loadDiscoveryCache(path string) map[string]discoveryCacheEntry {
+	cache := make(map[string]discoveryCacheEntry)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		slog.Warn("Ignoring invalid discovered models cache", "path", path, "error", err)
+	}
+	return cache
+}
+
+func saveDiscoveryCache(path string, cache map[string]discoveryCacheEntry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for discovered models cache: %w", err)
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal discovered models: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write discovered models cache: %w", err)
+	}
+	return nil
+}
+
+// discoveredModels returns the models discovered for a provider. Models are
+// cached for a day; a stale cache is still used when the provider can't be
+// reached. When start is set, it is called to get the provider running before
+// it is asked for its models.
+func discoveredModels(ctx context.Context, prov ProviderConfig, resolver VariableResolver, path string, refresh bool, start func(context.Context) error) []catwalk.Model {
+	resolved := prov
+	resolved.BaseURL, _ = resolver.ResolveValue(prov.BaseURL)
+	resolved.APIKey, _ = resolver.ResolveValue(prov.APIKey)
+	resolved.ExtraHeaders = make(map[string]string, len(prov.ExtraHeaders))
+	for key, value := range prov.ExtraHeaders {
+		resolved.ExtraHeaders[key], _ = resolver.ResolveValue(value)
+	}
+
+	cache := loadDiscoveryCache(path)
+	cached, ok := cache[prov.ID]
+	ok = ok && cached.BaseURL == resolved.BaseURL
+	if ok && !refresh && time.Since(cached.UpdatedAt) < 24*time.Hour {
+		return toolModels(cached.Models)
+	}
+
+	if start != nil {
+		if err := start(ctx); err != nil {
+			slog.Warn("Failed to start provider to discover models", "provider", prov.ID, "error", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, discoveryTimeout)
+	defer cancel()
+	models, err := DiscoverModels(ctx, nil, resolved)
+	if err != nil {
+		slog.Warn("Failed to discover models", "provider", prov.ID, "error", err)
+		if ok {
+			return toolModels(cached.Models)
+		}
+		return nil
+	}
+
+	cache[prov.ID] = discoveryCacheEntry{
+		BaseURL:   resolved.BaseURL,
+		UpdatedAt: time.Now(),
+		Models:    models,
+	}
+	if err := saveDiscoveryCache(path, cache); err != nil {
+		slog.Warn("Failed to cache discovered models", "provider", prov.ID, "error", err)
+	}
+	return toolModels(models)
+}
+
+// mergeModels appends the discovered models that are not configured already.
+func mergeModels(configured, discovered []catwalk.Model) []catwalk.Model {
+	for _, m := range discovered {
+		if !slices.ContainsFunc(configured, func(c catwalk.Model) bool { return c.ID == m.ID }) {
+			configured = append(configured, m)
+		}
+	}
+	return configured
+}
+
+func toolModels(discovered []DiscoveredModel) []catwalk.Model {
+	var models []catwalk.Model
+	for _, m := range discovered {
+		if !m.SupportsTools {
+			slog.Debug("Skipping discovered model without tool support", "model", m.ID)
+			continue
+		}
+		models = append(models, m.Model)
+	}
+	return models
+}
+
+// DiscoverModels asks the provider which models it serves. Ollama servers
+// are also asked for the context window and capabilities of each model;
+// other servers are queried through the OpenAI-compatible models endpoint.
+// The provider's base URL and API key must already be resolved.
+func DiscoverModels(ctx context.Context, client *http.Client, prov ProviderConfig) ([]DiscoveredModel, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	baseURL := strings.TrimRight(prov.BaseURL, "/")
+	if baseURL == "" {
+		return nil, fmt.Errorf("provider base_url not configured")
+	}
+
+	ollamaURL := strings.TrimSuffix(baseURL, "/v1")
+	if models, err := discoverOllamaModels(ctx, client, prov, ollamaURL); err == nil {
+		return models, nil
+	}
+	return discoverOpenAIModels(ctx, client, prov, baseURL)
+}
+
+type openAIModelList struct {
+	Data []struct {
+		ID string `json:"id"`
+		// Context window as reported by vLLM, LM Studio and others.
+		MaxModelLen      int64 `json:"max_model_len"`
+		ContextLength    int64 `json:"context_length"`
+		MaxContextLength int64 `json:"max_context_length"`
+		// llama.cpp reports the context the model was trained with.
+		Meta struct {
+			NCtxTrain int64 `json:"n_ctx_train"`
+		} `json:"meta"`
+	} `json:"data"`
+}
+
+func discoverOpenAIModels(ctx context.Context, client *http.Client, prov ProviderConfig, baseURL string) ([]DiscoveredModel, error) {
+	var list openAIModelList
+	if err := doDiscoveryRequest(ctx, client, prov, http.MethodGet, baseURL+"/models", nil, &list); err != nil {
+		return nil, err
+	}
+	models := make([]DiscoveredModel, 0, len(list.Data))
+	for _, m := range list.Data {
+		contextWindow := max(m.MaxModelLen, m.ContextLength, m.MaxContextLength, m.Meta.NCtxTrain)
+		models = append(models, DiscoveredModel{
+			Model: newDiscoveredModel(m.ID, contextWindow),
+			// The endpoint does not tell, assume the model can.
+			SupportsTools: true,
+		})
+	}
+	sortDiscoveredModels(models)
+	return models, nil
+}
+
+type ollamaTags struct {
+	Models []struct {
+		Name string `json:"name"`
+	} `json:"models"`
+}
+
+type ollamaShow struct {
+	ModelInfo    map[string]any `json:"model_info"`
+	Capabilities []string       `json:"capabilities"`
+}
+
+func discoverOllamaModels(ctx context.Context, client *http.Client, prov ProviderConfig, ollamaURL string) ([]DiscoveredModel, error) {
+	var tags ollamaTags
+	if err := doDiscoveryRequest(ctx, client, prov, http.MethodGet, ollamaURL+"/api/tags", nil, &tags); err != nil {
+		return nil, err
+	}
+	models := make([]DiscoveredModel, 0, len(tags.Models))
+	for _, tag := range tags.Models {
+		var show ollamaShow
+		body := map[string]string{"model": tag.Name}
+		if err := doDiscoveryRequest(ctx, client, prov, http.MethodPost, ollamaURL+"/api/show", body, &show); err != nil {
+			return nil, fmt.Errorf("failed to show model %s: %w", tag.Name, err)
+		}
+		// Models listed by older servers have no capabilities.
+		if len(show.Capabilities) > 0 && !slices.Contains(show.Capabilities, "completion") {
+			continue
+		}
+		model := newDiscoveredModel(tag.Name, ollamaContextLength(show.ModelInfo))
+		model.SupportsImages = slices.Contains(show.Capabilities, "vision")
+		model.CanReason = slices.Contains(show.Capabilities, "thinking")
+		models = append(models, DiscoveredModel{
+			Model:         model,
+			SupportsTools: len(show.Capabilities) == 0 || slices.Contains(show.Capabilities, "tools"),
+		})
+	}
+	sortDiscoveredModels(models)
+	return models, nil
+}
+
+// ollamaContextLength reads the "<architecture>.context_length" entry of a
+// model's info.
+func ollamaContextLength(info map[string]any) int64 {
+	for key, value := range info {
+		if !strings.HasSuffix(key, ".context_length") {
+			continue
+		}
+		if n, ok := value.(float64); ok {
+			return int64(n)
+		}
+	}
+	return 0
+}
+
+func newDiscoveredModel(id string, contextWindow int64) catwalk.Model {
+	if contextWindow <= 0 {
+		contextWindow = defaultDiscoveredContextWindow
+	}
+	return catwalk.Model{
+		ID:               id,
+		Name:             id,
+		ContextWindow:    contextWindow,
+		DefaultMaxTokens: min(contextWindow/4, maxDiscoveredMaxTokens),
+	}
+}
+
+func sortDiscoveredModels(models []DiscoveredModel) {
+	slices.SortFunc(models, func(a, b DiscoveredModel) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+}
+
+func doDiscoveryRequest(ctx context.Context, client *http.Client, prov ProviderConfig, method, url string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if prov.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+prov.APIKey)
+	}
+	for key, value := range prov.ExtraHeaders {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %s", method, url, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/env"
+	"github.com/stretchr/testify/require"
+)
+
+func newOllamaServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/tags", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"models":[{"name":"qwen3:8b"},{"name":"nomic-embed-text"},{"name":"gemma3:4b"}]}`))
+	})
+	mux.HandleFunc("POST /api/show", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string `json:"model"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		switch req.Model {
+		case "qwen3:8b":
+			w.Write([]byte(`{"model_info":{"general.architecture":"qwen3","qwen3.context_length":40960},"capabilities":["completion","tools","thinking"]}`))
+		case "gemma3:4b":
+			w.Write([]byte(`{"model_info":{"gemma3.context_length":131072},"capabilities":["completion","vision"]}`))
+		default:
+			w.Write([]byte(`{"model_info":{"nomic-bert.context_length":2048},"capabilities":["embedding"]}`))
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDiscoverModels(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ollama", func(t *testing.T) {
+		t.Parallel()
+		server := newOllamaServer(t)
+
+		models, err := DiscoverModels(t.Context(), server.Client(), ProviderConfig{BaseURL: server.URL + "/v1"})
+		require.NoError(t, err)
+		require.Len(t, models, 2)
+
+		require.Equal(t, "gemma3:4b", models[0].ID)
+		require.Equal(t, int64(131072), models[0].ContextWindow)
+		require.True(t, models[0].SupportsImages)
+		require.False(t, models[0].SupportsTools)
+
+		require.Equal(t, "qwen3:8b", models[1].ID)
+		require.Equal(t, int64(40960), models[1].ContextWindow)
+		require.Equal(t, int64(8192), models[1].DefaultMaxTokens)
+		require.True(t, models[1].CanReason)
+		require.True(t, models[1].SupportsTools)
+	})
+
+	t.Run("openai compatible", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/models" {
+				http.NotFound(w, r)
+				return
+			}
+			require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			w.Write([]byte(`{"data":[{"id":"Qwen/Qwen3-32B","max_model_len":32768},{"id":"local"}]}`))
+		}))
+		t.Cleanup(server.Close)
+
+		models, err := DiscoverModels(t.Context(), server.Client(), ProviderConfig{BaseURL: server.URL + "/v1", APIKey: "secret"})
+		require.NoError(t, err)
+		require.Len(t, models, 2)
+		require.Equal(t, "Qwen/Qwen3-32B", models[0].ID)
+		require.Equal(t, int64(32768), models[0].ContextWindow)
+		require.Equal(t, "local", models[1].ID)
+		require.Equal(t, int64(defaultDiscoveredContextWindow), models[1].ContextWindow)
+		require.Equal(t, int64(2048), models[1].DefaultMaxTokens)
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.NotFoundHandler())
+		t.Cleanup(server.Close)
+
+		_, err := DiscoverModels(t.Context(), server.Client(), ProviderConfig{BaseURL: server.URL})
+		require.Error(t, err)
+	})
+}
+
+func TestDiscoveredModels_Cache(t *testing.T) {
+	t.Parallel()
+
+	server := newOllamaServer(t)
+	path := filepath.Join(t.TempDir(), "discovered_models.json")
+	resolver := NewEnvironmentVariableResolver(env.NewFromMap(map[string]string{"OLLAMA_HOST": server.URL + "/v1"}))
+	prov := ProviderConfig{ID: "ollama", BaseURL: "$OLLAMA_HOST", DiscoverModels: true}
+
+	models := discoveredModels(context.Background(), prov, resolver, path, false, nil)
+	require.Len(t, models, 1)
+	require.Equal(t, "qwen3:8b", models[0].ID)
+
+	// The cache answers while the server is gone.
+	server.Close()
+	models = discoveredModels(context.Background(), prov, resolver, path, false, nil)
+	require.Len(t, models, 1)
+
+	// A refresh falls back to the stale cache.
+	models = discoveredModels(context.Background(), prov, resolver, path, true, nil)
+	require.Len(t, models, 1)
+
+	cache := loadDiscoveryCache(path)
+	require.Equal(t, server.URL+"/v1", cache["ollama"].BaseURL)
+}
+
+func TestDiscoveredModels_Start(t *testing.T) {
+	t.Parallel()
+
+	server := newOllamaServer(t)
+	path := filepath.Join(t.TempDir(), "discovered_models.json")
+	resolver := NewEnvironmentVariableResolver(env.NewFromMap(map[string]string{"OLLAMA_HOST": server.URL + "/v1"}))
+	prov := ProviderConfig{ID: "ollama", BaseURL: "$OLLAMA_HOST", DiscoverModels: true, StartupCommand: "ollama serve"}
+
+	starts := 0
+	start := func(context.Context) error {
+		starts++
+		return nil
+	}
+	models := discoveredModels(context.Background(), prov, resolver, path, false, start)
+	require.Len(t, models, 1)
+	require.Equal(t, 1, starts)
+
+	// Cached models don't need the provider running.
+	models = discoveredModels(context.Background(), prov, resolver, path, false, start)
+	require.Len(t, models, 1)
+	require.Equal(t, 1, starts)
+}
+
+func TestMergeModels(t *testing.T) {
+	t.Parallel()
+
+	configured := newDiscoveredModel("llama3", 4096)
+	configured.Name = "Llama 3"
+	merged := mergeModels(
+		[]catwalk.Model{configured},
+		[]catwalk.Model{newDiscoveredModel("llama3", 8192), newDiscoveredModel("qwen3", 8192)},
+	)
+	require.Len(t, merged, 2)
+	require.Equal(t, "Llama 3", merged[0].Name)
+	require.Equal(t, "qwen3", merged[1].ID)
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -141,6 +142,10 @@ func (c *Config) configureProviders(env env.Env, resolver VariableResolver, know
 			if config.APIKey != "" {
 				p.APIKey = config.APIKey
 			}
+			// OpenAI providers can be switched to the Responses API.
+			if config.Type == TypeOpenAIResponses && p.Type == catwalk.TypeOpenAI {
+				p.Type = TypeOpenAIResponses
+			}
 			if len(config.Models) > 0 {
 				models := []catwalk.Model{}
 				seen := make(map[string]bool)
@@ -188,6 +193,7 @@ func (c *Config) configureProviders(env env.Env, resolver VariableResolver, know
 			StartupCommand:        config.StartupCommand,
 			StartupTimeoutSeconds: config.StartupTimeoutSeconds,
 			StartupHealthPath:     config.StartupHealthPath,
+			Cache:                 config.Cache,
 			SystemPromptPrefix:    config.SystemPromptPrefix,
 			ExtraHeaders:          headers,
 			ExtraBody:             config.ExtraBody,
@@ -270,6 +276,15 @@ func (c *Config) configureProviders(env env.Env, resolver VariableResolver, know
 			c.Providers.Del(id)
 			continue
 		}
+		if providerConfig.Type == TypeReplay {
+			if err := c.configureReplayProvider(&providerConfig); err != nil {
+				slog.Warn("Skipping replay provider", "provider", id, "error", err)
+				c.Providers.Del(id)
+				continue
+			}
+			c.Providers.Set(id, providerConfig)
+			continue
+		}
 		if providerConfig.APIKey == "" {
 			slog.Warn("Provider is missing API key, this might be OK for local providers", "provider", id)
 		}
@@ -278,12 +293,24 @@ func (c *Config) configureProviders(env env.Env, resolver VariableResolver, know
 			c.Providers.Del(id)
 			continue
 		}
+		if providerConfig.DiscoverModels {
+			// The startup command has to run before a provider can be asked
+			// for its models.
+			var start func(context.Context) error
+			if providerConfig.StartupCommand != "" && providerStarter != nil {
+				prov := providerConfig
+				start = func(ctx context.Context) error {
+					return providerStarter(ctx, c.workingDir, prov)
+				}
+			}
+			providerConfig.Models = mergeModels(providerConfig.Models, discoveredModels(context.Background(), providerConfig, resolver, discoveryCacheFile(), forceDiscovery.Load(), start))
+		}
 		if len(providerConfig.Models) == 0 {
 			slog.Warn("Skipping custom provider because the provider has no models", "provider", id)
 			c.Providers.Del(id)
 			continue
 		}
-		if providerConfig.Type != catwalk.TypeOpenAI && providerConfig.Type != catwalk.TypeAnthropic {
+		if providerConfig.Type != catwalk.TypeOpenAI && providerConfig.Type != TypeOpenAIResponses && providerConfig.Type != catwalk.TypeAnthropic {
 			slog.Warn("Skipping custom provider because the provider type is not supported", "provider", id, "type", providerConfig.Type)
 			c.Providers.Del(id)
 			continue
@@ -305,6 +332,35 @@ func (c *Config) configureProviders(env env.Env, resolver VariableResolver, know
 	return nil
 }
 
+// configureReplayProvider checks the replay settings of a replay provider.
+// Without models of its own it offers the models of the provider it records.
+func (c *Config) configureReplayProvider(providerConfig *ProviderConfig) error {
+	replay := providerConfig.Replay
+	if replay == nil || replay.Cassette == "" {
+		return fmt.Errorf("no cassette configured")
+	}
+	switch replay.Mode {
+	case "":
+		replay.Mode = ReplayModeReplay
+	case ReplayModeRecord:
+		if replay.Provider == "" {
+			return fmt.Errorf("no provider to record configured")
+		}
+	case ReplayModeReplay:
+	default:
+		return fmt.Errorf("invalid replay mode %q", replay.Mode)
+	}
+	if len(providerConfig.Models) == 0 {
+		if upstream, ok := c.Providers.Get(replay.Provider); ok {
+			providerConfig.Models = upstream.Models
+		}
+	}
+	if len(providerConfig.Models) == 0 {
+		return fmt.Errorf("no models configured")
+	}
+	return nil
+}
+
 func (c *Config) setDefaults(workingDir, dataDir string) {
 	c.workingDir = workingDir
 	if c.Options == nil {
@@ -510,6 +566,7 @@ func (c *Config) configureSelectedModels(knownProviders []catwalk.Provider) erro
 			}
 			large.Think = largeModelSelected.Think
 		}
+		large.Fallbacks = largeModelSelected.Fallbacks
 	}
 	smallModelSelected, smallModelConfigured := c.Models[SelectedModelTypeSmall]
 	if smallModelConfigured {
@@ -537,6 +594,7 @@ func (c *Config) configureSelectedModels(knownProviders []catwalk.Provider) erro
 			small.ReasoningEffort = smallModelSelected.ReasoningEffort
 			small.Think = smallModelSelected.Think
 		}
+		small.Fallbacks = smallModelSelected.Fallbacks
 	}
 	c.Models[SelectedModelTypeLarge] = large
 	c.Models[SelectedModelTypeSmall] = small
@@ -515,13 +515,55 @@ func TestConfig_setupAgentsWithDisabledTools(t *testing.T) {
 	cfg.SetupAgents()
 	coderAgent, ok := cfg.Agents["coder"]
 	require.True(t, ok)
-	assert.Equal(t, []string{"bash", "multiedit", "fetch", "glob", "ls", "sourcegraph", "view", "write"}, coderAgent.AllowedTools)
+	assert.Equal(t, []string{"bash", "multiedit", "fetch", "glob", "jobs", "job_output", "job_stop", "job_wait", "ls", "refactor", "sourcegraph", "view", "write", "todos"}, coderAgent.AllowedTools)
 
 	taskAgent, ok := cfg.Agents["task"]
 	require.True(t, ok)
 	assert.Equal(t, []string{"glob", "ls", "sourcegraph", "view"}, taskAgent.AllowedTools)
 }
 
+func TestConfig_setupAgentsWithCustomAgents(t *testing.T) {
+	cfg := &Config{
+		Options: &Options{
+			DisabledTools: []string{"bash"},
+			ContextPaths:  []string{"CRUSH.md"},
+		},
+		Agents: map[string]Agent{
+			"reviewer": {
+				Description:  "Reviews changes",
+				Prompt:       "reviewer.md",
+				Model:        SelectedModelTypeSmall,
+				AllowedTools: []string{"view", "grep", "bash"},
+			},
+			"writer":   {},
+			"disabled": {Disabled: true},
+			"coder":    {Description: "Replaces the coder"},
+		},
+	}
+
+	cfg.SetupAgents()
+	reviewer, ok := cfg.Agents["reviewer"]
+	require.True(t, ok)
+	assert.Equal(t, "reviewer", reviewer.ID)
+	assert.Equal(t, "reviewer", reviewer.Name)
+	assert.Equal(t, "reviewer.md", reviewer.Prompt)
+	assert.Equal(t, SelectedModelTypeSmall, reviewer.Model)
+	assert.Equal(t, []string{"view", "grep"}, reviewer.AllowedTools)
+	assert.Equal(t, []string{"CRUSH.md"}, reviewer.ContextPaths)
+
+	writer, ok := cfg.Agents["writer"]
+	require.True(t, ok)
+	assert.Equal(t, SelectedModelTypeLarge, writer.Model)
+	assert.NotContains(t, writer.AllowedTools, "bash")
+	assert.Contains(t, writer.AllowedTools, "write")
+	assert.Equal(t, map[string][]string{}, writer.AllowedMCP)
+	assert.Equal(t, []string{}, writer.AllowedLSP)
+
+	_, ok = cfg.Agents["disabled"]
+	assert.False(t, ok)
+	assert.Equal(t, "An agent that helps with executing coding tasks.", cfg.Agents["coder"].Description)
+}
+
 func TestConfig_configureProvidersWithDisabledProvider(t *testing.T) {
 	knownProviders := []catwalk.Provider{
 		{
@@ -24,21 +24,39 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.copyFileStmt, err = db.PrepareContext(ctx, copyFile); err != nil {
+		return nil, fmt.Errorf("error preparing query CopyFile: %w", err)
+	}
+	if q.copyMessageStmt, err = db.PrepareContext(ctx, copyMessage); err != nil {
+		return nil, fmt.Errorf("error preparing query CopyMessage: %w", err)
+	}
 	if q.createFileStmt, err = db.PrepareContext(ctx, createFile); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateFile: %w", err)
 	}
 	if q.createMessageStmt, err = db.PrepareContext(ctx, createMessage); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateMessage: %w", err)
 	}
+	if q.createPermissionGrantStmt, err = db.PrepareContext(ctx, createPermissionGrant); err != nil {
+		return nil, fmt.Errorf("error preparing query CreatePermissionGrant: %w", err)
+	}
 	if q.createSessionStmt, err = db.PrepareContext(ctx, createSession); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateSession: %w", err)
 	}
+	if q.createTodoStmt, err = db.PrepareContext(ctx, createTodo); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateTodo: %w", err)
+	}
+	if q.deleteAllPermissionGrantsStmt, err = db.PrepareContext(ctx, deleteAllPermissionGrants); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteAllPermissionGrants: %w", err)
+	}
 	if q.deleteFileStmt, err = db.PrepareContext(ctx, deleteFile); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteFile: %w", err)
 	}
 	if q.deleteMessageStmt, err = db.PrepareContext(ctx, deleteMessage); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteMessage: %w", err)
 	}
+	if q.deletePermissionGrantStmt, err = db.PrepareContext(ctx, deletePermissionGrant); err != nil {
+		return nil, fmt.Errorf("error preparing query DeletePermissionGrant: %w", err)
+	}
 	if q.deleteSessionStmt, err = db.PrepareContext(ctx, deleteSession); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteSession: %w", err)
 	}
@@ -48,6 +66,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.deleteSessionMessagesStmt, err = db.PrepareContext(ctx, deleteSessionMessages); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteSessionMessages: %w", err)
 	}
+	if q.deleteSessionTodosStmt, err = db.PrepareContext(ctx, deleteSessionTodos); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteSessionTodos: %w", err)
+	}
 	if q.getFileStmt, err = db.PrepareContext(ctx, getFile); err != nil {
 		return nil, fmt.Errorf("error preparing query GetFile: %w", err)
 	}
@@ -57,6 +78,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getMessageStmt, err = db.PrepareContext(ctx, getMessage); err != nil {
 		return nil, fmt.Errorf("error preparing query GetMessage: %w", err)
 	}
+	if q.getPermissionGrantStmt, err = db.PrepareContext(ctx, getPermissionGrant); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPermissionGrant: %w", err)
+	}
 	if q.getSessionByIDStmt, err = db.PrepareContext(ctx, getSessionByID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetSessionByID: %w", err)
 	}
@@ -69,15 +93,24 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listLatestSessionFilesStmt, err = db.PrepareContext(ctx, listLatestSessionFiles); err != nil {
 		return nil, fmt.Errorf("error preparing query ListLatestSessionFiles: %w", err)
 	}
+	if q.listMessageUsageStmt, err = db.PrepareContext(ctx, listMessageUsage); err != nil {
+		return nil, fmt.Errorf("error preparing query ListMessageUsage: %w", err)
+	}
 	if q.listMessagesBySessionStmt, err = db.PrepareContext(ctx, listMessagesBySession); err != nil {
 		return nil, fmt.Errorf("error preparing query ListMessagesBySession: %w", err)
 	}
 	if q.listNewFilesStmt, err = db.PrepareContext(ctx, listNewFiles); err != nil {
 		return nil, fmt.Errorf("error preparing query ListNewFiles: %w", err)
 	}
+	if q.listPermissionGrantsStmt, err = db.PrepareContext(ctx, listPermissionGrants); err != nil {
+		return nil, fmt.Errorf("error preparing query ListPermissionGrants: %w", err)
+	}
 	if q.listSessionsStmt, err = db.PrepareContext(ctx, listSessions); err != nil {
 		return nil, fmt.Errorf("error preparing query ListSessions: %w", err)
 	}
+	if q.listTodosBySessionStmt, err = db.PrepareContext(ctx, listTodosBySession); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTodosBySession: %w", err)
+	}
 	if q.updateMessageStmt, err = db.PrepareContext(ctx, updateMessage); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateMessage: %w", err)
 	}
@@ -89,6 +122,16 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 
 func (q *Queries) Close() error {
 	var err error
+	if q.copyFileStmt != nil {
+		if cerr := q.copyFileStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing copyFileStmt: %w", cerr)
+		}
+	}
+	if q.copyMessageStmt != nil {
+		if cerr := q.copyMessageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing copyMessageStmt: %w", cerr)
+		}
+	}
 	if q.createFileStmt != nil {
 		if cerr := q.createFileStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createFileStmt: %w", cerr)
@@ -99,11 +142,26 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createMessageStmt: %w", cerr)
 		}
 	}
+	if q.createPermissionGrantStmt != nil {
+		if cerr := q.createPermissionGrantStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createPermissionGrantStmt: %w", cerr)
+		}
+	}
 	if q.createSessionStmt != nil {
 		if cerr := q.createSessionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createSessionStmt: %w", cerr)
 		}
 	}
+	if q.createTodoStmt != nil {
+		if cerr := q.createTodoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createTodoStmt: %w", cerr)
+		}
+	}
+	if q.deleteAllPermissionGrantsStmt != nil {
+		if cerr := q.deleteAllPermissionGrantsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteAllPermissionGrantsStmt: %w", cerr)
+		}
+	}
 	if q.deleteFileStmt != nil {
 		if cerr := q.deleteFileStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteFileStmt: %w", cerr)
@@ -114,6 +172,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing deleteMessageStmt: %w", cerr)
 		}
 	}
+	if q.deletePermissionGrantStmt != nil {
+		if cerr := q.deletePermissionGrantStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deletePermissionGrantStmt: %w", cerr)
+		}
+	}
 	if q.deleteSessionStmt != nil {
 		if cerr := q.deleteSessionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteSessionStmt: %w", cerr)
@@ -129,6 +192,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing deleteSessionMessagesStmt: %w", cerr)
 		}
 	}
+	if q.deleteSessionTodosStmt != nil {
+		if cerr := q.deleteSessionTodosStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteSessionTodosStmt: %w", cerr)
+		}
+	}
 	if q.getFileStmt != nil {
 		if cerr := q.getFileStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getFileStmt: %w", cerr)
@@ -144,6 +212,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getMessageStmt: %w", cerr)
 		}
 	}
+	if q.getPermissionGrantStmt != nil {
+		if cerr := q.getPermissionGrantStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPermissionGrantStmt: %w", cerr)
+		}
+	}
 	if q.getSessionByIDStmt != nil {
 		if cerr := q.getSessionByIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getSessionByIDStmt: %w", cerr)
@@ -164,6 +237,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listLatestSessionFilesStmt: %w", cerr)
 		}
 	}
+	if q.listMessageUsageStmt != nil {
+		if cerr := q.listMessageUsageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listMessageUsageStmt: %w", cerr)
+		}
+	}
 	if q.listMessagesBySessionStmt != nil {
 		if cerr := q.listMessagesBySessionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listMessagesBySessionStmt: %w", cerr)
@@ -174,11 +252,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listNewFilesStmt: %w", cerr)
 		}
 	}
+	if q.listPermissionGrantsStmt != nil {
+		if cerr := q.listPermissionGrantsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listPermissionGrantsStmt: %w", cerr)
+		}
+	}
 	if q.listSessionsStmt != nil {
 		if cerr := q.listSessionsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listSessionsStmt: %w", cerr)
 		}
 	}
+	if q.listTodosBySessionStmt != nil {
+		if cerr := q.listTodosBySessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTodosBySessionStmt: %w", cerr)
+		}
+	}
 	if q.updateMessageStmt != nil {
 		if cerr := q.updateMessageStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateMessageStmt: %w", cerr)
@@ -226,53 +314,75 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                          DBTX
-	tx                          *sql.Tx
-	createFileStmt              *sql.Stmt
-	createMessageStmt           *sql.Stmt
-	createSessionStmt           *sql.Stmt
-	deleteFileStmt              *sql.Stmt
-	deleteMessageStmt           *sql.Stmt
-	deleteSessionStmt           *sql.Stmt
-	deleteSessionFilesStmt      *sql.Stmt
-	deleteSessionMessagesStmt   *sql.Stmt
-	getFileStmt                 *sql.Stmt
-	getFileByPathAndSessionStmt *sql.Stmt
-	getMessageStmt              *sql.Stmt
-	getSessionByIDStmt          *sql.Stmt
-	listFilesByPathStmt         *sql.Stmt
-	listFilesBySessionStmt      *sql.Stmt
-	listLatestSessionFilesStmt  *sql.Stmt
-	listMessagesBySessionStmt   *sql.Stmt
-	listNewFilesStmt            *sql.Stmt
-	listSessionsStmt            *sql.Stmt
-	updateMessageStmt           *sql.Stmt
-	updateSessionStmt           *sql.Stmt
+	db                            DBTX
+	tx                            *sql.Tx
+	copyFileStmt                  *sql.Stmt
+	copyMessageStmt               *sql.Stmt
+	createFileStmt                *sql.Stmt
+	createMessageStmt             *sql.Stmt
+	createPermissionGrantStmt     *sql.Stmt
+	createSessionStmt             *sql.Stmt
+	createTodoStmt                *sql.Stmt
+	deleteAllPermissionGrantsStmt *sql.Stmt
+	deleteFileStmt                *sql.Stmt
+	deleteMessageStmt             *sql.Stmt
+	deletePermissionGrantStmt     *sql.Stmt
+	deleteSessionStmt             *sql.Stmt
+	deleteSessionFilesStmt        *sql.Stmt
+	deleteSessionMessagesStmt     *sql.Stmt
+	deleteSessionTodosStmt        *sql.Stmt
+	getFileStmt                   *sql.Stmt
+	getFileByPathAndSessionStmt   *sql.Stmt
+	getMessageStmt                *sql.Stmt
+	getPermissionGrantStmt        *sql.Stmt
+	getSessionByIDStmt            *sql.Stmt
+	listFilesByPathStmt           *sql.Stmt
+	listFilesBySessionStmt        *sql.Stmt
+	listLatestSessionFilesStmt    *sql.Stmt
+	listMessageUsageStmt          *sql.Stmt
+	listMessagesBySessionStmt     *sql.Stmt
+	listNewFilesStmt              *sql.Stmt
+	listPermissionGrantsStmt      *sql.Stmt
+	listSessionsStmt              *sql.Stmt
+	listTodosBySessionStmt        *sql.Stmt
+	updateMessageStmt             *sql.Stmt
+	updateSessionStmt             *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                          tx,
-		tx:                          tx,
-		createFileStmt:              q.createFileStmt,
-		createMessageStmt:           q.createMessageStmt,
-		createSessionStmt:           q.createSessionStmt,
-		deleteFileStmt:              q.deleteFileStmt,
-		deleteMessageStmt:           q.deleteMessageStmt,
-		deleteSessionStmt:           q.deleteSessionStmt,
-		deleteSessionFilesStmt:      q.deleteSessionFilesStmt,
-		deleteSessionMessagesStmt:   q.deleteSessionMessagesStmt,
-		getFileStmt:                 q.getFileStmt,
-		getFileByPathAndSessionStmt: q.getFileByPathAndSessionStmt,
-		getMessageStmt:              q.getMessageStmt,
-		getSessionByIDStmt:          q.getSessionByIDStmt,
-		listFilesByPathStmt:         q.listFilesByPathStmt,
-		listFilesBySessionStmt:      q.listFilesBySessionStmt,
-		listLatestSessionFilesStmt:  q.listLatestSessionFilesStmt,
-		listMessagesBySessionStmt:   q.listMessagesBySessionStmt,
-		listNewFilesStmt:            q.listNewFilesStmt,
-		listSessionsStmt:            q.listSessionsStmt,
-		updateMessageStmt:           q.updateMessageStmt,
-		updateSessionStmt:           q.updateSessionStmt,
+		db:                            tx,
+		tx:                            tx,
+		copyFileStmt:                  q.copyFileStmt,
+		copyMessageStmt:               q.copyMessageStmt,
+		createFileStmt:                q.createFileStmt,
+		createMessageStmt:             q.createMessageStmt,
+		createPermissionGrantStmt:     q.createPermissionGrantStmt,
+		createSessionStmt:             q.createSessionStmt,
+		createTodoStmt:                q.createTodoStmt,
+		deleteAllPermissionGrantsStmt: q.deleteAllPermissionGrantsStmt,
+		deleteFileStmt:                q.deleteFileStmt,
+		deleteMessageStmt:             q.deleteMessageStmt,
+		deletePermissionGrantStmt:     q.deletePermissionGrantStmt,
+		deleteSessionStmt:             q.deleteSessionStmt,
+		deleteSessionFilesStmt:        q.deleteSessionFilesStmt,
+		deleteSessionMessagesStmt:     q.deleteSessionMessagesStmt,
+		deleteSessionTodosStmt:        q.deleteSessionTodosStmt,
+		getFileStmt:                   q.getFileStmt,
+		getFileByPathAndSessionStmt:   q.getFileByPathAndSessionStmt,
+		getMessageStmt:                q.getMessageStmt,
+		getPermissionGrantStmt:        q.getPermissionGrantStmt,
+		getSessionByIDStmt:            q.getSessionByIDStmt,
+		listFilesByPathStmt:           q.listFilesByPathStmt,
+		listFilesBySessionStmt:        q.listFilesBySessionStmt,
+		listLatestSessionFilesStmt:    q.listLatestSessionFilesStmt,
+		listMessageUsageStmt:          q.listMessageUsageStmt,
+		listMessagesBySessionStmt:     q.listMessagesBySessionStmt,
+		listNewFilesStmt:              q.listNewFilesStmt,
+		listPermissionGrantsStmt:      q.listPermissionGrantsStmt,
+		listSessionsStmt:              q.listSessionsStmt,
+		listTodosBySessionStmt:        q.listTodosBySessionStmt,
+		updateMessageStmt:             q.updateMessageStmt,
+		updateSessionStmt:             q.updateSessionStmt,
 	}
 }
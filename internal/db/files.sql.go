@@ -7,8 +7,52 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
+const copyFile = `-- name: CopyFile :exec
+INSERT INTO files (
+    id,
+    session_id,
+    path,
+    content,
+    version,
+    is_new,
+    message_id,
+    created_at,
+    updated_at
+)
+SELECT
+    ?,
+    ?,
+    path,
+    content,
+    version,
+    is_new,
+    ?,
+    created_at,
+    updated_at
+FROM files
+WHERE files.id = ?
+`
+
+type CopyFileParams struct {
+	ID        string         `json:"id"`
+	SessionID string         `json:"session_id"`
+	MessageID sql.NullString `json:"message_id"`
+	SourceID  string         `json:"source_id"`
+}
+
+func (q *Queries) CopyFile(ctx context.Context, arg CopyFileParams) error {
+	_, err := q.exec(ctx, q.copyFileStmt, copyFile,
+		arg.ID,
+		arg.SessionID,
+		arg.MessageID,
+		arg.SourceID,
+	)
+	return err
+}
+
 const createFile = `-- name: CreateFile :one
 INSERT INTO files (
     id,
@@ -16,20 +60,24 @@ INSERT INTO files (
     path,
     content,
     version,
+    is_new,
+    message_id,
     created_at,
     updated_at
 ) VALUES (
-    ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
+    ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
 )
-RETURNING id, session_id, path, content, version, created_at, updated_at
+RETURNING id, session_id, path, content, version, created_at, updated_at, is_new, message_id
 `
 
 type CreateFileParams struct {
-	ID        string `json:"id"`
-	SessionID string `json:"session_id"`
-	Path      string `json:"path"`
-	Content   string `json:"content"`
-	Version   int64  `json:"version"`
+	ID        string         `json:"id"`
+	SessionID string         `json:"session_id"`
+	Path      string         `json:"path"`
+	Content   string         `json:"content"`
+	Version   int64          `json:"version"`
+	IsNew     int64          `json:"is_new"`
+	MessageID sql.NullString `json:"message_id"`
 }
 
 func (q *Queries) CreateFile(ctx context.Context, arg CreateFileParams) (File, error) {
@@ -39,6 +87,8 @@ func (q *Queries) CreateFile(ctx context.Context, arg CreateFileParams) (File, e
 		arg.Path,
 		arg.Content,
 		arg.Version,
+		arg.IsNew,
+		arg.MessageID,
 	)
 	var i File
 	err := row.Scan(
@@ -49,6 +99,8 @@ func (q *Queries) CreateFile(ctx context.Context, arg CreateFileParams) (File, e
 		&i.Version,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsNew,
+		&i.MessageID,
 	)
 	return i, err
 }
@@ -74,7 +126,7 @@ func (q *Queries) DeleteSessionFiles(ctx context.Context, sessionID string) erro
 }
 
 const getFile = `-- name: GetFile :one
-SELECT id, session_id, path, content, version, created_at, updated_at
+SELECT id, session_id, path, content, version, created_at, updated_at, is_new, message_id
 FROM files
 WHERE id = ? LIMIT 1
 `
@@ -90,12 +142,14 @@ func (q *Queries) GetFile(ctx context.Context, id string) (File, error) {
 		&i.Version,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsNew,
+		&i.MessageID,
 	)
 	return i, err
 }
 
 const getFileByPathAndSession = `-- name: GetFileByPathAndSession :one
-SELECT id, session_id, path, content, version, created_at, updated_at
+SELECT id, session_id, path, content, version, created_at, updated_at, is_new, message_id
 FROM files
 WHERE path = ? AND session_id = ?
 ORDER BY version DESC, created_at DESC
@@ -118,12 +172,14 @@ func (q *Queries) GetFileByPathAndSession(ctx context.Context, arg GetFileByPath
 		&i.Version,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsNew,
+		&i.MessageID,
 	)
 	return i, err
 }
 
 const listFilesByPath = `-- name: ListFilesByPath :many
-SELECT id, session_id, path, content, version, created_at, updated_at
+SELECT id, session_id, path, content, version, created_at, updated_at, is_new, message_id
 FROM files
 WHERE path = ?
 ORDER BY version DESC, created_at DESC
@@ -146,6 +202,8 @@ func (q *Queries) ListFilesByPath(ctx context.Context, path string) ([]File, err
 			&i.Version,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.IsNew,
+			&i.MessageID,
 		); err != nil {
 			return nil, err
 		}
@@ -161,10 +219,10 @@ func (q *Queries) ListFilesByPath(ctx context.Context, path string) ([]File, err
 }
 
 const listFilesBySession = `-- name: ListFilesBySession :many
-SELECT id, session_id, path, content, version, created_at, updated_at
+SELECT id, session_id, path, content, version, created_at, updated_at, is_new, message_id
 FROM files
 WHERE session_id = ?
-ORDER BY version ASC, created_at ASC
+ORDER BY rowid ASC
 `
 
 func (q *Queries) ListFilesBySession(ctx context.Context, sessionID string) ([]File, error) {
@@ -184,6 +242,8 @@ func (q *Queries) ListFilesBySession(ctx context.Context, sessionID string) ([]F
 			&i.Version,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.IsNew,
+			&i.MessageID,
 		); err != nil {
 			return nil, err
 		}
@@ -199,7 +259,7 @@ func (q *Queries) ListFilesBySession(ctx context.Context, sessionID string) ([]F
 }
 
 const listLatestSessionFiles = `-- name: ListLatestSessionFiles :many
-SELECT f.id, f.session_id, f.path, f.content, f.version, f.created_at, f.updated_at
+SELECT f.id, f.session_id, f.path, f.content, f.version, f.created_at, f.updated_at, f.is_new, f.message_id
 FROM files f
 INNER JOIN (
     SELECT path, MAX(version) as max_version, MAX(created_at) as max_created_at
@@ -227,6 +287,8 @@ func (q *Queries) ListLatestSessionFiles(ctx context.Context, sessionID string)
 			&i.Version,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.IsNew,
+			&i.MessageID,
 		); err != nil {
 			return nil, err
 		}
@@ -242,7 +304,7 @@ func (q *Queries) ListLatestSessionFiles(ctx context.Context, sessionID string)
 }
 
 const listNewFiles = `-- name: ListNewFiles :many
-SELECT id, session_id, path, content, version, created_at, updated_at
+SELECT id, session_id, path, content, version, created_at, updated_at, is_new, message_id
 FROM files
 WHERE is_new = 1
 ORDER BY version DESC, created_at DESC
@@ -265,6 +327,8 @@ func (q *Queries) ListNewFiles(ctx context.Context) ([]File, error) {
 			&i.Version,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.IsNew,
+			&i.MessageID,
 		); err != nil {
 			return nil, err
 		}
@@ -10,6 +10,43 @@ import (
 	"database/sql"
 )
 
+const copyMessage = `-- name: CopyMessage :exec
+INSERT INTO messages (
+    id,
+    session_id,
+    role,
+    parts,
+    model,
+    provider,
+    created_at,
+    updated_at,
+    finished_at
+)
+SELECT
+    ?,
+    ?,
+    role,
+    parts,
+    model,
+    provider,
+    created_at,
+    updated_at,
+    finished_at
+FROM messages
+WHERE messages.id = ?
+`
+
+type CopyMessageParams struct {
+	ID        string `json:"id"`
+	SessionID string `json:"session_id"`
+	SourceID  string `json:"source_id"`
+}
+
+func (q *Queries) CopyMessage(ctx context.Context, arg CopyMessageParams) error {
+	_, err := q.exec(ctx, q.copyMessageStmt, copyMessage, arg.ID, arg.SessionID, arg.SourceID)
+	return err
+}
+
 const createMessage = `-- name: CreateMessage :one
 INSERT INTO messages (
     id,
@@ -18,21 +55,31 @@ INSERT INTO messages (
     parts,
     model,
     provider,
+    input_tokens,
+    output_tokens,
+    cache_read_tokens,
+    cache_write_tokens,
+    cost,
     created_at,
     updated_at
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
 )
-RETURNING id, session_id, role, parts, model, created_at, updated_at, finished_at, provider
+RETURNING id, session_id, role, parts, model, created_at, updated_at, finished_at, provider, input_tokens, output_tokens, cache_read_tokens, cache_write_tokens, cost
 `
 
 type CreateMessageParams struct {
-	ID        string         `json:"id"`
-	SessionID string         `json:"session_id"`
-	Role      string         `json:"role"`
-	Parts     string         `json:"parts"`
-	Model     sql.NullString `json:"model"`
-	Provider  sql.NullString `json:"provider"`
+	ID               string         `json:"id"`
+	SessionID        string         `json:"session_id"`
+	Role             string         `json:"role"`
+	Parts            string         `json:"parts"`
+	Model            sql.NullString `json:"model"`
+	Provider         sql.NullString `json:"provider"`
+	InputTokens      int64          `json:"input_tokens"`
+	OutputTokens     int64          `json:"output_tokens"`
+	CacheReadTokens  int64          `json:"cache_read_tokens"`
+	CacheWriteTokens int64          `json:"cache_write_tokens"`
+	Cost             float64        `json:"cost"`
 }
 
 func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error) {
@@ -43,6 +90,11 @@ func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (M
 		arg.Parts,
 		arg.Model,
 		arg.Provider,
+		arg.InputTokens,
+		arg.OutputTokens,
+		arg.CacheReadTokens,
+		arg.CacheWriteTokens,
+		arg.Cost,
 	)
 	var i Message
 	err := row.Scan(
@@ -55,6 +107,11 @@ func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (M
 		&i.UpdatedAt,
 		&i.FinishedAt,
 		&i.Provider,
+		&i.InputTokens,
+		&i.OutputTokens,
+		&i.CacheReadTokens,
+		&i.CacheWriteTokens,
+		&i.Cost,
 	)
 	return i, err
 }
@@ -80,7 +137,7 @@ func (q *Queries) DeleteSessionMessages(ctx context.Context, sessionID string) e
 }
 
 const getMessage = `-- name: GetMessage :one
-SELECT id, session_id, role, parts, model, created_at, updated_at, finished_at, provider
+SELECT id, session_id, role, parts, model, created_at, updated_at, finished_at, provider, input_tokens, output_tokens, cache_read_tokens, cache_write_tokens, cost
 FROM messages
 WHERE id = ? LIMIT 1
 `
@@ -98,12 +155,83 @@ func (q *Queries) GetMessage(ctx context.Context, id string) (Message, error) {
 		&i.UpdatedAt,
 		&i.FinishedAt,
 		&i.Provider,
+		&i.InputTokens,
+		&i.OutputTokens,
+		&i.CacheReadTokens,
+		&i.CacheWriteTokens,
+		&i.Cost,
 	)
 	return i, err
 }
 
+const listMessageUsage = `-- name: ListMessageUsage :many
+SELECT
+    messages.session_id,
+    sessions.title AS session_title,
+    messages.model,
+    messages.provider,
+    messages.created_at,
+    messages.input_tokens,
+    messages.output_tokens,
+    messages.cache_read_tokens,
+    messages.cache_write_tokens,
+    messages.cost
+FROM messages
+JOIN sessions ON sessions.id = messages.session_id
+WHERE messages.created_at >= ?
+    AND (messages.input_tokens + messages.output_tokens + messages.cache_read_tokens + messages.cache_write_tokens) > 0
+ORDER BY messages.created_at ASC
+`
+
+type ListMessageUsageRow struct {
+	SessionID        string         `json:"session_id"`
+	SessionTitle     string         `json:"session_title"`
+	Model            sql.NullString `json:"model"`
+	Provider         sql.NullString `json:"provider"`
+	CreatedAt        int64          `json:"created_at"`
+	InputTokens      int64          `json:"input_tokens"`
+	OutputTokens     int64          `json:"output_tokens"`
+	CacheReadTokens  int64          `json:"cache_read_tokens"`
+	CacheWriteTokens int64          `json:"cache_write_tokens"`
+	Cost             float64        `json:"cost"`
+}
+
+func (q *Queries) ListMessageUsage(ctx context.Context, since int64) ([]ListMessageUsageRow, error) {
+	rows, err := q.query(ctx, q.listMessageUsageStmt, listMessageUsage, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListMessageUsageRow{}
+	for rows.Next() {
+		var i ListMessageUsageRow
+		if err := rows.Scan(
+			&i.SessionID,
+			&i.SessionTitle,
+			&i.Model,
+			&i.Provider,
+			&i.CreatedAt,
+			&i.InputTokens,
+			&i.OutputTokens,
+			&i.CacheReadTokens,
+			&i.CacheWriteTokens,
+			&i.Cost,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listMessagesBySession = `-- name: ListMessagesBySession :many
-SELECT id, session_id, role, parts, model, created_at, updated_at, finished_at, provider
+SELECT id, session_id, role, parts, model, created_at, updated_at, finished_at, provider, input_tokens, output_tokens, cache_read_tokens, cache_write_tokens, cost
 FROM messages
 WHERE session_id = ?
 ORDER BY created_at ASC
@@ -128,6 +256,11 @@ func (q *Queries) ListMessagesBySession(ctx context.Context, sessionID string) (
 			&i.UpdatedAt,
 			&i.FinishedAt,
 			&i.Provider,
+			&i.InputTokens,
+			&i.OutputTokens,
+			&i.CacheReadTokens,
+			&i.CacheWriteTokens,
+			&i.Cost,
 		); err != nil {
 			return nil, err
 		}
@@ -147,17 +280,42 @@ UPDATE messages
 SET
     parts = ?,
     finished_at = ?,
+    input_tokens = ?,
+    output_tokens = ?,
+    cache_read_tokens = ?,
+    cache_write_tokens = ?,
+    cost = ?,
+    model = ?,
+    provider = ?,
     updated_at = strftime('%s', 'now')
 WHERE id = ?
 `
 
 type UpdateMessageParams struct {
-	Parts      string        `json:"parts"`
-	FinishedAt sql.NullInt64 `json:"finished_at"`
-	ID         string        `json:"id"`
+	Parts            string         `json:"parts"`
+	FinishedAt       sql.NullInt64  `json:"finished_at"`
+	InputTokens      int64          `json:"input_tokens"`
+	OutputTokens     int64          `json:"output_tokens"`
+	CacheReadTokens  int64          `json:"cache_read_tokens"`
+	CacheWriteTokens int64          `json:"cache_write_tokens"`
+	Cost             float64        `json:"cost"`
+	Model            sql.NullString `json:"model"`
+	Provider         sql.NullString `json:"provider"`
+	ID               string         `json:"id"`
 }
 
 func (q *Queries) UpdateMessage(ctx context.Context, arg UpdateMessageParams) error {
-	_, err := q.exec(ctx, q.updateMessageStmt, updateMessage, arg.Parts, arg.FinishedAt, arg.ID)
+	_, err := q.exec(ctx, q.updateMessageStmt, updateMessage,
+		arg.Parts,
+		arg.FinishedAt,
+		arg.InputTokens,
+		arg.OutputTokens,
+		arg.CacheReadTokens,
+		arg.CacheWriteTokens,
+		arg.Cost,
+		arg.Model,
+		arg.Provider,
+		arg.ID,
+	)
 	return err
 }
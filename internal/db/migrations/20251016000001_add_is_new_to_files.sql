@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Mark file history entries for files that did not exist before the agent created them
+ALTER TABLE files ADD COLUMN is_new INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE files DROP COLUMN is_new;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Permissions the user chose to always allow in this project
+CREATE TABLE IF NOT EXISTS permission_grants (
+    id TEXT PRIMARY KEY,
+    tool_name TEXT NOT NULL,
+    action TEXT NOT NULL,
+    path TEXT NOT NULL,
+    command TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL,  -- Unix timestamp in seconds
+    UNIQUE(tool_name, action, path, command)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS permission_grants;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Whether the session is planning (read-only tools) or working normally
+ALTER TABLE sessions ADD COLUMN mode TEXT NOT NULL DEFAULT 'normal';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN mode;
+-- +goose StatementEnd
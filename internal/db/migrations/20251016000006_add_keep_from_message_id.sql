@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- First message kept verbatim after the summary of a compacted session
+ALTER TABLE sessions ADD COLUMN keep_from_message_id TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN keep_from_message_id;
+-- +goose StatementEnd
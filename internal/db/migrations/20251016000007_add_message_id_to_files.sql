@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Record the message whose tool calls produced each file version
+ALTER TABLE files ADD COLUMN message_id TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE files DROP COLUMN message_id;
+-- +goose StatementEnd
@@ -9,24 +9,35 @@ import (
 )
 
 type Querier interface {
+	CopyFile(ctx context.Context, arg CopyFileParams) error
+	CopyMessage(ctx context.Context, arg CopyMessageParams) error
 	CreateFile(ctx context.Context, arg CreateFileParams) (File, error)
 	CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error)
+	CreatePermissionGrant(ctx context.Context, arg CreatePermissionGrantParams) error
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateTodo(ctx context.Context, arg CreateTodoParams) error
+	DeleteAllPermissionGrants(ctx context.Context) error
 	DeleteFile(ctx context.Context, id string) error
 	DeleteMessage(ctx context.Context, id string) error
+	DeletePermissionGrant(ctx context.Context, id string) error
 	DeleteSession(ctx context.Context, id string) error
 	DeleteSessionFiles(ctx context.Context, sessionID string) error
 	DeleteSessionMessages(ctx context.Context, sessionID string) error
+	DeleteSessionTodos(ctx context.Context, sessionID string) error
 	GetFile(ctx context.Context, id string) (File, error)
 	GetFileByPathAndSession(ctx context.Context, arg GetFileByPathAndSessionParams) (File, error)
 	GetMessage(ctx context.Context, id string) (Message, error)
+	GetPermissionGrant(ctx context.Context, arg GetPermissionGrantParams) (PermissionGrant, error)
 	GetSessionByID(ctx context.Context, id string) (Session, error)
 	ListFilesByPath(ctx context.Context, path string) ([]File, error)
 	ListFilesBySession(ctx context.Context, sessionID string) ([]File, error)
 	ListLatestSessionFiles(ctx context.Context, sessionID string) ([]File, error)
+	ListMessageUsage(ctx context.Context, since int64) ([]ListMessageUsageRow, error)
 	ListMessagesBySession(ctx context.Context, sessionID string) ([]Message, error)
 	ListNewFiles(ctx context.Context) ([]File, error)
+	ListPermissionGrants(ctx context.Context) ([]PermissionGrant, error)
 	ListSessions(ctx context.Context) ([]Session, error)
+	ListTodosBySession(ctx context.Context, sessionID string) ([]Todo, error)
 	UpdateMessage(ctx context.Context, arg UpdateMessageParams) error
 	UpdateSession(ctx context.Context, arg UpdateSessionParams) (Session, error)
 }
@@ -14,7 +14,7 @@ LIMIT 1;
 SELECT *
 FROM files
 WHERE session_id = ?
-ORDER BY version ASC, created_at ASC;
+ORDER BY rowid ASC;
 
 -- name: ListFilesByPath :many
 SELECT *
@@ -29,13 +29,40 @@ INSERT INTO files (
     path,
     content,
     version,
+    is_new,
+    message_id,
     created_at,
     updated_at
 ) VALUES (
-    ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
+    ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
 )
 RETURNING *;
 
+-- name: CopyFile :exec
+INSERT INTO files (
+    id,
+    session_id,
+    path,
+    content,
+    version,
+    is_new,
+    message_id,
+    created_at,
+    updated_at
+)
+SELECT
+    sqlc.arg(id),
+    sqlc.arg(session_id),
+    path,
+    content,
+    version,
+    is_new,
+    sqlc.arg(message_id),
+    created_at,
+    updated_at
+FROM files
+WHERE files.id = sqlc.arg(source_id);
+
 -- name: DeleteFile :exec
 DELETE FROM files
 WHERE id = ?;
@@ -17,22 +17,77 @@ INSERT INTO messages (
     parts,
     model,
     provider,
+    input_tokens,
+    output_tokens,
+    cache_read_tokens,
+    cache_write_tokens,
+    cost,
     created_at,
     updated_at
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
 )
 RETURNING *;
 
+-- name: CopyMessage :exec
+INSERT INTO messages (
+    id,
+    session_id,
+    role,
+    parts,
+    model,
+    provider,
+    created_at,
+    updated_at,
+    finished_at
+)
+SELECT
+    sqlc.arg(id),
+    sqlc.arg(session_id),
+    role,
+    parts,
+    model,
+    provider,
+    created_at,
+    updated_at,
+    finished_at
+FROM messages
+WHERE messages.id = sqlc.arg(source_id);
+
 -- name: UpdateMessage :exec
 UPDATE messages
 SET
     parts = ?,
     finished_at = ?,
+    input_tokens = ?,
+    output_tokens = ?,
+    cache_read_tokens = ?,
+    cache_write_tokens = ?,
+    cost = ?,
+    model = ?,
+    provider = ?,
     updated_at = strftime('%s', 'now')
 WHERE id = ?;
 
 
+-- name: ListMessageUsage :many
+SELECT
+    messages.session_id,
+    sessions.title AS session_title,
+    messages.model,
+    messages.provider,
+    messages.created_at,
+    messages.input_tokens,
+    messages.output_tokens,
+    messages.cache_read_tokens,
+    messages.cache_write_tokens,
+    messages.cost
+FROM messages
+JOIN sessions ON sessions.id = messages.session_id
+WHERE messages.created_at >= sqlc.arg(since)
+    AND (messages.input_tokens + messages.output_tokens + messages.cache_read_tokens + messages.cache_write_tokens) > 0
+ORDER BY messages.created_at ASC;
+
 -- name: DeleteMessage :exec
 DELETE FROM messages
 WHERE id = ?;
@@ -0,0 +1,29 @@
+-- name: CreatePermissionGrant :exec
+INSERT OR IGNORE INTO permission_grants (
+    id,
+    tool_name,
+    action,
+    path,
+    command,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, strftime('%s', 'now')
+);
+
+-- name: GetPermissionGrant :one
+SELECT *
+FROM permission_grants
+WHERE tool_name = ? AND action = ? AND path = ? AND command = ?
+LIMIT 1;
+
+-- name: ListPermissionGrants :many
+SELECT *
+FROM permission_grants
+ORDER BY created_at ASC, tool_name ASC;
+
+-- name: DeletePermissionGrant :exec
+DELETE FROM permission_grants
+WHERE id = ?;
+
+-- name: DeleteAllPermissionGrants :exec
+DELETE FROM permission_grants;
@@ -0,0 +1,21 @@
+-- name: CreateTodo :exec
+INSERT INTO todos (
+    id,
+    session_id,
+    position,
+    content,
+    status,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, strftime('%s', 'now')
+);
+
+-- name: ListTodosBySession :many
+SELECT *
+FROM todos
+WHERE session_id = ?
+ORDER BY position ASC;
+
+-- name: DeleteSessionTodos :exec
+DELETE FROM todos
+WHERE session_id = ?;
@@ -0,0 +1,120 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// FileRestore describes how a single file is brought back to its state at a
+// checkpoint.
+type FileRestore struct {
+	Path string
+	// Current is the content of the file on disk.
+	Current string
+	// Content is the content the file had at the checkpoint.
+	Content string
+	// Delete is set when the file did not exist at the checkpoint.
+	Delete bool
+}
+
+// RestorePlan returns the files that changed since the messages in since,
+// which are the IDs of the messages from a checkpoint on, and how to restore
+// them. Files that already match their checkpoint state are omitted, so an
+// empty plan means there is nothing to undo.
+func (s *service) RestorePlan(ctx context.Context, sessionID string, since []string) ([]FileRestore, error) {
+	files, err := s.ListBySession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Versions are listed in the order they were recorded, so everything from
+	// the first version made by one of the messages on came after the
+	// checkpoint.
+	start := slices.IndexFunc(files, func(file File) bool {
+		return file.MessageID != "" && slices.Contains(since, file.MessageID)
+	})
+	if start < 0 {
+		return nil, nil
+	}
+
+	type versions struct{ before, after []File }
+	byPath := make(map[string]*versions)
+	for i, file := range files {
+		v, ok := byPath[file.Path]
+		if !ok {
+			v = &versions{}
+			byPath[file.Path] = v
+		}
+		if i < start {
+			v.before = append(v.before, file)
+		} else {
+			v.after = append(v.after, file)
+		}
+	}
+
+	var plan []FileRestore
+	for path, v := range byPath {
+		before, after := v.before, v.after
+		if len(after) == 0 {
+			continue
+		}
+
+		// The latest version recorded before the checkpoint is what the file
+		// looked like then. Files first touched after the checkpoint start
+		// with a version holding their original content.
+		restore := FileRestore{Path: path}
+		if len(before) > 0 {
+			restore.Content = before[len(before)-1].Content
+		} else {
+			restore.Content = after[0].Content
+			restore.Delete = after[0].IsNew
+		}
+
+		current, err := os.ReadFile(path)
+		exists := err == nil
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		restore.Current = string(current)
+
+		if restore.Delete && !exists {
+			continue
+		}
+		if !restore.Delete && exists && restore.Current == restore.Content {
+			continue
+		}
+		plan = append(plan, restore)
+	}
+
+	slices.SortFunc(plan, func(a, b FileRestore) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return plan, nil
+}
+
+// Restore writes or deletes the files in plan and records the restored
+// content as a new version.
+func (s *service) Restore(ctx context.Context, sessionID string, plan []FileRestore) error {
+	for _, restore := range plan {
+		if restore.Delete {
+			if err := os.Remove(restore.Path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to delete %s: %w", restore.Path, err)
+			}
+		} else {
+			if err := os.MkdirAll(filepath.Dir(restore.Path), 0o755); err != nil {
+				return fmt.Errorf("failed to create directory for %s: %w", restore.Path, err)
+			}
+			if err := os.WriteFile(restore.Path, []byte(restore.Content), 0o644); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", restore.Path, err)
+			}
+		}
+		if _, err := s.CreateVersion(ctx, sessionID, "", restore.Path, restore.Content); err != nil {
+			return fmt.Errorf("failed to record restored version of %s: %w", restore.Path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package history
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	conn, err := db.Connect(ctx, t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	q := db.New(conn)
+	svc := NewService(q, conn)
+
+	_, err = q.CreateSession(ctx, db.CreateSessionParams{ID: "s1", Title: "test"})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	edited := filepath.Join(dir, "edited.go")
+	created := filepath.Join(dir, "created.go")
+	untouched := filepath.Join(dir, "untouched.go")
+
+	// Both turns happen within the same second, so only the order the
+	// versions were recorded in tells them apart.
+	turnOne := []string{"user-1", "assistant-1"}
+	turnTwo := []string{"user-2", "assistant-2"}
+
+	// Turn one, before the checkpoint: edited.go is changed from v0 to v1.
+	_, err = svc.Create(ctx, "s1", "assistant-1", edited, "v0")
+	require.NoError(t, err)
+	_, err = svc.CreateVersion(ctx, "s1", "assistant-1", edited, "v1")
+	require.NoError(t, err)
+	_, err = svc.Create(ctx, "s1", "assistant-1", untouched, "same")
+	require.NoError(t, err)
+
+	// Turn two, after the checkpoint: edited.go becomes v2 and created.go is
+	// created from scratch.
+	_, err = svc.CreateVersion(ctx, "s1", "assistant-2", edited, "v2")
+	require.NoError(t, err)
+	_, err = svc.CreateNew(ctx, "s1", "assistant-2", created)
+	require.NoError(t, err)
+	_, err = svc.CreateVersion(ctx, "s1", "assistant-2", created, "package main")
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(edited, []byte("v2"), 0o644))
+	require.NoError(t, os.WriteFile(created, []byte("package main"), 0o644))
+	require.NoError(t, os.WriteFile(untouched, []byte("same"), 0o644))
+
+	plan, err := svc.RestorePlan(ctx, "s1", turnTwo)
+	require.NoError(t, err)
+	require.Equal(t, []FileRestore{
+		{Path: created, Current: "package main", Delete: true},
+		{Path: edited, Current: "v2", Content: "v1"},
+	}, plan)
+
+	require.NoError(t, svc.Restore(ctx, "s1", plan))
+	content, err := os.ReadFile(edited)
+	require.NoError(t, err)
+	require.Equal(t, "v1", string(content))
+	_, err = os.Stat(created)
+	require.True(t, os.IsNotExist(err))
+
+	// Everything is back at the checkpoint, so there is nothing left to undo.
+	plan, err = svc.RestorePlan(ctx, "s1", turnTwo)
+	require.NoError(t, err)
+	require.Empty(t, plan)
+
+	// Going further back restores the content from before the first turn.
+	plan, err = svc.RestorePlan(ctx, "s1", slices.Concat(turnOne, turnTwo))
+	require.NoError(t, err)
+	require.Len(t, plan, 1)
+	require.Equal(t, "v0", plan[0].Content)
+}
@@ -21,20 +21,34 @@ type File struct {
 	Path      string
 	Content   string
 	Version   int64
+	// IsNew reports whether the file did not exist before it was first
+	// recorded, i.e. it was created by a tool.
+	IsNew bool
+	// MessageID is the message whose tool calls produced this version. It
+	// is empty for versions that weren't made by the agent.
+	MessageID string
 	CreatedAt int64
 	UpdatedAt int64
 }
 
 type Service interface {
 	pubsub.Suscriber[File]
-	Create(ctx context.Context, sessionID, path, content string) (File, error)
-	CreateVersion(ctx context.Context, sessionID, path, content string) (File, error)
+	Create(ctx context.Context, sessionID, messageID, path, content string) (File, error)
+	// CreateNew records the initial, empty version of a file that did not
+	// exist before.
+	CreateNew(ctx context.Context, sessionID, messageID, path string) (File, error)
+	CreateVersion(ctx context.Context, sessionID, messageID, path, content string) (File, error)
 	Get(ctx context.Context, id string) (File, error)
 	GetByPathAndSession(ctx context.Context, path, sessionID string) (File, error)
 	ListBySession(ctx context.Context, sessionID string) ([]File, error)
 	ListLatestSessionFiles(ctx context.Context, sessionID string) ([]File, error)
 	Delete(ctx context.Context, id string) error
 	DeleteSessionFiles(ctx context.Context, sessionID string) error
+	// RestorePlan computes the changes that bring the files of a session
+	// back to their state before the messages in since.
+	RestorePlan(ctx context.Context, sessionID string, since []string) ([]FileRestore, error)
+	// Restore applies a plan returned by RestorePlan.
+	Restore(ctx context.Context, sessionID string, plan []FileRestore) error
 }
 
 type service struct {
@@ -51,11 +65,15 @@ func NewService(q *db.Queries, db *sql.DB) Service {
 	}
 }
 
-func (s *service) Create(ctx context.Context, sessionID, path, content string) (File, error) {
-	return s.createWithVersion(ctx, sessionID, path, content, InitialVersion)
+func (s *service) Create(ctx context.Context, sessionID, messageID, path, content string) (File, error) {
+	return s.createWithVersion(ctx, sessionID, messageID, path, content, InitialVersion, false)
 }
 
-func (s *service) CreateVersion(ctx context.Context, sessionID, path, content string) (File, error) {
+func (s *service) CreateNew(ctx context.Context, sessionID, messageID, path string) (File, error) {
+	return s.createWithVersion(ctx, sessionID, messageID, path, "", InitialVersion, true)
+}
+
+func (s *service) CreateVersion(ctx context.Context, sessionID, messageID, path, content string) (File, error) {
 	// Get the latest version for this path
 	files, err := s.q.ListFilesByPath(ctx, path)
 	if err != nil {
@@ -64,17 +82,17 @@ func (s *service) CreateVersion(ctx context.Context, sessionID, path, content st
 
 	if len(files) == 0 {
 		// No previous versions, create initial
-		return s.Create(ctx, sessionID, path, content)
+		return s.Create(ctx, sessionID, messageID, path, content)
 	}
 
 	// Get the latest version
 	latestFile := files[0] // Files are ordered by version DESC, created_at DESC
 	nextVersion := latestFile.Version + 1
 
-	return s.createWithVersion(ctx, sessionID, path, content, nextVersion)
+	return s.createWithVersion(ctx, sessionID, messageID, path, content, nextVersion, false)
 }
 
-func (s *service) createWithVersion(ctx context.Context, sessionID, path, content string, version int64) (File, error) {
+func (s *service) createWithVersion(ctx context.Context, sessionID, messageID, path, content string, version int64, isNew bool) (File, error) {
 	// Maximum number of retries for transaction conflicts
 	const maxRetries = 3
 	var file File
@@ -98,6 +116,8 @@ func (s *service) createWithVersion(ctx context.Context, sessionID, path, conten
 			Path:      path,
 			Content:   content,
 			Version:   version,
+			IsNew:     boolToInt(isNew),
+			MessageID: sql.NullString{String: messageID, Valid: messageID != ""},
 		})
 		if txErr != nil {
 			// Rollback the transaction
@@ -204,7 +224,16 @@ func (s *service) fromDBItem(item db.File) File {
 		Path:      item.Path,
 		Content:   item.Content,
 		Version:   item.Version,
+		IsNew:     item.IsNew != 0,
+		MessageID: item.MessageID.String,
 		CreatedAt: item.CreatedAt,
 		UpdatedAt: item.UpdatedAt,
 	}
 }
+
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,402 @@
+// Package job runs the shell commands the agent starts in the background,
+// keeping the latest lines of their output so they can be read while the
+// commands run.
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/shell"
+)
+
+const (
+	// maxLines is how many lines of output are kept for each job. Older
+	// lines are dropped.
+	maxLines = 5000
+	// maxLineLength is how many bytes of a line are kept.
+	maxLineLength = 4096
+	// stopTimeout is how long a job has to exit after it is asked to before
+	// it is killed.
+	stopTimeout = 5 * time.Second
+	// maxEnded is how many ended jobs are kept. Older ones are forgotten.
+	maxEnded = 50
+)
+
+// ErrNotFound is returned for job IDs that don't exist.
+var ErrNotFound = errors.New("job not found")
+
+// Status is the state of a job.
+type Status string
+
+const (
+	StatusRunning Status = "running"
+	StatusExited  Status = "exited"
+	StatusStopped Status = "stopped"
+)
+
+// Job describes a background job. It is published when the job starts and
+// when it ends.
+type Job struct {
+	ID        int       `json:"id"`
+	SessionID string    `json:"session_id"`
+	Command   string    `json:"command"`
+	Dir       string    `json:"dir"`
+	PID       int       `json:"pid"`
+	Status    Status    `json:"status"`
+	ExitCode  int       `json:"exit_code"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at,omitzero"`
+}
+
+// Line is a line of output of a job. Lines of stdout and stderr are numbered
+// together from 1, in the order they were written.
+type Line struct {
+	N      int
+	Stderr bool
+	Text   string
+}
+
+type Service interface {
+	pubsub.Suscriber[Job]
+	// Start runs command with bash in dir, in the background. When sandbox
+	// is not nil, the command runs in it.
+	Start(sessionID, command, dir string, sandbox *shell.Sandbox) (Job, error)
+	// Get returns the job with the given ID.
+	Get(id int) (Job, error)
+	// List returns the jobs started in a session, in the order they were
+	// started.
+	List(sessionID string) []Job
+	// Output returns the lines of output of a job that are kept.
+	Output(id int) (Job, []Line, error)
+	// Wait waits until a line after line since of the output of a job
+	// matches, the job ends or ctx is done. It returns the line that
+	// matched, or a zero Line when none did.
+	Wait(ctx context.Context, id, since int, match func(string) bool) (Line, Job, error)
+	// Stop terminates a job and the processes it started.
+	Stop(id int) (Job, error)
+	// Shutdown stops every running job.
+	Shutdown()
+}
+
+type service struct {
+	*pubsub.Broker[Job]
+
+	mu     sync.Mutex
+	nextID int
+	procs  map[int]*process
+}
+
+func NewService() Service {
+	return &service{
+		Broker: pubsub.NewBroker[Job](),
+		procs:  make(map[int]*process),
+	}
+}
+
+func (s *service) Start(sessionID, command, dir string, sandbox *shell.Sandbox) (Job, error) {
+	p := &process{changed: make(chan struct{}), done: make(chan struct{})}
+	stdout := &lineWriter{p: p}
+	stderr := &lineWriter{p: p, stderr: true}
+
+	cmd, err := shellCommand(command)
+	if err != nil {
+		return Job{}, err
+	}
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+	setProcessGroup(cmd)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Processes the job leaves behind may keep its output open; don't wait
+	// for them once the job itself has exited.
+	cmd.WaitDelay = time.Second
+	if sandbox != nil {
+		if err := sandbox.Command(cmd); err != nil {
+			return Job{}, fmt.Errorf("failed to sandbox background command: %w", err)
+		}
+	}
+	if err := cmd.Start(); err != nil {
+		return Job{}, fmt.Errorf("failed to start background command: %w", err)
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	p.job = Job{
+		ID:        s.nextID,
+		SessionID: sessionID,
+		Command:   command,
+		Dir:       dir,
+		PID:       cmd.Process.Pid,
+		Status:    StatusRunning,
+		StartedAt: time.Now(),
+	}
+	s.procs[p.job.ID] = p
+	job := p.job
+	s.mu.Unlock()
+	s.Publish(pubsub.CreatedEvent, job)
+
+	go func() {
+		_ = cmd.Wait()
+		stdout.flush()
+		stderr.flush()
+
+		p.mu.Lock()
+		p.job.Status = StatusExited
+		if p.stopping {
+			p.job.Status = StatusStopped
+		}
+		p.job.ExitCode = -1
+		if cmd.ProcessState != nil {
+			p.job.ExitCode = cmd.ProcessState.ExitCode()
+		}
+		p.job.EndedAt = time.Now()
+		job := p.job
+		p.notifyLocked()
+		p.mu.Unlock()
+		close(p.done)
+
+		s.mu.Lock()
+		s.pruneLocked()
+		s.mu.Unlock()
+		s.Publish(pubsub.UpdatedEvent, job)
+	}()
+	return job, nil
+}
+
+// pruneLocked forgets the oldest ended jobs beyond maxEnded.
+func (s *service) pruneLocked() {
+	var ended []int
+	for id, p := range s.procs {
+		p.mu.Lock()
+		if p.job.Status != StatusRunning {
+			ended = append(ended, id)
+		}
+		p.mu.Unlock()
+	}
+	if len(ended) <= maxEnded {
+		return
+	}
+	slices.Sort(ended)
+	for _, id := range ended[:len(ended)-maxEnded] {
+		delete(s.procs, id)
+	}
+}
+
+func (s *service) process(id int) (*process, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.procs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	return p, nil
+}
+
+func (s *service) Get(id int) (Job, error) {
+	p, err := s.process(id)
+	if err != nil {
+		return Job{}, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.job, nil
+}
+
+func (s *service) List(sessionID string) []Job {
+	s.mu.Lock()
+	procs := make([]*process, 0, len(s.procs))
+	for _, p := range s.procs {
+		procs = append(procs, p)
+	}
+	s.mu.Unlock()
+
+	var jobs []Job
+	for _, p := range procs {
+		p.mu.Lock()
+		if p.job.SessionID == sessionID {
+			jobs = append(jobs, p.job)
+		}
+		p.mu.Unlock()
+	}
+	slices.SortFunc(jobs, func(a, b Job) int { return a.ID - b.ID })
+	return jobs
+}
+
+func (s *service) Output(id int) (Job, []Line, error) {
+	p, err := s.process(id)
+	if err != nil {
+		return Job{}, nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.job, p.lines.all(), nil
+}
+
+func (s *service) Wait(ctx context.Context, id, since int, match func(string) bool) (Line, Job, error) {
+	p, err := s.process(id)
+	if err != nil {
+		return Line{}, Job{}, err
+	}
+	for {
+		p.mu.Lock()
+		for _, line := range p.lines.all() {
+			if line.N > since && match(line.Text) {
+				job := p.job
+				p.mu.Unlock()
+				return line, job, nil
+			}
+		}
+		since = p.lines.total
+		job, changed := p.job, p.changed
+		p.mu.Unlock()
+
+		if job.Status != StatusRunning {
+			return Line{}, job, nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return Line{}, job, ctx.Err()
+		}
+	}
+}
+
+func (s *service) Stop(id int) (Job, error) {
+	p, err := s.process(id)
+	if err != nil {
+		return Job{}, err
+	}
+	p.stop()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.job, nil
+}
+
+func (s *service) Shutdown() {
+	s.mu.Lock()
+	procs := make([]*process, 0, len(s.procs))
+	for _, p := range s.procs {
+		procs = append(procs, p)
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, p := range procs {
+		wg.Go(p.stop)
+	}
+	wg.Wait()
+}
+
+// process is a running or ended job and its output.
+type process struct {
+	mu       sync.Mutex
+	job      Job
+	lines    ring
+	stopping bool
+	// changed is closed and replaced whenever a line is added or the job
+	// ends.
+	changed chan struct{}
+	done    chan struct{}
+}
+
+func (p *process) notifyLocked() {
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+func (p *process) addLine(text string, stderr bool) {
+	if len(text) > maxLineLength {
+		text = text[:maxLineLength] + "…"
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lines.add(text, stderr)
+	p.notifyLocked()
+}
+
+// stop asks the processes of the job to exit, forces them to if they don't in
+// time, and waits for the job to end.
+func (p *process) stop() {
+	p.mu.Lock()
+	if p.job.Status != StatusRunning {
+		p.mu.Unlock()
+		return
+	}
+	p.stopping = true
+	pid := p.job.PID
+	p.mu.Unlock()
+
+	_ = terminate(pid)
+	select {
+	case <-p.done:
+		return
+	case <-time.After(stopTimeout):
+	}
+	_ = kill(pid)
+	<-p.done
+}
+
+// ring keeps the latest maxLines lines of output.
+type ring struct {
+	lines []Line
+	start int
+	total int
+}
+
+func (r *ring) add(text string, stderr bool) {
+	r.total++
+	line := Line{N: r.total, Stderr: stderr, Text: text}
+	if len(r.lines) < maxLines {
+		r.lines = append(r.lines, line)
+		return
+	}
+	r.lines[r.start] = line
+	r.start = (r.start + 1) % maxLines
+}
+
+func (r *ring) all() []Line {
+	return slices.Concat(r.lines[r.start:], r.lines[:r.start])
+}
+
+// lineWriter splits what a job writes to stdout or stderr into lines.
+type lineWriter struct {
+	p       *process
+	stderr  bool
+	partial []byte
+}
+
+func (w *lineWriter) Write(b []byte) (int, error) {
+	n := len(b)
+	for {
+		i := slices.Index(b, '\n')
+		if i == -1 {
+			break
+		}
+		line := append(w.partial, b[:i]...)
+		w.partial = w.partial[:0]
+		w.p.addLine(strings.TrimSuffix(string(line), "\r"), w.stderr)
+		b = b[i+1:]
+	}
+	w.partial = append(w.partial, b...)
+	// Output without line breaks is kept as lines of maxLineLength.
+	if len(w.partial) > maxLineLength {
+		w.flush()
+	}
+	return n, nil
+}
+
+// flush adds what is left of an unterminated last line.
+func (w *lineWriter) flush() {
+	if len(w.partial) > 0 {
+		w.p.addLine(strings.TrimSuffix(string(w.partial), "\r"), w.stderr)
+		w.partial = nil
+	}
+}
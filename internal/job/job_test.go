@@ -0,0 +1,123 @@
+package job
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRing(t *testing.T) {
+	t.Parallel()
+
+	var r ring
+	for i := range maxLines + 3 {
+		r.add(fmt.Sprint(i+1), false)
+	}
+	lines := r.all()
+	require.Len(t, lines, maxLines)
+	require.Equal(t, 4, lines[0].N)
+	require.Equal(t, "4", lines[0].Text)
+	require.Equal(t, maxLines+3, lines[len(lines)-1].N)
+	require.Equal(t, maxLines+3, r.total)
+}
+
+func TestLineWriter(t *testing.T) {
+	t.Parallel()
+
+	p := &process{changed: make(chan struct{})}
+	w := &lineWriter{p: p, stderr: true}
+	_, _ = w.Write([]byte("first\r\nsec"))
+	_, _ = w.Write([]byte("ond\nthird"))
+	w.flush()
+	_, _ = w.Write([]byte(strings.Repeat("x", maxLineLength+1)))
+
+	lines := p.lines.all()
+	require.Len(t, lines, 4)
+	require.Equal(t, Line{N: 1, Stderr: true, Text: "first"}, lines[0])
+	require.Equal(t, "second", lines[1].Text)
+	require.Equal(t, "third", lines[2].Text)
+	require.Equal(t, strings.Repeat("x", maxLineLength)+"…", lines[3].Text)
+}
+
+func TestPrune(t *testing.T) {
+	t.Parallel()
+
+	s := &service{procs: make(map[int]*process)}
+	for id := 1; id <= maxEnded+2; id++ {
+		s.procs[id] = &process{job: Job{ID: id, Status: StatusExited}}
+	}
+	s.procs[0] = &process{job: Job{Status: StatusRunning}}
+
+	s.pruneLocked()
+	require.Len(t, s.procs, maxEnded+1)
+	require.Contains(t, s.procs, 0)
+	require.NotContains(t, s.procs, 1)
+	require.NotContains(t, s.procs, 2)
+	require.Contains(t, s.procs, 3)
+}
+
+func TestService(t *testing.T) {
+	t.Parallel()
+
+	s := NewService()
+	t.Cleanup(s.Shutdown)
+
+	t.Run("output and exit code", func(t *testing.T) {
+		t.Parallel()
+		job, err := s.Start("output", "echo out; echo err >&2; exit 3", t.TempDir(), nil)
+		require.NoError(t, err)
+		require.Equal(t, StatusRunning, job.Status)
+
+		line, job, err := s.Wait(t.Context(), job.ID, 0, func(string) bool { return false })
+		require.NoError(t, err)
+		require.Zero(t, line)
+		require.Equal(t, StatusExited, job.Status)
+		require.Equal(t, 3, job.ExitCode)
+
+		_, lines, err := s.Output(job.ID)
+		require.NoError(t, err)
+		// The login shell may print lines of its own first.
+		var out, errOut []string
+		for _, line := range lines {
+			if line.Stderr {
+				errOut = append(errOut, line.Text)
+			} else {
+				out = append(out, line.Text)
+			}
+		}
+		require.Contains(t, out, "out")
+		require.Contains(t, errOut, "err")
+		require.NotContains(t, out, "err")
+		require.Len(t, s.List("output"), 1)
+	})
+
+	t.Run("wait for a line and stop", func(t *testing.T) {
+		t.Parallel()
+		job, err := s.Start("stop", "echo starting; sleep 0.1; echo listening on :8080; sleep 60", t.TempDir(), nil)
+		require.NoError(t, err)
+
+		line, job, err := s.Wait(t.Context(), job.ID, 0, func(s string) bool {
+			return strings.Contains(s, "listening")
+		})
+		require.NoError(t, err)
+		require.Equal(t, "listening on :8080", line.Text)
+		require.False(t, line.Stderr)
+		require.Positive(t, line.N)
+		require.Equal(t, StatusRunning, job.Status)
+
+		start := time.Now()
+		job, err = s.Stop(job.ID)
+		require.NoError(t, err)
+		require.Equal(t, StatusStopped, job.Status)
+		require.Less(t, time.Since(start), stopTimeout)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		_, err := s.Get(1000)
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+}
@@ -0,0 +1,29 @@
+//go:build unix
+
+package job
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// shellCommand runs command with bash as a login shell.
+func shellCommand(command string) (*exec.Cmd, error) {
+	return exec.Command("bash", "-lc", command), nil
+}
+
+// setProcessGroup starts cmd in a process group of its own, so that the
+// processes it starts can be stopped along with it.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// terminate asks the process group of pid to exit.
+func terminate(pid int) error {
+	return syscall.Kill(-pid, syscall.SIGTERM)
+}
+
+// kill forces the process group of pid to exit.
+func kill(pid int) error {
+	return syscall.Kill(-pid, syscall.SIGKILL)
+}
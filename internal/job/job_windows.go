@@ -0,0 +1,36 @@
+//go:build windows
+
+package job
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// shellCommand runs command with bash as a login shell. Windows has no bash
+// of its own; one such as Git Bash has to be in the PATH.
+func shellCommand(command string) (*exec.Cmd, error) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		return nil, fmt.Errorf("background commands need bash, which was not found in the PATH: %w", err)
+	}
+	return exec.Command(bash, "-lc", command), nil
+}
+
+// setProcessGroup starts cmd in a process group of its own, so that the
+// processes it starts can be stopped along with it.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// terminate asks the process tree of pid to exit.
+func terminate(pid int) error {
+	return exec.Command("taskkill", "/T", "/PID", strconv.Itoa(pid)).Run()
+}
+
+// kill forces the process tree of pid to exit.
+func kill(pid int) error {
+	return exec.Command("taskkill", "/F", "/T", "/PID", strconv.Itoa(pid)).Run()
+}
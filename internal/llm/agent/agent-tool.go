@@ -4,44 +4,128 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/csync"
 	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/lsp"
 	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/session"
 )
 
 type agentTool struct {
-	agent    Service
+	// newAgent creates the sub-agents tasks are delegated to.
+	newAgent func(config.Agent) (Service, error)
+	agents   *csync.Map[string, Service]
 	sessions session.Service
 	messages message.Service
+	// lspClients are the LSP servers sub-agents can be allowed.
+	lspClients map[string]*lsp.Client
 }
 
 const (
 	AgentToolName = "agent"
+
+	// defaultSubAgent is the agent tasks go to unless another one is asked
+	// for.
+	defaultSubAgent = "task"
 )
 
 type AgentParams struct {
 	Prompt string `json:"prompt"`
+	Agent  string `json:"agent,omitempty"`
 }
 
 func (b *agentTool) Name() string {
 	return AgentToolName
 }
 
+// subAgents returns the agents defined in the config that tasks can be
+// delegated to, besides the default task agent.
+func subAgents() []config.Agent {
+	var agents []config.Agent
+	for id, agent := range config.Get().Agents {
+		if id != "coder" && id != defaultSubAgent {
+			agents = append(agents, agent)
+		}
+	}
+	slices.SortFunc(agents, func(a, b config.Agent) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return agents
+}
+
+// lspToolNames are the tools agents get for their LSP servers, besides
+// refactor, which has to be an allowed tool too.
+var lspToolNames = []string{
+	tools.DiagnosticsToolName,
+	tools.DefinitionToolName,
+	tools.ReferencesToolName,
+	tools.HoverToolName,
+	tools.SymbolsToolName,
+}
+
+// subAgentTools returns the names of the tools NewAgent gives agent. The MCP
+// tools are known once the coder agent's tools are.
+func subAgentTools(agent config.Agent, lspClients map[string]*lsp.Client) []string {
+	hasLSP := len(allowedLSPClients(agent, lspClients)) > 0
+	var names []string
+	for _, name := range agent.AllowedTools {
+		if name != tools.RefactorToolName || hasLSP {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	for _, tool := range allowedMCPTools(agent, mcpTools) {
+		names = append(names, tool.Name())
+	}
+	if hasLSP {
+		names = append(names, lspToolNames...)
+	}
+	return names
+}
+
 func (b *agentTool) Info() tools.ToolInfo {
+	parameters := map[string]any{
+		"prompt": map[string]any{
+			"type":        "string",
+			"description": "The task for the agent to perform",
+		},
+	}
+	description := agentToolDescription
+	if agents := subAgents(); len(agents) > 0 {
+		ids := []string{defaultSubAgent}
+		var list strings.Builder
+		for _, agent := range agents {
+			ids = append(ids, agent.ID)
+			fmt.Fprintf(&list, "\n- %s:", agent.ID)
+			if agent.Description != "" {
+				fmt.Fprintf(&list, " %s", agent.Description)
+			}
+			fmt.Fprintf(&list, " Tools: %s.", strings.Join(subAgentTools(agent, b.lspClients), ", "))
+		}
+		description += "\n\nInstead of the default task agent described above, you can delegate to one of these agents by setting the agent parameter:" + list.String()
+		parameters["agent"] = map[string]any{
+			"type":        "string",
+			"description": "The agent to delegate the task to",
+			"enum":        ids,
+			"default":     defaultSubAgent,
+		}
+	}
 	return tools.ToolInfo{
 		Name:        AgentToolName,
-		Description: "Launch a new agent that has access to the following tools: GlobTool, GrepTool, LS, View. When you are searching for a keyword or file and are not confident that you will find the right match on the first try, use the Agent tool to perform the search for you. For example:\n\n- If you are searching for a keyword like \"config\" or \"logger\", or for questions like \"which file does X?\", the Agent tool is strongly recommended\n- If you want to read a specific file path, use the View or GlobTool tool instead of the Agent tool, to find the match more quickly\n- If you are searching for a specific class definition like \"class Foo\", use the GlobTool tool instead, to find the match more quickly\n\nUsage notes:\n1. Launch multiple agents concurrently whenever possible, to maximize performance; to do that, use a single message with multiple tool uses\n2. When the agent is done, it will return a single message back to you. The result returned by the agent is not visible to the user. To show the user the result, you should send a text message back to the user with a concise summary of the result.\n3. Each agent invocation is stateless. You will not be able to send additional messages to the agent, nor will the agent be able to communicate with you outside of its final report. Therefore, your prompt should contain a highly detailed task description for the agent to perform autonomously and you should specify exactly what information the agent should return back to you in its final and only message to you.\n4. The agent's outputs should generally be trusted\n5. IMPORTANT: The agent can not use Bash, Replace, Edit, so can not modify files. If you want to use these tools, use them directly instead of going through the agent.",
-		Parameters: map[string]any{
-			"prompt": map[string]any{
-				"type":        "string",
-				"description": "The task for the agent to perform",
-			},
-		},
-		Required: []string{"prompt"},
+		Description: description,
+		Parameters:  parameters,
+		Required:    []string{"prompt"},
 	}
 }
 
+const agentToolDescription = "Launch a new agent that has access to the following tools: GlobTool, GrepTool, LS, View. When you are searching for a keyword or file and are not confident that you will find the right match on the first try, use the Agent tool to perform the search for you. For example:\n\n- If you are searching for a keyword like \"config\" or \"logger\", or for questions like \"which file does X?\", the Agent tool is strongly recommended\n- If you want to read a specific file path, use the View or GlobTool tool instead of the Agent tool, to find the match more quickly\n- If you are searching for a specific class definition like \"class Foo\", use the GlobTool tool instead, to find the match more quickly\n\nUsage notes:\n1. Launch multiple agents concurrently whenever possible, to maximize performance; to do that, use a single message with multiple tool uses\n2. When the agent is done, it will return a single message back to you. The result returned by the agent is not visible to the user. To show the user the result, you should send a text message back to the user with a concise summary of the result.\n3. Each agent invocation is stateless. You will not be able to send additional messages to the agent, nor will the agent be able to communicate with you outside of its final report. Therefore, your prompt should contain a highly detailed task description for the agent to perform autonomously and you should specify exactly what information the agent should return back to you in its final and only message to you.\n4. The agent's outputs should generally be trusted\n5. IMPORTANT: The agent can not use Bash, Replace, Edit, so can not modify files. If you want to use these tools, use them directly instead of going through the agent."
+
 func (b *agentTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
 	var params AgentParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
@@ -50,6 +134,13 @@ func (b *agentTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolRes
 	if params.Prompt == "" {
 		return tools.NewTextErrorResponse("prompt is required"), nil
 	}
+	if params.Agent == "" {
+		params.Agent = defaultSubAgent
+	}
+	agentCfg, ok := config.Get().Agents[params.Agent]
+	if !ok || params.Agent == "coder" {
+		return tools.NewTextErrorResponse(fmt.Sprintf("unknown agent: %s", params.Agent)), nil
+	}
 
 	sessionID, messageID := tools.GetContextValues(ctx)
 	if sessionID == "" || messageID == "" {
@@ -61,7 +152,16 @@ func (b *agentTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolRes
 		return tools.ToolResponse{}, fmt.Errorf("error creating session: %s", err)
 	}
 
-	done, err := b.agent.Run(ctx, session.ID, params.Prompt)
+	agent, ok := b.agents.Get(agentCfg.ID)
+	if !ok {
+		agent, err = b.newAgent(agentCfg)
+		if err != nil {
+			return tools.ToolResponse{}, fmt.Errorf("failed to create %s agent: %w", agentCfg.ID, err)
+		}
+		b.agents.Set(agentCfg.ID, agent)
+	}
+
+	done, err := agent.Run(ctx, session.ID, params.Prompt)
 	if err != nil {
 		return tools.ToolResponse{}, fmt.Errorf("error generating agent: %s", err)
 	}
@@ -94,13 +194,16 @@ func (b *agentTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolRes
 }
 
 func NewAgentTool(
-	agent Service,
+	newAgent func(config.Agent) (Service, error),
 	sessions session.Service,
 	messages message.Service,
+	lspClients map[string]*lsp.Client,
 ) tools.BaseTool {
 	return &agentTool{
-		sessions: sessions,
-		messages: messages,
-		agent:    agent,
+		sessions:   sessions,
+		messages:   messages,
+		newAgent:   newAgent,
+		agents:     csync.NewMap[string, Service](),
+		lspClients: lspClients,
 	}
 }
@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubAgentAllowances(t *testing.T) {
+	t.Parallel()
+
+	mcpTools := []tools.BaseTool{
+		&McpTool{mcpName: "github", tool: mcp.Tool{Name: "search"}},
+		&McpTool{mcpName: "github", tool: mcp.Tool{Name: "create_issue"}},
+		&McpTool{mcpName: "docs", tool: mcp.Tool{Name: "lookup"}},
+	}
+	names := func(t []tools.BaseTool) []string {
+		var names []string
+		for _, tool := range t {
+			names = append(names, tool.Name())
+		}
+		return names
+	}
+
+	t.Run("all MCPs when not set", func(t *testing.T) {
+		t.Parallel()
+		require.Equal(t, mcpTools, allowedMCPTools(config.Agent{}, mcpTools))
+	})
+
+	t.Run("allowed MCPs and tools", func(t *testing.T) {
+		t.Parallel()
+		agent := config.Agent{AllowedMCP: map[string][]string{"github": {"search"}, "docs": {}}}
+		require.Equal(t, []string{"mcp_github_search", "mcp_docs_lookup"}, names(allowedMCPTools(agent, mcpTools)))
+	})
+
+	t.Run("no MCPs", func(t *testing.T) {
+		t.Parallel()
+		require.Empty(t, allowedMCPTools(config.Agent{AllowedMCP: map[string][]string{}}, mcpTools))
+	})
+
+	t.Run("allowed tools include refactor", func(t *testing.T) {
+		t.Parallel()
+		all := []tools.BaseTool{
+			tools.NewViewTool(nil, nil, t.TempDir()),
+			tools.NewGrepTool(t.TempDir()),
+			tools.NewRefactorTool(nil, nil, nil, t.TempDir()),
+		}
+		require.Equal(t, []string{"view", "grep"}, names(filterAllowedTools(config.Agent{AllowedTools: []string{"view", "grep"}}, all)))
+		require.Equal(t, []string{"view", "grep", "refactor"}, names(filterAllowedTools(config.Agent{}, all)))
+	})
+
+	t.Run("allowed LSPs", func(t *testing.T) {
+		t.Parallel()
+		clients := map[string]*lsp.Client{"gopls": nil, "tsserver": nil}
+		require.Len(t, allowedLSPClients(config.Agent{}, clients), 2)
+		require.Equal(t, map[string]*lsp.Client{"gopls": nil}, allowedLSPClients(config.Agent{AllowedLSP: []string{"gopls"}}, clients))
+		require.Empty(t, allowedLSPClients(config.Agent{AllowedLSP: []string{}}, clients))
+	})
+
+	t.Run("listed tools", func(t *testing.T) {
+		t.Parallel()
+		clients := map[string]*lsp.Client{"gopls": nil}
+		agent := config.Agent{
+			AllowedTools: []string{"view", "refactor"},
+			AllowedMCP:   map[string][]string{},
+			AllowedLSP:   []string{},
+		}
+		require.Equal(t, []string{"view"}, subAgentTools(agent, clients))
+
+		agent.AllowedLSP = []string{"gopls"}
+		require.Equal(t, []string{"view", "refactor", "diagnostics", "definition", "references", "hover", "symbols"}, subAgentTools(agent, clients))
+
+		require.Empty(t, subAgentTools(config.Agent{AllowedTools: []string{}}, clients))
+	})
+}
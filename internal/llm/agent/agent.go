@@ -13,6 +13,7 @@ import (
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/csync"
 	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/job"
 	"github.com/charmbracelet/crush/internal/llm/prompt"
 	"github.com/charmbracelet/crush/internal/llm/provider"
 	"github.com/charmbracelet/crush/internal/llm/tools"
@@ -24,12 +25,14 @@ import (
 	"github.com/charmbracelet/crush/internal/pubsub"
 	"github.com/charmbracelet/crush/internal/session"
 	"github.com/charmbracelet/crush/internal/shell"
+	"github.com/charmbracelet/crush/internal/todo"
 )
 
 // Common errors
 var (
 	ErrRequestCancelled = errors.New("request canceled by user")
 	ErrSessionBusy      = errors.New("session is currently processing another request")
+	ErrBudgetExceeded   = errors.New("budget exceeded")
 )
 
 type AgentEventType string
@@ -38,6 +41,12 @@ const (
 	AgentEventTypeError     AgentEventType = "error"
 	AgentEventTypeResponse  AgentEventType = "response"
 	AgentEventTypeSummarize AgentEventType = "summarize"
+	// AgentEventTypeFallback is published when the agent switches to a
+	// fallback model because the current one failed.
+	AgentEventTypeFallback AgentEventType = "fallback"
+	// AgentEventTypeCompact is published when the agent compacts a session
+	// that got close to the context window of its model.
+	AgentEventTypeCompact AgentEventType = "compact"
 )
 
 type AgentEvent struct {
@@ -45,7 +54,7 @@ type AgentEvent struct {
 	Message message.Message
 	Error   error
 
-	// When summarizing
+	// When summarizing, compacting or falling back to another model
 	SessionID string
 	Progress  string
 	Done      bool
@@ -63,6 +72,8 @@ type Service interface {
 	UpdateModel() error
 	QueuedPrompts(sessionID string) int
 	ClearQueue(sessionID string)
+	// ExtendBudget allows the session to spend another max_session_cost.
+	ExtendBudget(sessionID string)
 }
 
 type agent struct {
@@ -70,6 +81,7 @@ type agent struct {
 	agentCfg config.Agent
 	sessions session.Service
 	messages message.Service
+	todos    todo.Service
 	mcpTools []McpTool
 
 	tools *csync.LazySlice[tools.BaseTool]
@@ -78,6 +90,12 @@ type agent struct {
 
 	provider   provider.Provider
 	providerID string
+	// fallbacks are tried in order when provider keeps failing.
+	fallbacks []chainModel
+
+	// planProvider and planFallbacks answer in sessions in plan mode.
+	planProvider  provider.Provider
+	planFallbacks []chainModel
 
 	titleProvider       provider.Provider
 	summarizeProvider   provider.Provider
@@ -86,6 +104,8 @@ type agent struct {
 	activeRequests *csync.Map[string, context.CancelFunc]
 
 	promptQueue *csync.Map[string, []string]
+
+	budgetExtensions *csync.Map[string, int]
 }
 
 var agentPromptMap = map[string]prompt.PromptID{
@@ -93,6 +113,74 @@ var agentPromptMap = map[string]prompt.PromptID{
 	"task":  prompt.PromptTask,
 }
 
+// agentSystemPrompt returns a function that builds the system prompt of the
+// agent for a provider. Agents defined in the config bring their own prompt.
+func agentSystemPrompt(agentCfg config.Agent) (func(providerID string) string, error) {
+	if agentCfg.Prompt != "" {
+		systemPrompt, err := prompt.AgentPrompt(agentCfg)
+		if err != nil {
+			return nil, err
+		}
+		return func(string) string { return systemPrompt }, nil
+	}
+
+	promptID := agentPromptMap[agentCfg.ID]
+	if promptID == "" {
+		promptID = prompt.PromptDefault
+	}
+	return func(providerID string) string {
+		return prompt.GetPrompt(promptID, providerID, config.Get().Options.ContextPaths...)
+	}, nil
+}
+
+// allowedLSPClients returns the LSP clients the agent may use.
+func allowedLSPClients(agentCfg config.Agent, lspClients map[string]*lsp.Client) map[string]*lsp.Client {
+	if agentCfg.AllowedLSP == nil {
+		return lspClients
+	}
+	allowed := make(map[string]*lsp.Client)
+	for name, client := range lspClients {
+		if slices.Contains(agentCfg.AllowedLSP, name) {
+			allowed[name] = client
+		}
+	}
+	return allowed
+}
+
+// allowedMCPTools returns the MCP tools the agent may use.
+func allowedMCPTools(agentCfg config.Agent, mcpTools []tools.BaseTool) []tools.BaseTool {
+	if agentCfg.AllowedMCP == nil {
+		return mcpTools
+	}
+	var allowed []tools.BaseTool
+	for _, tool := range mcpTools {
+		mcpTool, ok := tool.(*McpTool)
+		if !ok {
+			continue
+		}
+		toolNames, ok := agentCfg.AllowedMCP[mcpTool.mcpName]
+		if ok && (len(toolNames) == 0 || slices.Contains(toolNames, mcpTool.tool.Name)) {
+			allowed = append(allowed, tool)
+		}
+	}
+	return allowed
+}
+
+// filterAllowedTools returns the tools of all that are in the agent's allowed
+// tools, or all of them when it has none set.
+func filterAllowedTools(agentCfg config.Agent, all []tools.BaseTool) []tools.BaseTool {
+	if agentCfg.AllowedTools == nil {
+		return all
+	}
+	var allowed []tools.BaseTool
+	for _, tool := range all {
+		if slices.Contains(agentCfg.AllowedTools, tool.Name()) {
+			allowed = append(allowed, tool)
+		}
+	}
+	return allowed
+}
+
 func NewAgent(
 	ctx context.Context,
 	agentCfg config.Agent,
@@ -101,6 +189,8 @@ func NewAgent(
 	sessions session.Service,
 	messages message.Service,
 	history history.Service,
+	todos todo.Service,
+	jobs job.Service,
 	lspClients map[string]*lsp.Client,
 ) (Service, error) {
 	cfg := config.Get()
@@ -108,17 +198,13 @@ func NewAgent(
 	var agentToolFn func() (tools.BaseTool, error)
 	if agentCfg.ID == "coder" {
 		agentToolFn = func() (tools.BaseTool, error) {
-			taskAgentCfg := config.Get().Agents["task"]
-			if taskAgentCfg.ID == "" {
-				return nil, fmt.Errorf("task agent not found in config")
-			}
-			taskAgent, err := NewAgent(ctx, taskAgentCfg, permissions, sessions, messages, history, lspClients)
-			if err != nil {
-				return nil, fmt.Errorf("failed to create task agent: %w", err)
+			newSubAgent := func(subAgentCfg config.Agent) (Service, error) {
+				return NewAgent(ctx, subAgentCfg, permissions, sessions, messages, history, todos, jobs, lspClients)
 			}
-			return NewAgentTool(taskAgent, sessions, messages), nil
+			return NewAgentTool(newSubAgent, sessions, messages, lspClients), nil
 		}
 	}
+	lspClients = allowedLSPClients(agentCfg, lspClients)
 
 	providerCfg := config.Get().GetProviderForModel(agentCfg.Model)
 	if providerCfg == nil {
@@ -134,18 +220,26 @@ func NewAgent(
 		return nil, fmt.Errorf("provider %s not ready: %w", providerCfg.ID, err)
 	}
 
-	promptID := agentPromptMap[agentCfg.ID]
-	if promptID == "" {
-		promptID = prompt.PromptDefault
+	systemPrompt, err := agentSystemPrompt(agentCfg)
+	if err != nil {
+		return nil, err
 	}
 	opts := []provider.ProviderClientOption{
 		provider.WithModel(agentCfg.Model),
-		provider.WithSystemMessage(prompt.GetPrompt(promptID, providerCfg.ID, config.Get().Options.ContextPaths...)),
+		provider.WithSystemMessage(systemPrompt(providerCfg.ID)),
 	}
 	agentProvider, err := provider.NewProvider(*providerCfg, opts...)
 	if err != nil {
 		return nil, err
 	}
+	planProvider, err := provider.NewProvider(
+		*providerCfg,
+		provider.WithModel(agentCfg.Model),
+		provider.WithSystemMessage(planSystemPrompt(providerCfg.ID)),
+	)
+	if err != nil {
+		return nil, err
+	}
 
 	smallModelCfg := cfg.Models[config.SelectedModelTypeSmall]
 	var smallModelProviderCfg *config.ProviderConfig
@@ -195,7 +289,7 @@ func NewAgent(
 
 		cwd := cfg.WorkingDir()
 		allTools := []tools.BaseTool{
-			tools.NewBashTool(permissions, cwd),
+			tools.NewBashTool(permissions, jobs, cwd),
 			tools.NewDownloadTool(permissions, cwd),
 			tools.NewKillTool(permissions, cwd),
 			tools.NewEditTool(lspClients, permissions, history, cwd),
@@ -203,37 +297,39 @@ func NewAgent(
 			tools.NewFetchTool(permissions, cwd),
 			tools.NewGlobTool(cwd),
 			tools.NewGrepTool(cwd),
+			tools.NewJobsTool(jobs),
+			tools.NewJobOutputTool(jobs),
+			tools.NewJobStopTool(jobs),
+			tools.NewJobWaitTool(jobs),
 			tools.NewLsTool(permissions, cwd),
 			tools.NewSourcegraphTool(),
+			tools.NewTodosTool(todos),
 			tools.NewViewTool(lspClients, permissions, cwd),
 			tools.NewWriteTool(lspClients, permissions, history, cwd),
 		}
+		if len(lspClients) > 0 {
+			// Refactoring writes files, so it has to be an allowed tool too.
+			allTools = append(allTools, tools.NewRefactorTool(lspClients, permissions, history, cwd))
+		}
 
 		mcpToolsOnce.Do(func() {
 			mcpTools = doGetMCPTools(ctx, permissions, cfg)
 		})
 
-		withCoderTools := func(t []tools.BaseTool) []tools.BaseTool {
-			if agentCfg.ID == "coder" && len(t) > 0 {
-				t = append(t, mcpTools...)
-				if len(lspClients) > 0 {
-					t = append(t, tools.NewDiagnosticsTool(lspClients))
-				}
+		t := filterAllowedTools(agentCfg, allTools)
+		if len(t) > 0 {
+			t = append(t, allowedMCPTools(agentCfg, mcpTools)...)
+			if len(lspClients) > 0 {
+				t = append(t,
+					tools.NewDiagnosticsTool(lspClients),
+					tools.NewDefinitionTool(lspClients, cwd),
+					tools.NewReferencesTool(lspClients, cwd),
+					tools.NewHoverTool(lspClients, cwd),
+					tools.NewSymbolsTool(lspClients, cwd),
+				)
 			}
-			return t
-		}
-
-		if agentCfg.AllowedTools == nil {
-			return withCoderTools(allTools)
 		}
-
-		var filteredTools []tools.BaseTool
-		for _, tool := range allTools {
-			if slices.Contains(agentCfg.AllowedTools, tool.Name()) {
-				filteredTools = append(filteredTools, tool)
-			}
-		}
-		return withCoderTools(filteredTools)
+		return t
 	}
 
 	return &agent{
@@ -241,8 +337,12 @@ func NewAgent(
 		agentCfg:            agentCfg,
 		provider:            agentProvider,
 		providerID:          string(providerCfg.ID),
+		fallbacks:           newFallbackModels(cfg, agentCfg.Model, systemPrompt),
+		planProvider:        planProvider,
+		planFallbacks:       newFallbackModels(cfg, agentCfg.Model, planSystemPrompt),
 		messages:            messages,
 		sessions:            sessions,
+		todos:               todos,
 		titleProvider:       titleProvider,
 		summarizeProvider:   summarizeProvider,
 		summarizeProviderID: string(providerCfg.ID),
@@ -250,6 +350,7 @@ func NewAgent(
 		activeRequests:      csync.NewMap[string, context.CancelFunc](),
 		tools:               csync.NewLazySlice(toolFn),
 		promptQueue:         csync.NewMap[string, []string](),
+		budgetExtensions:    csync.NewMap[string, int](),
 	}, nil
 }
 
@@ -419,19 +520,7 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 	if err != nil {
 		return a.err(fmt.Errorf("failed to get session: %w", err))
 	}
-	if session.SummaryMessageID != "" {
-		summaryMsgInex := -1
-		for i, msg := range msgs {
-			if msg.ID == session.SummaryMessageID {
-				summaryMsgInex = i
-				break
-			}
-		}
-		if summaryMsgInex != -1 {
-			msgs = msgs[summaryMsgInex:]
-			msgs[0].Role = message.User
-		}
-	}
+	msgs = activeHistory(session, msgs)
 
 	userMsg, err := a.createUserMessage(ctx, sessionID, content, attachmentParts)
 	if err != nil {
@@ -440,6 +529,13 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 	// Append the new user message to the conversation history.
 	msgHistory := append(msgs, userMsg)
 
+	extensions, _ := a.budgetExtensions.Get(sessionID)
+	budget := newTurnBudget(cfg.Options.Budget, extensions)
+	chain := a.modelChain(session.Mode)
+	allTools, err := a.sessionTools(session.Mode)
+	if err != nil {
+		return a.err(err)
+	}
 	for {
 		// Check for cancellation before each iteration
 		select {
@@ -448,7 +544,20 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 		default:
 			// Continue processing
 		}
-		agentMessage, toolResults, err := a.streamAndHandleEvents(ctx, sessionID, msgHistory)
+		if err := budget.check(session); err != nil {
+			return a.budgetExceeded(sessionID, err)
+		}
+		if !cfg.Options.DisableAutoSummarize && needsCompaction(session, a.Model(), compactionOptions()) {
+			history, err := a.compact(ctx, session)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return a.err(ErrRequestCancelled)
+				}
+				return a.err(fmt.Errorf("failed to compact session: %w", err))
+			}
+			msgHistory = history
+		}
+		agentMessage, toolResults, err := a.streamAndHandleEvents(ctx, sessionID, chain, allTools, msgHistory)
 		if err != nil {
 			if errors.Is(err, context.Canceled) {
 				agentMessage.AddFinish(message.FinishReasonCanceled, "Request cancelled", "")
@@ -457,6 +566,11 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 			}
 			return a.err(fmt.Errorf("failed to process events: %w", err))
 		}
+		// Pick up the usage tracked for this call.
+		if session, err = a.sessions.Get(ctx, sessionID); err != nil {
+			return a.err(fmt.Errorf("failed to get session: %w", err))
+		}
+		budget.record(session)
 		if cfg.Options.Debug {
 			slog.Info("Result", "message", agentMessage.FinishReason(), "toolResults", toolResults)
 		}
@@ -508,6 +622,33 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 	}
 }
 
+// budgetExceeded ends the run with an assistant message explaining which
+// limit was hit, so the reason is visible in the conversation.
+func (a *agent) budgetExceeded(sessionID string, err error) AgentEvent {
+	ctx := context.Background()
+	msg, createErr := a.messages.Create(ctx, sessionID, message.CreateMessageParams{
+		Role:     message.Assistant,
+		Parts:    []message.ContentPart{},
+		Model:    a.Model().ID,
+		Provider: a.providerID,
+	})
+	if createErr != nil {
+		return a.err(fmt.Errorf("failed to create assistant message: %w", createErr))
+	}
+	a.finishMessage(ctx, &msg, message.FinishReasonBudgetExceeded, "Budget exceeded", err.Error())
+	return AgentEvent{
+		Type:    AgentEventTypeError,
+		Message: msg,
+		Error:   err,
+		Done:    true,
+	}
+}
+
+func (a *agent) ExtendBudget(sessionID string) {
+	extensions, _ := a.budgetExtensions.Get(sessionID)
+	a.budgetExtensions.Set(sessionID, extensions+1)
+}
+
 func (a *agent) createUserMessage(ctx context.Context, sessionID, content string, attachmentParts []message.ContentPart) (message.Message, error) {
 	parts := []message.ContentPart{message.TextContent{Text: content}}
 	parts = append(parts, attachmentParts...)
@@ -529,177 +670,41 @@ func (a *agent) getAllTools() ([]tools.BaseTool, error) {
 	return allTools, nil
 }
 
-func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msgHistory []message.Message) (message.Message, *message.Message, error) {
+func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, chain *modelChain, allTools []tools.BaseTool, msgHistory []message.Message) (message.Message, *message.Message, error) {
 	ctx = context.WithValue(ctx, tools.SessionIDContextKey, sessionID)
 
 	// Create the assistant message first so the spinner shows immediately
 	assistantMsg, err := a.messages.Create(ctx, sessionID, message.CreateMessageParams{
 		Role:     message.Assistant,
 		Parts:    []message.ContentPart{},
-		Model:    a.Model().ID,
-		Provider: a.providerID,
+		Model:    chain.model().model.ID,
+		Provider: chain.model().providerID,
 	})
 	if err != nil {
 		return assistantMsg, nil, fmt.Errorf("failed to create assistant message: %w", err)
 	}
 
-	allTools, toolsErr := a.getAllTools()
-	if toolsErr != nil {
-		return assistantMsg, nil, toolsErr
-	}
-	// Now collect tools (which may block on MCP initialization)
-	eventChan := a.provider.StreamResponse(ctx, msgHistory, allTools)
-
 	// Add the session and message ID into the context if needed by tools.
 	ctx = context.WithValue(ctx, tools.MessageIDContextKey, assistantMsg.ID)
 
-	// Process each event in the stream.
-	for event := range eventChan {
-		if processErr := a.processEvent(ctx, sessionID, &assistantMsg, event); processErr != nil {
-			if errors.Is(processErr, context.Canceled) {
-				a.finishMessage(context.Background(), &assistantMsg, message.FinishReasonCanceled, "Request cancelled", "")
-			} else {
-				a.finishMessage(ctx, &assistantMsg, message.FinishReasonError, "API Error", processErr.Error())
-			}
-			return assistantMsg, nil, processErr
+	for {
+		streamErr := a.streamResponse(ctx, sessionID, &assistantMsg, chain.model(), msgHistory, allTools)
+		if streamErr == nil {
+			break
 		}
-		if ctx.Err() != nil {
-			a.finishMessage(context.Background(), &assistantMsg, message.FinishReasonCanceled, "Request cancelled", "")
-			return assistantMsg, nil, ctx.Err()
+		if ctx.Err() == nil && chain.next(streamErr) {
+			a.switchModel(ctx, &assistantMsg, chain.model(), streamErr)
+			continue
 		}
-	}
-
-	toolResults := make([]message.ToolResult, len(assistantMsg.ToolCalls()))
-	toolCalls := assistantMsg.ToolCalls()
-	for i, toolCall := range toolCalls {
-		select {
-		case <-ctx.Done():
+		if errors.Is(streamErr, context.Canceled) || ctx.Err() != nil {
 			a.finishMessage(context.Background(), &assistantMsg, message.FinishReasonCanceled, "Request cancelled", "")
-			// Make all future tool calls cancelled
-			for j := i; j < len(toolCalls); j++ {
-				toolResults[j] = message.ToolResult{
-					ToolCallID: toolCalls[j].ID,
-					Content:    "Tool execution canceled by user",
-					IsError:    true,
-				}
-			}
-			goto out
-		default:
-			// Continue processing
-			var tool tools.BaseTool
-			allTools, _ := a.getAllTools()
-			for _, availableTool := range allTools {
-				if availableTool.Info().Name == toolCall.Name {
-					tool = availableTool
-					break
-				}
-			}
-
-			// Tool not found
-			if tool == nil {
-				toolResults[i] = message.ToolResult{
-					ToolCallID: toolCall.ID,
-					Content:    fmt.Sprintf("Tool not found: %s", toolCall.Name),
-					IsError:    true,
-				}
-				continue
-			}
-
-			// Run tool in goroutine to allow cancellation and accept streaming deltas
-			type toolExecResult struct {
-				response tools.ToolResponse
-				err      error
-			}
-			resultChan := make(chan toolExecResult, 1)
-			progressCh := make(chan string, 128)
-
-			// Inject progress callback into context for streaming tools
-			progressCB := func(delta string) {
-				select {
-				case progressCh <- delta:
-				default:
-					// drop if congested; we will catch up with later chunks
-				}
-			}
-			ctxWithProgress := context.WithValue(ctx, tools.ProgressCallbackContextKey, progressCB)
-
-			go func() {
-				response, err := tool.Run(ctxWithProgress, tools.ToolCall{
-					ID:    toolCall.ID,
-					Name:  toolCall.Name,
-					Input: toolCall.Input,
-				})
-				resultChan <- toolExecResult{response: response, err: err}
-			}()
-
-			var toolResponse tools.ToolResponse
-			var toolErr error
-
-			for {
-				select {
-				case <-ctx.Done():
-					a.finishMessage(context.Background(), &assistantMsg, message.FinishReasonCanceled, "Request cancelled", "")
-					// Mark remaining tool calls as cancelled
-					for j := i; j < len(toolCalls); j++ {
-						toolResults[j] = message.ToolResult{
-							ToolCallID: toolCalls[j].ID,
-							Content:    "Tool execution canceled by user",
-							IsError:    true,
-						}
-					}
-					goto out
-				case delta := <-progressCh:
-					// Append incremental output to the tool call input for live preview
-					assistantMsg.AppendToolCallInput(toolCall.ID, delta)
-					_ = a.messages.Update(ctx, assistantMsg)
-				case result := <-resultChan:
-					toolResponse = result.response
-					toolErr = result.err
-					// drain remaining progress without blocking
-					for {
-						select {
-						case delta := <-progressCh:
-							assistantMsg.AppendToolCallInput(toolCall.ID, delta)
-							_ = a.messages.Update(ctx, assistantMsg)
-						default:
-							goto doneProgress
-						}
-					}
-				doneProgress:
-					// done with this tool
-					goto haveResult
-				}
-			}
-		haveResult:
-
-			if toolErr != nil {
-				slog.Error("Tool execution error", "toolCall", toolCall.ID, "error", toolErr)
-				if errors.Is(toolErr, permission.ErrorPermissionDenied) {
-					toolResults[i] = message.ToolResult{
-						ToolCallID: toolCall.ID,
-						Content:    "Permission denied",
-						IsError:    true,
-					}
-					for j := i + 1; j < len(toolCalls); j++ {
-						toolResults[j] = message.ToolResult{
-							ToolCallID: toolCalls[j].ID,
-							Content:    "Tool execution canceled by user",
-							IsError:    true,
-						}
-					}
-					a.finishMessage(ctx, &assistantMsg, message.FinishReasonPermissionDenied, "Permission denied", "")
-					break
-				}
-			}
-			toolResults[i] = message.ToolResult{
-				ToolCallID: toolCall.ID,
-				Content:    toolResponse.Content,
-				Metadata:   toolResponse.Metadata,
-				IsError:    toolResponse.IsError,
-			}
+		} else {
+			a.finishMessage(ctx, &assistantMsg, message.FinishReasonError, "API Error", streamErr.Error())
 		}
+		return assistantMsg, nil, streamErr
 	}
-out:
+
+	toolResults := a.runToolCalls(ctx, &assistantMsg, allTools)
 	if len(toolResults) == 0 {
 		return assistantMsg, nil, nil
 	}
@@ -719,12 +724,58 @@ out:
 	return assistantMsg, &msg, err
 }
 
+// streamResponse streams the response of model into assistantMsg.
+func (a *agent) streamResponse(ctx context.Context, sessionID string, assistantMsg *message.Message, model chainModel, msgHistory []message.Message, allTools []tools.BaseTool) error {
+	eventChan := model.provider.StreamResponse(ctx, msgHistory, allTools)
+	for event := range eventChan {
+		if err := a.processEvent(ctx, sessionID, assistantMsg, model.model, event); err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// switchModel discards what the failed model streamed into assistantMsg so
+// that model can answer instead, and lets the user know about the switch.
+func (a *agent) switchModel(ctx context.Context, assistantMsg *message.Message, model chainModel, cause error) {
+	from := assistantMsg.Provider + "/" + assistantMsg.Model
+	slog.Warn("Falling back to another model", "from", from, "to", model.String(), "error", cause)
+
+	assistantMsg.Parts = []message.ContentPart{}
+	assistantMsg.Usage = message.Usage{}
+	assistantMsg.Model = model.model.ID
+	assistantMsg.Provider = model.providerID
+	_ = a.messages.Update(ctx, *assistantMsg)
+
+	a.Publish(pubsub.CreatedEvent, AgentEvent{
+		Type:      AgentEventTypeFallback,
+		Message:   *assistantMsg,
+		Error:     cause,
+		SessionID: assistantMsg.SessionID,
+		Progress:  fmt.Sprintf("%s failed, switched to %s", from, model),
+	})
+}
+
+// modelChain returns the models a run of the agent in a session in mode may
+// use, starting with the primary one.
+func (a *agent) modelChain(mode session.Mode) *modelChain {
+	if mode == session.ModePlan {
+		primary := chainModel{provider: a.planProvider, providerID: a.providerID, model: a.Model()}
+		return &modelChain{models: append([]chainModel{primary}, a.planFallbacks...)}
+	}
+	primary := chainModel{provider: a.provider, providerID: a.providerID, model: a.Model()}
+	return &modelChain{models: append([]chainModel{primary}, a.fallbacks...)}
+}
+
 func (a *agent) finishMessage(ctx context.Context, msg *message.Message, finishReason message.FinishReason, message, details string) {
 	msg.AddFinish(finishReason, message, details)
 	_ = a.messages.Update(ctx, *msg)
 }
 
-func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg *message.Message, event provider.ProviderEvent) error {
+func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg *message.Message, model catwalk.Model, event provider.ProviderEvent) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -761,10 +812,12 @@ func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg
 		assistantMsg.FinishThinking()
 		assistantMsg.SetToolCalls(event.Response.ToolCalls)
 		assistantMsg.AddFinish(event.Response.FinishReason, "", "")
+		assistantMsg.SetResponseID(event.Response.ResponseID)
+		assistantMsg.Usage = messageUsage(model, event.Response.Usage)
 		if err := a.messages.Update(ctx, *assistantMsg); err != nil {
 			return fmt.Errorf("failed to update message: %w", err)
 		}
-		return a.TrackUsage(ctx, sessionID, a.Model(), event.Response.Usage)
+		return a.TrackUsage(ctx, sessionID, model, event.Response.Usage)
 	}
 
 	return nil
@@ -776,12 +829,7 @@ func (a *agent) TrackUsage(ctx context.Context, sessionID string, model catwalk.
 		return fmt.Errorf("failed to get session: %w", err)
 	}
 
-	cost := model.CostPer1MInCached/1e6*float64(usage.CacheCreationTokens) +
-		model.CostPer1MOutCached/1e6*float64(usage.CacheReadTokens) +
-		model.CostPer1MIn/1e6*float64(usage.InputTokens) +
-		model.CostPer1MOut/1e6*float64(usage.OutputTokens)
-
-	sess.Cost += cost
+	sess.Cost += usageCost(model, usage)
 	sess.CompletionTokens = usage.OutputTokens + usage.CacheReadTokens
 	sess.PromptTokens = usage.InputTokens + usage.CacheCreationTokens
 
@@ -792,6 +840,25 @@ func (a *agent) TrackUsage(ctx context.Context, sessionID string, model catwalk.
 	return nil
 }
 
+func usageCost(model catwalk.Model, usage provider.TokenUsage) float64 {
+	return model.CostPer1MInCached/1e6*float64(usage.CacheCreationTokens) +
+		model.CostPer1MOutCached/1e6*float64(usage.CacheReadTokens) +
+		model.CostPer1MIn/1e6*float64(usage.InputTokens) +
+		model.CostPer1MOut/1e6*float64(usage.OutputTokens)
+}
+
+// messageUsage converts the usage of a model call into what is stored on the
+// assistant message it produced.
+func messageUsage(model catwalk.Model, usage provider.TokenUsage) message.Usage {
+	return message.Usage{
+		InputTokens:      usage.InputTokens,
+		OutputTokens:     usage.OutputTokens,
+		CacheReadTokens:  usage.CacheReadTokens,
+		CacheWriteTokens: usage.CacheCreationTokens,
+		Cost:             usageCost(model, usage),
+	}
+}
+
 func (a *agent) Summarize(ctx context.Context, sessionID string) error {
 	if a.summarizeProvider == nil {
 		return fmt.Errorf("summarize provider not available")
@@ -895,8 +962,7 @@ func (a *agent) Summarize(ctx context.Context, sessionID string) error {
 			a.Publish(pubsub.CreatedEvent, event)
 			return
 		}
-		shell := shell.GetPersistentShell(config.Get().WorkingDir())
-		summary += "\n\n**Current working directory of the persistent shell**\n\n" + shell.GetWorkingDir()
+		summary += a.summaryContext(summarizeCtx, sessionID)
 		event = AgentEvent{
 			Type:     AgentEventTypeSummarize,
 			Progress: "Creating new session...",
@@ -926,6 +992,7 @@ func (a *agent) Summarize(ctx context.Context, sessionID string) error {
 			},
 			Model:    a.summarizeProvider.Model().ID,
 			Provider: a.summarizeProviderID,
+			Usage:    messageUsage(a.summarizeProvider.Model(), finalResponse.Usage),
 		})
 		if err != nil {
 			event = AgentEvent{
@@ -938,15 +1005,12 @@ func (a *agent) Summarize(ctx context.Context, sessionID string) error {
 			return
 		}
 		oldSession.SummaryMessageID = msg.ID
+		oldSession.KeepFromMessageID = ""
 		oldSession.CompletionTokens = finalResponse.Usage.OutputTokens
 		oldSession.PromptTokens = 0
 		model := a.summarizeProvider.Model()
 		usage := finalResponse.Usage
-		cost := model.CostPer1MInCached/1e6*float64(usage.CacheCreationTokens) +
-			model.CostPer1MOutCached/1e6*float64(usage.CacheReadTokens) +
-			model.CostPer1MIn/1e6*float64(usage.InputTokens) +
-			model.CostPer1MOut/1e6*float64(usage.OutputTokens)
-		oldSession.Cost += cost
+		oldSession.Cost += usageCost(model, usage)
 		_, err = a.sessions.Save(summarizeCtx, oldSession)
 		if err != nil {
 			event = AgentEvent{
@@ -970,6 +1034,18 @@ func (a *agent) Summarize(ctx context.Context, sessionID string) error {
 	return nil
 }
 
+// summaryContext returns what a summary of the session ends with so the
+// conversation that follows can pick up where it left off.
+func (a *agent) summaryContext(ctx context.Context, sessionID string) string {
+	shell := shell.GetPersistentShell(config.Get().WorkingDir())
+	summaryContext := "\n\n**Current working directory of the persistent shell**\n\n" + shell.GetWorkingDir()
+	// Keep the task list in view of the conversation that follows.
+	if todos, err := a.todos.Get(ctx, sessionID); err == nil && len(todos) > 0 {
+		summaryContext += "\n\n**Current task list**\n\n" + todo.Format(todos)
+	}
+	return summaryContext
+}
+
 func (a *agent) ClearQueue(sessionID string) {
 	if a.QueuedPrompts(sessionID) > 0 {
 		slog.Info("Clearing queued prompts", "session_id", sessionID)
@@ -1005,6 +1081,11 @@ func (a *agent) UpdateModel() error {
 		return fmt.Errorf("provider for agent %s not found in config", a.agentCfg.Name)
 	}
 
+	systemPrompt, err := agentSystemPrompt(a.agentCfg)
+	if err != nil {
+		return err
+	}
+
 	// Check if provider has changed
 	if string(currentProviderCfg.ID) != a.providerID {
 		// Provider changed, need to recreate the main provider
@@ -1013,14 +1094,9 @@ func (a *agent) UpdateModel() error {
 			return fmt.Errorf("model not found for agent %s", a.agentCfg.Name)
 		}
 
-		promptID := agentPromptMap[a.agentCfg.ID]
-		if promptID == "" {
-			promptID = prompt.PromptDefault
-		}
-
 		opts := []provider.ProviderClientOption{
 			provider.WithModel(a.agentCfg.Model),
-			provider.WithSystemMessage(prompt.GetPrompt(promptID, currentProviderCfg.ID, cfg.Options.ContextPaths...)),
+			provider.WithSystemMessage(systemPrompt(currentProviderCfg.ID)),
 		}
 
 		newProvider, err := provider.NewProvider(*currentProviderCfg, opts...)
@@ -1028,11 +1104,25 @@ func (a *agent) UpdateModel() error {
 			return fmt.Errorf("failed to create new provider: %w", err)
 		}
 
+		newPlanProvider, err := provider.NewProvider(
+			*currentProviderCfg,
+			provider.WithModel(a.agentCfg.Model),
+			provider.WithSystemMessage(planSystemPrompt(currentProviderCfg.ID)),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create new plan provider: %w", err)
+		}
+
 		// Update the provider and provider ID
 		a.provider = newProvider
+		a.planProvider = newPlanProvider
 		a.providerID = string(currentProviderCfg.ID)
 	}
 
+	// Fallbacks may point at the previous primary model, so always rebuild them.
+	a.fallbacks = newFallbackModels(cfg, a.agentCfg.Model, systemPrompt)
+	a.planFallbacks = newFallbackModels(cfg, a.agentCfg.Model, planSystemPrompt)
+
 	// Check if providers have changed for title (small) and summarize (large)
 	smallModelCfg := cfg.Models[config.SelectedModelTypeSmall]
 	var smallModelProviderCfg config.ProviderConfig
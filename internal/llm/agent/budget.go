@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/session"
+)
+
+// turnBudget enforces the limits of options.budget for a single run of the
+// agent.
+type turnBudget struct {
+	limits config.Budget
+	// extensions is the number of times the user allowed the session to
+	// spend another max_session_cost.
+	extensions int
+	iterations int
+	tokens     int64
+}
+
+func newTurnBudget(limits *config.Budget, extensions int) *turnBudget {
+	b := &turnBudget{extensions: extensions}
+	if limits != nil {
+		b.limits = *limits
+	}
+	return b
+}
+
+// record accounts for a model call whose usage was just tracked on sess.
+func (b *turnBudget) record(sess session.Session) {
+	b.iterations++
+	b.tokens += sess.PromptTokens + sess.CompletionTokens
+}
+
+// check returns an error wrapping ErrBudgetExceeded when another model call
+// would exceed one of the limits.
+func (b *turnBudget) check(sess session.Session) error {
+	if maxCost := b.limits.MaxSessionCost * float64(1+b.extensions); maxCost > 0 && sess.Cost >= maxCost {
+		return fmt.Errorf("%w: session cost $%.2f reached the limit of $%.2f", ErrBudgetExceeded, sess.Cost, maxCost)
+	}
+	if b.limits.MaxTurnTokens > 0 && b.tokens >= b.limits.MaxTurnTokens {
+		return fmt.Errorf("%w: %d tokens used by this prompt reached the limit of %d", ErrBudgetExceeded, b.tokens, b.limits.MaxTurnTokens)
+	}
+	if b.limits.MaxToolIterations > 0 && b.iterations >= b.limits.MaxToolIterations {
+		return fmt.Errorf("%w: %d tool iterations reached the limit of %d", ErrBudgetExceeded, b.iterations, b.limits.MaxToolIterations)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTurnBudget(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no limits", func(t *testing.T) {
+		t.Parallel()
+		b := newTurnBudget(nil, 0)
+		for range 100 {
+			b.record(session.Session{PromptTokens: 1000})
+		}
+		require.NoError(t, b.check(session.Session{Cost: 1000}))
+	})
+
+	t.Run("session cost", func(t *testing.T) {
+		t.Parallel()
+		b := newTurnBudget(&config.Budget{MaxSessionCost: 2}, 0)
+		require.NoError(t, b.check(session.Session{Cost: 1.99}))
+		require.ErrorIs(t, b.check(session.Session{Cost: 2}), ErrBudgetExceeded)
+
+		extended := newTurnBudget(&config.Budget{MaxSessionCost: 2}, 1)
+		require.NoError(t, extended.check(session.Session{Cost: 3}))
+		require.ErrorIs(t, extended.check(session.Session{Cost: 4}), ErrBudgetExceeded)
+	})
+
+	t.Run("turn tokens", func(t *testing.T) {
+		t.Parallel()
+		b := newTurnBudget(&config.Budget{MaxTurnTokens: 1000}, 0)
+		b.record(session.Session{PromptTokens: 400, CompletionTokens: 100})
+		require.NoError(t, b.check(session.Session{}))
+		b.record(session.Session{PromptTokens: 450, CompletionTokens: 50})
+		require.ErrorIs(t, b.check(session.Session{}), ErrBudgetExceeded)
+	})
+
+	t.Run("tool iterations", func(t *testing.T) {
+		t.Parallel()
+		b := newTurnBudget(&config.Budget{MaxToolIterations: 2}, 0)
+		b.record(session.Session{})
+		require.NoError(t, b.check(session.Session{}))
+		b.record(session.Session{})
+		err := b.check(session.Session{})
+		require.ErrorIs(t, err, ErrBudgetExceeded)
+		require.Contains(t, err.Error(), "2 tool iterations")
+	})
+}
@@ -0,0 +1,316 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/llm/provider"
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/session"
+)
+
+// Defaults of options.compaction.
+const (
+	defaultCompactionThreshold = 85
+	defaultCompactionKeepTurns = 4
+	defaultMaxToolOutput       = 2000
+)
+
+// truncatedNote marks tool results that were shortened by a compaction, so
+// they are not shortened again.
+const truncatedNote = "[Output truncated when the session was compacted:"
+
+const compactionPrompt = "Summarize the conversation above so the work can continue once these messages are dropped. The most recent messages are kept as they are and are not shown here. Keep the requests and constraints of the user, the decisions that were made, the exact paths of the files that were read, created or changed, the errors that are not resolved yet, and the task in progress with its next steps."
+
+// compactionOptions returns options.compaction with defaults for the values
+// that are not set.
+func compactionOptions() config.Compaction {
+	opts := config.Compaction{
+		Threshold:     defaultCompactionThreshold,
+		KeepTurns:     defaultCompactionKeepTurns,
+		MaxToolOutput: defaultMaxToolOutput,
+	}
+	cfg := config.Get()
+	if cfg == nil || cfg.Options == nil || cfg.Options.Compaction == nil {
+		return opts
+	}
+	if c := cfg.Options.Compaction; c.Threshold > 0 {
+		opts.Threshold = min(c.Threshold, 100)
+	}
+	if c := cfg.Options.Compaction; c.KeepTurns > 0 {
+		opts.KeepTurns = c.KeepTurns
+	}
+	if c := cfg.Options.Compaction; c.MaxToolOutput > 0 {
+		opts.MaxToolOutput = c.MaxToolOutput
+	}
+	return opts
+}
+
+// compactionLimit returns the number of tokens of the context window of model
+// at which a session is compacted.
+func compactionLimit(model catwalk.Model, opts config.Compaction) int64 {
+	return model.ContextWindow * int64(opts.Threshold) / 100
+}
+
+// needsCompaction reports whether the last model call of sess used enough of
+// the context window of model for the session to be compacted.
+func needsCompaction(sess session.Session, model catwalk.Model, opts config.Compaction) bool {
+	if model.ContextWindow <= 0 {
+		return false
+	}
+	return sess.PromptTokens+sess.CompletionTokens >= compactionLimit(model, opts)
+}
+
+// activeHistory returns the messages of a session that are sent to the model.
+// Once the session was summarized, these are the summary as a user message,
+// the messages kept verbatim when the session was compacted and the messages
+// that came after the summary.
+func activeHistory(sess session.Session, msgs []message.Message) []message.Message {
+	if sess.SummaryMessageID == "" {
+		return msgs
+	}
+	summaryIdx := slices.IndexFunc(msgs, func(msg message.Message) bool {
+		return msg.ID == sess.SummaryMessageID
+	})
+	if summaryIdx == -1 {
+		return msgs
+	}
+	summary := msgs[summaryIdx]
+	summary.Role = message.User
+	history := []message.Message{summary}
+	if sess.KeepFromMessageID != "" {
+		keepIdx := slices.IndexFunc(msgs[:summaryIdx], func(msg message.Message) bool {
+			return msg.ID == sess.KeepFromMessageID
+		})
+		if keepIdx != -1 {
+			history = append(history, msgs[keepIdx:summaryIdx]...)
+		}
+	}
+	return append(history, msgs[summaryIdx+1:]...)
+}
+
+// keepFrom returns the index of the first message of the last turns of
+// history, where a turn is a user message or a model response together with
+// the results of its tool calls. It returns 0 when history has no more turns
+// than that, since there is nothing to compact then.
+func keepFrom(history []message.Message, turns int) int {
+	for i := len(history) - 1; i > 0; i-- {
+		if history[i].Role == message.Tool {
+			continue
+		}
+		if turns--; turns == 0 {
+			return i
+		}
+	}
+	return 0
+}
+
+// compactionReport records what a compaction dropped from the context.
+type compactionReport struct {
+	truncatedResults   int
+	droppedChars       int
+	summarizedMessages int
+	keptTurns          int
+	// files are the paths the tool calls of the summarized messages worked
+	// on, in the order they first appear.
+	files []string
+}
+
+// String describes the report for the summary message, so the model knows
+// what it can no longer see.
+func (r compactionReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The %d messages before the last %d turns were replaced by this summary.", r.summarizedMessages, r.keptTurns)
+	if r.truncatedResults > 0 {
+		fmt.Fprintf(&b, " %d large tool results were truncated first, dropping %d characters.", r.truncatedResults, r.droppedChars)
+	}
+	if len(r.files) > 0 {
+		b.WriteString(" Files used by the summarized tool calls, read them again if you need their contents:\n")
+		for _, file := range r.files {
+			fmt.Fprintf(&b, "\n- %s", file)
+		}
+	}
+	return b.String()
+}
+
+// truncateToolResults shortens the tool results of msgs that are longer than
+// maxChars in place, recording what was dropped in report. It returns the
+// messages that changed.
+func truncateToolResults(msgs []message.Message, maxChars int, report *compactionReport) []message.Message {
+	var changed []message.Message
+	for i, msg := range msgs {
+		if msg.Role != message.Tool {
+			continue
+		}
+		parts := make([]message.ContentPart, len(msg.Parts))
+		truncated := false
+		for j, part := range msg.Parts {
+			parts[j] = part
+			result, ok := part.(message.ToolResult)
+			if !ok || len(result.Content) <= maxChars || strings.Contains(result.Content, truncatedNote) {
+				continue
+			}
+			cut := maxChars
+			for cut > 0 && !utf8.RuneStart(result.Content[cut]) {
+				cut--
+			}
+			dropped := len(result.Content) - cut
+			result.Content = fmt.Sprintf("%s\n\n%s %d characters dropped]", result.Content[:cut], truncatedNote, dropped)
+			parts[j] = result
+			report.truncatedResults++
+			report.droppedChars += dropped
+			truncated = true
+		}
+		if truncated {
+			msgs[i].Parts = parts
+			changed = append(changed, msgs[i])
+		}
+	}
+	return changed
+}
+
+// referencedFiles returns the paths passed to the tool calls of msgs, in the
+// order they first appear.
+func referencedFiles(msgs []message.Message) []string {
+	var files []string
+	for _, msg := range msgs {
+		for _, call := range msg.ToolCalls() {
+			var input struct {
+				FilePath string `json:"file_path"`
+				Path     string `json:"path"`
+			}
+			if err := json.Unmarshal([]byte(call.Input), &input); err != nil {
+				continue
+			}
+			for _, path := range []string{input.FilePath, input.Path} {
+				if path != "" && !slices.Contains(files, path) {
+					files = append(files, path)
+				}
+			}
+		}
+	}
+	return files
+}
+
+func (a *agent) publishCompaction(sessionID, progress string, done bool) {
+	a.Publish(pubsub.CreatedEvent, AgentEvent{
+		Type:      AgentEventTypeCompact,
+		SessionID: sessionID,
+		Progress:  progress,
+		Done:      done,
+	})
+}
+
+// compact frees up context in a session whose last model call reached the
+// compaction threshold. Large tool results of the older turns are truncated
+// first; when that is not enough, everything but the last turns is
+// summarized. It returns the history to continue the conversation with.
+func (a *agent) compact(ctx context.Context, sess session.Session) ([]message.Message, error) {
+	opts := compactionOptions()
+	msgs, err := a.messages.List(ctx, sess.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
+	}
+	history := activeHistory(sess, msgs)
+	keep := keepFrom(history, opts.KeepTurns)
+	if keep == 0 {
+		return history, nil
+	}
+	// Messages kept by an earlier compaction come before its summary, so they
+	// cannot stay next to a new one.
+	if summaryIdx := slices.IndexFunc(msgs, func(msg message.Message) bool {
+		return msg.ID == sess.SummaryMessageID
+	}); sess.SummaryMessageID != "" && summaryIdx != -1 {
+		keep = max(keep, len(history)-(len(msgs)-summaryIdx-1))
+	}
+	if keep >= len(history) {
+		return history, nil
+	}
+
+	report := compactionReport{}
+	for _, msg := range history[keep:] {
+		if msg.Role != message.Tool {
+			report.keptTurns++
+		}
+	}
+	for _, msg := range truncateToolResults(history[:keep], opts.MaxToolOutput, &report) {
+		if err := a.messages.Update(ctx, msg); err != nil {
+			return nil, fmt.Errorf("failed to truncate tool results: %w", err)
+		}
+	}
+	// Roughly four characters per token.
+	used := sess.PromptTokens + sess.CompletionTokens - int64(report.droppedChars/4)
+	if used < compactionLimit(a.Model(), opts) || a.summarizeProvider == nil {
+		if report.truncatedResults > 0 {
+			a.publishCompaction(sess.ID, fmt.Sprintf("Compacted the session by truncating %d large tool results", report.truncatedResults), true)
+		}
+		return history, nil
+	}
+
+	a.publishCompaction(sess.ID, "Summarizing earlier messages...", false)
+	old := history[:keep]
+	report.summarizedMessages = len(old)
+	report.files = referencedFiles(old)
+	summaryCtx := context.WithValue(ctx, tools.SessionIDContextKey, sess.ID)
+	prompt := message.Message{
+		Role:  message.User,
+		Parts: []message.ContentPart{message.TextContent{Text: compactionPrompt}},
+	}
+	var finalResponse *provider.ProviderResponse
+	for r := range a.summarizeProvider.StreamResponse(summaryCtx, append(slices.Clip(old), prompt), nil) {
+		if r.Error != nil {
+			return nil, fmt.Errorf("failed to summarize: %w", r.Error)
+		}
+		if r.Response != nil {
+			finalResponse = r.Response
+		}
+	}
+	if finalResponse == nil || strings.TrimSpace(finalResponse.Content) == "" {
+		return nil, fmt.Errorf("empty summary returned")
+	}
+
+	summary := strings.TrimSpace(finalResponse.Content) +
+		"\n\n**Compacted context**\n\n" + report.String() +
+		a.summaryContext(ctx, sess.ID)
+	msg, err := a.messages.Create(ctx, sess.ID, message.CreateMessageParams{
+		Role: message.Assistant,
+		Parts: []message.ContentPart{
+			message.TextContent{Text: summary},
+			message.Finish{
+				Reason: message.FinishReasonEndTurn,
+				Time:   time.Now().Unix(),
+			},
+		},
+		Model:    a.summarizeProvider.Model().ID,
+		Provider: a.summarizeProviderID,
+		Usage:    messageUsage(a.summarizeProvider.Model(), finalResponse.Usage),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create summary message: %w", err)
+	}
+
+	// Usage may have been tracked while summarizing.
+	if sess, err = a.sessions.Get(ctx, sess.ID); err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	sess.SummaryMessageID = msg.ID
+	sess.KeepFromMessageID = history[keep].ID
+	sess.Cost += usageCost(a.summarizeProvider.Model(), finalResponse.Usage)
+	sess.PromptTokens = 0
+	sess.CompletionTokens = finalResponse.Usage.OutputTokens
+	if sess, err = a.sessions.Save(ctx, sess); err != nil {
+		return nil, fmt.Errorf("failed to save session: %w", err)
+	}
+
+	a.publishCompaction(sess.ID, fmt.Sprintf("Compacted the session by summarizing %d earlier messages", report.summarizedMessages), true)
+	return activeHistory(sess, append(msgs, msg)), nil
+}
@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/stretchr/testify/require"
+)
+
+func testMessage(id string, role message.MessageRole, parts ...message.ContentPart) message.Message {
+	return message.Message{ID: id, Role: role, Parts: parts}
+}
+
+func messageIDs(msgs []message.Message) []string {
+	ids := make([]string, len(msgs))
+	for i, msg := range msgs {
+		ids[i] = msg.ID
+	}
+	return ids
+}
+
+func TestNeedsCompaction(t *testing.T) {
+	t.Parallel()
+
+	opts := config.Compaction{Threshold: 80}
+	model := catwalk.Model{ContextWindow: 1000}
+	require.False(t, needsCompaction(session.Session{PromptTokens: 700, CompletionTokens: 99}, model, opts))
+	require.True(t, needsCompaction(session.Session{PromptTokens: 700, CompletionTokens: 100}, model, opts))
+	require.False(t, needsCompaction(session.Session{PromptTokens: 700}, catwalk.Model{}, opts))
+}
+
+func TestActiveHistory(t *testing.T) {
+	t.Parallel()
+
+	msgs := []message.Message{
+		testMessage("1", message.User),
+		testMessage("2", message.Assistant),
+		testMessage("3", message.User),
+		testMessage("4", message.Assistant),
+		testMessage("summary", message.Assistant),
+		testMessage("5", message.User),
+	}
+
+	t.Run("not summarized", func(t *testing.T) {
+		t.Parallel()
+		history := activeHistory(session.Session{}, msgs)
+		require.Equal(t, []string{"1", "2", "3", "4", "summary", "5"}, messageIDs(history))
+	})
+
+	t.Run("summarized", func(t *testing.T) {
+		t.Parallel()
+		history := activeHistory(session.Session{SummaryMessageID: "summary"}, msgs)
+		require.Equal(t, []string{"summary", "5"}, messageIDs(history))
+		require.Equal(t, message.User, history[0].Role)
+		require.Equal(t, message.Assistant, msgs[4].Role)
+	})
+
+	t.Run("compacted", func(t *testing.T) {
+		t.Parallel()
+		history := activeHistory(session.Session{SummaryMessageID: "summary", KeepFromMessageID: "3"}, msgs)
+		require.Equal(t, []string{"summary", "3", "4", "5"}, messageIDs(history))
+	})
+}
+
+func TestKeepFrom(t *testing.T) {
+	t.Parallel()
+
+	history := []message.Message{
+		testMessage("1", message.User),
+		testMessage("2", message.Assistant),
+		testMessage("3", message.Tool),
+		testMessage("4", message.Assistant),
+		testMessage("5", message.Tool),
+		testMessage("6", message.Assistant),
+	}
+	require.Equal(t, 5, keepFrom(history, 1))
+	require.Equal(t, 3, keepFrom(history, 2))
+	require.Equal(t, 1, keepFrom(history, 3))
+	require.Zero(t, keepFrom(history, 4))
+}
+
+func TestTruncateToolResults(t *testing.T) {
+	t.Parallel()
+
+	large := strings.Repeat("a", 100)
+	msgs := []message.Message{
+		testMessage("1", message.Assistant, message.TextContent{Text: large}),
+		testMessage("2", message.Tool,
+			message.ToolResult{ToolCallID: "a", Content: "small"},
+			message.ToolResult{ToolCallID: "b", Content: large},
+		),
+		testMessage("3", message.Tool, message.ToolResult{ToolCallID: "c", Content: "small"}),
+	}
+
+	var report compactionReport
+	changed := truncateToolResults(msgs, 10, &report)
+	require.Equal(t, []string{"2"}, messageIDs(changed))
+	require.Equal(t, 1, report.truncatedResults)
+	require.Equal(t, 90, report.droppedChars)
+
+	results := msgs[1].ToolResults()
+	require.Equal(t, "small", results[0].Content)
+	require.True(t, strings.HasPrefix(results[1].Content, strings.Repeat("a", 10)+"\n\n"+truncatedNote))
+	require.Equal(t, large, msgs[0].Content().Text)
+
+	// Results are only truncated once.
+	require.Empty(t, truncateToolResults(msgs, 10, &report))
+	require.Equal(t, 1, report.truncatedResults)
+}
+
+func TestReferencedFiles(t *testing.T) {
+	t.Parallel()
+
+	msgs := []message.Message{
+		testMessage("1", message.Assistant,
+			message.ToolCall{ID: "a", Name: "view", Input: `{"file_path": "main.go"}`},
+			message.ToolCall{ID: "b", Name: "ls", Input: `{"path": "internal"}`},
+		),
+		testMessage("2", message.Assistant,
+			message.ToolCall{ID: "c", Name: "edit", Input: `{"file_path": "main.go"}`},
+			message.ToolCall{ID: "d", Name: "bash", Input: `{"command": "go test"}`},
+			message.ToolCall{ID: "e", Name: "write", Input: `{"file_path": "go.mod"`},
+		),
+	}
+	require.Equal(t, []string{"main.go", "internal"}, referencedFiles(msgs))
+}
@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"log/slog"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/llm/provider"
+)
+
+// chainModel is a model the agent can send a request to.
+type chainModel struct {
+	provider   provider.Provider
+	providerID string
+	model      catwalk.Model
+}
+
+func (m chainModel) String() string {
+	return m.providerID + "/" + m.model.ID
+}
+
+// modelChain tracks which model answers during a single run of the agent. It
+// starts at the primary model and moves to the next fallback when a model is
+// unavailable, staying there until the run ends.
+type modelChain struct {
+	models  []chainModel
+	current int
+}
+
+func (c *modelChain) model() chainModel {
+	return c.models[c.current]
+}
+
+// next switches to the next model after the current one failed with err. It
+// returns false when err is not a reason to fall back or no model is left.
+func (c *modelChain) next(err error) bool {
+	if c.current+1 >= len(c.models) || !provider.ShouldFallback(err) {
+		return false
+	}
+	c.current++
+	return true
+}
+
+// newFallbackModels creates providers for the fallbacks configured for the
+// model type. Fallbacks that are not configured are skipped.
+func newFallbackModels(cfg *config.Config, modelType config.SelectedModelType, systemPrompt func(providerID string) string) []chainModel {
+	var models []chainModel
+	for _, fallback := range cfg.Models[modelType].Fallbacks {
+		providerCfg, ok := cfg.Providers.Get(fallback.Provider)
+		if !ok || providerCfg.Disable {
+			slog.Warn("Skipping fallback model with unavailable provider", "provider", fallback.Provider, "model", fallback.Model)
+			continue
+		}
+		model := cfg.GetModel(fallback.Provider, fallback.Model)
+		if model == nil {
+			slog.Warn("Skipping unknown fallback model", "provider", fallback.Provider, "model", fallback.Model)
+			continue
+		}
+		p, err := provider.NewProvider(
+			providerCfg,
+			provider.WithModel(modelType),
+			provider.WithSelectedModel(fallback),
+			provider.WithSystemMessage(systemPrompt(providerCfg.ID)),
+		)
+		if err != nil {
+			slog.Warn("Skipping fallback model", "provider", fallback.Provider, "model", fallback.Model, "error", err)
+			continue
+		}
+		models = append(models, chainModel{provider: p, providerID: providerCfg.ID, model: *model})
+	}
+	return models
+}
@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/llm/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelChain(t *testing.T) {
+	t.Parallel()
+
+	exhausted := fmt.Errorf("%w for rate limit: 8 retries", provider.ErrMaxRetries)
+
+	t.Run("falls back in order", func(t *testing.T) {
+		t.Parallel()
+		chain := &modelChain{models: []chainModel{
+			{providerID: "anthropic"},
+			{providerID: "openai"},
+			{providerID: "ollama"},
+		}}
+		require.Equal(t, "anthropic", chain.model().providerID)
+		require.True(t, chain.next(exhausted))
+		require.Equal(t, "openai", chain.model().providerID)
+		require.True(t, chain.next(exhausted))
+		require.Equal(t, "ollama", chain.model().providerID)
+		require.False(t, chain.next(exhausted))
+		require.Equal(t, "ollama", chain.model().providerID)
+	})
+
+	t.Run("keeps the model on other errors", func(t *testing.T) {
+		t.Parallel()
+		chain := &modelChain{models: []chainModel{
+			{providerID: "anthropic"},
+			{providerID: "openai"},
+		}}
+		require.False(t, chain.next(errors.New("invalid request")))
+		require.Equal(t, "anthropic", chain.model().providerID)
+	})
+
+	t.Run("no fallbacks", func(t *testing.T) {
+		t.Parallel()
+		chain := &modelChain{models: []chainModel{{providerID: "anthropic"}}}
+		require.False(t, chain.next(exhausted))
+	})
+}
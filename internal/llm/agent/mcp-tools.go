@@ -108,6 +108,7 @@ func (b *McpTool) Info() tools.ToolInfo {
 		Description: b.tool.Description,
 		Parameters:  parameters,
 		Required:    required,
+		ReadOnly:    b.tool.Annotations.ReadOnlyHint != nil && *b.tool.Annotations.ReadOnlyHint,
 	}
 }
 
@@ -175,18 +176,19 @@ func (b *McpTool) Run(ctx context.Context, params tools.ToolCall) (tools.ToolRes
 		return tools.ToolResponse{}, fmt.Errorf("session ID and message ID are required for creating a new file")
 	}
 	permissionDescription := fmt.Sprintf("execute %s with the following parameters: %s", b.Info().Name, params.Input)
-	p := b.permissions.Request(
-		permission.CreatePermissionRequest{
-			SessionID:   sessionID,
-			ToolCallID:  params.ID,
-			Path:        b.workingDir,
-			ToolName:    b.Info().Name,
-			Action:      "execute",
-			Description: permissionDescription,
-			Params:      params.Input,
-		},
-	)
-	if !p {
+	permissionReq := permission.CreatePermissionRequest{
+		SessionID:   sessionID,
+		ToolCallID:  params.ID,
+		Path:        b.workingDir,
+		ToolName:    b.Info().Name,
+		Action:      "execute",
+		Description: permissionDescription,
+		Params:      params.Input,
+	}
+	if err := b.permissions.Check(permissionReq); err != nil {
+		return tools.NewTextErrorResponse(err.Error()), nil
+	}
+	if !b.permissions.Request(permissionReq) {
 		return tools.ToolResponse{}, permission.ErrorPermissionDenied
 	}
 
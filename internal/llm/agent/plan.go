@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"slices"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/llm/prompt"
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/session"
+)
+
+// planTools are the tools the agent may use in sessions in plan mode.
+var planTools = []string{
+	tools.ViewToolName,
+	tools.LSToolName,
+	tools.GlobToolName,
+	tools.GrepToolName,
+	tools.DiagnosticsToolName,
+	tools.DefinitionToolName,
+	tools.ReferencesToolName,
+	tools.HoverToolName,
+	tools.SymbolsToolName,
+	tools.FetchToolName,
+	tools.JobsToolName,
+	tools.JobOutputToolName,
+	tools.JobWaitToolName,
+}
+
+// planSystemPrompt builds the system prompt used in plan mode for a provider.
+func planSystemPrompt(providerID string) string {
+	return prompt.GetPrompt(prompt.PromptPlan, providerID, config.Get().Options.ContextPaths...)
+}
+
+// sessionTools returns the tools the agent may use in a session in mode.
+func (a *agent) sessionTools(mode session.Mode) ([]tools.BaseTool, error) {
+	allTools, err := a.getAllTools()
+	if err != nil || mode != session.ModePlan {
+		return allTools, err
+	}
+	return slices.DeleteFunc(allTools, func(tool tools.BaseTool) bool {
+		return !slices.Contains(planTools, tool.Name())
+	}), nil
+}
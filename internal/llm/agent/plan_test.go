@@ -0,0 +1,36 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionTools(t *testing.T) {
+	t.Parallel()
+
+	a := &agent{tools: csync.NewLazySlice(func() []tools.BaseTool {
+		var allTools []tools.BaseTool
+		for _, name := range []string{"bash", "edit", "view", "grep", "sourcegraph", "fetch"} {
+			allTools = append(allTools, &countingTool{name: name})
+		}
+		return allTools
+	})}
+
+	toolNames := func(mode session.Mode) []string {
+		allTools, err := a.sessionTools(mode)
+		require.NoError(t, err)
+		var names []string
+		for _, tool := range allTools {
+			names = append(names, tool.Name())
+		}
+		return names
+	}
+	require.Equal(t, []string{"bash", "edit", "view", "grep", "sourcegraph", "fetch"}, toolNames(session.ModeNormal))
+	require.Equal(t, []string{"view", "grep", "fetch"}, toolNames(session.ModePlan))
+	// Plan mode must not filter the tools of later runs.
+	require.Len(t, toolNames(session.ModeNormal), 6)
+}
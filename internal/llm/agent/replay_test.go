@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/job"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/todo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAgentReplay runs a prompt through the agent against recorded model
+// responses: the model reads a file with the view tool and answers.
+func TestAgentReplay(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("Hello, world!\n"), 0o644))
+
+	cassette, err := filepath.Abs(filepath.Join("testdata", "replay_view.json"))
+	require.NoError(t, err)
+	selected := map[string]string{"provider": "replay", "model": "replay-model"}
+	crushConfig, err := json.Marshal(map[string]any{
+		"options": map[string]any{"disable_provider_auto_update": true},
+		"providers": map[string]any{
+			"replay": map[string]any{
+				"type":   "replay",
+				"replay": map[string]any{"cassette": cassette},
+				"models": []map[string]any{{"id": "replay-model", "name": "Replay", "context_window": 100000, "default_max_tokens": 1000}},
+			},
+		},
+		"models": map[string]any{"large": selected, "small": selected},
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "crush.json"), crushConfig, 0o644))
+
+	cfg, err := config.Init(dir, filepath.Join(dir, ".crush"), false)
+	require.NoError(t, err)
+
+	conn, err := db.Connect(ctx, cfg.Options.DataDirectory)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	q := db.New(conn)
+	sessions := session.NewService(q)
+	messages := message.NewService(q)
+
+	agentCfg := config.Agent{
+		ID:           "replay",
+		Name:         "Replay",
+		Model:        config.SelectedModelTypeLarge,
+		AllowedTools: []string{"view"},
+	}
+	a, err := NewAgent(ctx, agentCfg, permission.NewPermissionService(dir, true, nil, nil, q), sessions, messages, history.NewService(q, conn), todo.NewService(q, conn), job.NewService(), nil)
+	require.NoError(t, err)
+
+	sess, err := sessions.Create(ctx, "replay")
+	require.NoError(t, err)
+	events, err := a.Run(ctx, sess.ID, "What does hello.txt say?")
+	require.NoError(t, err)
+	result := <-events
+	require.NoError(t, result.Error)
+	require.Equal(t, `It says "Hello, world!"`, result.Message.Content().Text)
+
+	msgs, err := messages.List(ctx, sess.ID)
+	require.NoError(t, err)
+	require.Len(t, msgs, 4)
+	require.Equal(t, message.User, msgs[0].Role)
+	require.Equal(t, message.FinishReasonToolUse, msgs[1].FinishReason())
+	require.Equal(t, "view", msgs[1].ToolCalls()[0].Name)
+	require.Contains(t, msgs[2].ToolResults()[0].Content, "1|Hello, world!")
+	require.Equal(t, message.FinishReasonEndTurn, msgs[3].FinishReason())
+	require.Equal(t, int64(100), msgs[3].Usage.CacheReadTokens)
+}
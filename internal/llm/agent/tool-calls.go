@@ -0,0 +1,199 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/permission"
+)
+
+// defaultMaxParallelTools is how many read-only tool calls run at once unless
+// configured otherwise.
+const defaultMaxParallelTools = 4
+
+func maxParallelTools() int {
+	if cfg := config.Get(); cfg != nil && cfg.Options != nil && cfg.Options.MaxParallelTools > 0 {
+		return cfg.Options.MaxParallelTools
+	}
+	return defaultMaxParallelTools
+}
+
+// toolBatchOutcome tells how running a batch of tool calls ended.
+type toolBatchOutcome int
+
+const (
+	toolBatchDone toolBatchOutcome = iota
+	toolBatchCanceled
+	toolBatchPermissionDenied
+)
+
+// runToolCalls runs the tool calls of assistantMsg with allTools and returns
+// their results in the order of the calls. Consecutive read-only calls run at once, other
+// calls one at a time. When the user cancels or denies a permission, the calls
+// that didn't run are reported as cancelled and the message is finished.
+func (a *agent) runToolCalls(ctx context.Context, assistantMsg *message.Message, allTools []tools.BaseTool) []message.ToolResult {
+	toolCalls := assistantMsg.ToolCalls()
+	toolResults := make([]message.ToolResult, len(toolCalls))
+	limit := maxParallelTools()
+
+	for start := 0; start < len(toolCalls); {
+		end := start + 1
+		if isReadOnlyTool(allTools, toolCalls[start].Name) {
+			for end < len(toolCalls) && isReadOnlyTool(allTools, toolCalls[end].Name) {
+				end++
+			}
+		}
+
+		switch a.runToolBatch(ctx, assistantMsg, allTools, toolCalls[start:end], toolResults[start:end], limit) {
+		case toolBatchCanceled:
+			a.finishMessage(context.Background(), assistantMsg, message.FinishReasonCanceled, "Request cancelled", "")
+			cancelToolCalls(toolCalls, toolResults)
+			return toolResults
+		case toolBatchPermissionDenied:
+			a.finishMessage(ctx, assistantMsg, message.FinishReasonPermissionDenied, "Permission denied", "")
+			cancelToolCalls(toolCalls, toolResults)
+			return toolResults
+		}
+		start = end
+	}
+	return toolResults
+}
+
+// runToolBatch runs toolCalls at once, at most limit at a time, and stores
+// their results in toolResults. Progress reported by the tools is appended to
+// the input of their calls for a live preview.
+func (a *agent) runToolBatch(ctx context.Context, assistantMsg *message.Message, allTools []tools.BaseTool, toolCalls []message.ToolCall, toolResults []message.ToolResult, limit int) toolBatchOutcome {
+	type toolProgress struct {
+		toolCallID string
+		delta      string
+	}
+	type toolExecResult struct {
+		index    int
+		response tools.ToolResponse
+		err      error
+	}
+	resultChan := make(chan toolExecResult, len(toolCalls))
+	progressCh := make(chan toolProgress, 128)
+	running := make(chan struct{}, limit)
+
+	pending := 0
+	for i, toolCall := range toolCalls {
+		tool := findTool(allTools, toolCall.Name)
+		if tool == nil {
+			toolResults[i] = message.ToolResult{
+				ToolCallID: toolCall.ID,
+				Content:    fmt.Sprintf("Tool not found: %s", toolCall.Name),
+				IsError:    true,
+			}
+			continue
+		}
+
+		// Inject progress callback into context for streaming tools
+		progressCB := func(delta string) {
+			select {
+			case progressCh <- toolProgress{toolCallID: toolCall.ID, delta: delta}:
+			default:
+				// drop if congested; we will catch up with later chunks
+			}
+		}
+		ctxWithProgress := context.WithValue(ctx, tools.ProgressCallbackContextKey, progressCB)
+
+		pending++
+		go func() {
+			select {
+			case running <- struct{}{}:
+				defer func() { <-running }()
+			case <-ctx.Done():
+				resultChan <- toolExecResult{index: i, err: ctx.Err()}
+				return
+			}
+			response, err := tool.Run(ctxWithProgress, tools.ToolCall{
+				ID:    toolCall.ID,
+				Name:  toolCall.Name,
+				Input: toolCall.Input,
+			})
+			resultChan <- toolExecResult{index: i, response: response, err: err}
+		}()
+	}
+
+	appendProgress := func(progress toolProgress) {
+		// Append incremental output to the tool call input for live preview
+		assistantMsg.AppendToolCallInput(progress.toolCallID, progress.delta)
+		_ = a.messages.Update(ctx, *assistantMsg)
+	}
+
+	outcome := toolBatchDone
+	for pending > 0 {
+		select {
+		case <-ctx.Done():
+			return toolBatchCanceled
+		case progress := <-progressCh:
+			appendProgress(progress)
+		case result := <-resultChan:
+			pending--
+			// drain remaining progress without blocking
+			for drained := false; !drained; {
+				select {
+				case progress := <-progressCh:
+					appendProgress(progress)
+				default:
+					drained = true
+				}
+			}
+
+			toolCall := toolCalls[result.index]
+			if result.err != nil {
+				slog.Error("Tool execution error", "toolCall", toolCall.ID, "error", result.err)
+				if errors.Is(result.err, permission.ErrorPermissionDenied) {
+					toolResults[result.index] = message.ToolResult{
+						ToolCallID: toolCall.ID,
+						Content:    "Permission denied",
+						IsError:    true,
+					}
+					outcome = toolBatchPermissionDenied
+					continue
+				}
+			}
+			toolResults[result.index] = message.ToolResult{
+				ToolCallID: toolCall.ID,
+				Content:    result.response.Content,
+				Metadata:   result.response.Metadata,
+				IsError:    result.response.IsError,
+			}
+		}
+	}
+	return outcome
+}
+
+// cancelToolCalls reports the tool calls without a result as cancelled.
+func cancelToolCalls(toolCalls []message.ToolCall, toolResults []message.ToolResult) {
+	for i, toolCall := range toolCalls {
+		if toolResults[i].ToolCallID != "" {
+			continue
+		}
+		toolResults[i] = message.ToolResult{
+			ToolCallID: toolCall.ID,
+			Content:    "Tool execution canceled by user",
+			IsError:    true,
+		}
+	}
+}
+
+func findTool(allTools []tools.BaseTool, name string) tools.BaseTool {
+	for _, tool := range allTools {
+		if tool.Info().Name == name {
+			return tool
+		}
+	}
+	return nil
+}
+
+func isReadOnlyTool(allTools []tools.BaseTool, name string) bool {
+	tool := findTool(allTools, name)
+	return tool != nil && tool.Info().ReadOnly
+}
@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/stretchr/testify/require"
+)
+
+// countingTool records how many of its calls run at once.
+type countingTool struct {
+	name       string
+	readOnly   bool
+	running    *atomic.Int32
+	maxRunning *atomic.Int32
+}
+
+func (t *countingTool) Info() tools.ToolInfo {
+	return tools.ToolInfo{Name: t.name, ReadOnly: t.readOnly}
+}
+
+func (t *countingTool) Name() string {
+	return t.name
+}
+
+func (t *countingTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+	running := t.running.Add(1)
+	defer t.running.Add(-1)
+	for {
+		peak := t.maxRunning.Load()
+		if running <= peak || t.maxRunning.CompareAndSwap(peak, running) {
+			break
+		}
+	}
+	if !t.readOnly && running != 1 {
+		return tools.NewTextErrorResponse("ran next to other tools"), nil
+	}
+	time.Sleep(20 * time.Millisecond)
+	return tools.NewTextResponse(t.name + " " + call.Input), nil
+}
+
+func TestRunToolCalls(t *testing.T) {
+	t.Parallel()
+
+	var running, maxRunning atomic.Int32
+	allTools := []tools.BaseTool{
+		&countingTool{name: "view", readOnly: true, running: &running, maxRunning: &maxRunning},
+		&countingTool{name: "edit", running: &running, maxRunning: &maxRunning},
+	}
+	a := &agent{}
+
+	calls := []string{"view", "view", "view", "view", "view", "view", "edit", "view", "missing"}
+	msg := message.Message{Role: message.Assistant}
+	var toolCalls []message.ToolCall
+	for i, name := range calls {
+		toolCalls = append(toolCalls, message.ToolCall{ID: string(rune('a' + i)), Name: name, Input: string(rune('0' + i)), Finished: true})
+	}
+	msg.SetToolCalls(toolCalls)
+
+	results := a.runToolCalls(t.Context(), &msg, allTools)
+	require.Len(t, results, len(calls))
+	for i, result := range results[:len(calls)-1] {
+		require.Equal(t, toolCalls[i].ID, result.ToolCallID)
+		require.False(t, result.IsError, result.Content)
+		require.Equal(t, calls[i]+" "+toolCalls[i].Input, result.Content)
+	}
+	require.True(t, results[len(calls)-1].IsError)
+	require.Equal(t, int32(defaultMaxParallelTools), maxRunning.Load())
+}
@@ -0,0 +1,37 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/config"
+)
+
+// AgentPrompt returns the system prompt of an agent defined in the config:
+// the contents of its prompt file, followed by the environment and the
+// project-specific context.
+func AgentPrompt(agent config.Agent) (string, error) {
+	path := expandPath(agent.Prompt)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(config.Get().WorkingDir(), path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt of agent %s: %w", agent.ID, err)
+	}
+
+	basePrompt := strings.TrimSpace(string(content))
+	if includeEnvironmentInfoForModel(agent.Model) {
+		if env := getEnvironmentInfo(); env != "" {
+			basePrompt = fmt.Sprintf("%s\n\n%s", basePrompt, env)
+		}
+	}
+
+	contextContent := getContextFromPaths(config.Get().WorkingDir(), agent.ContextPaths)
+	if contextContent != "" {
+		return fmt.Sprintf("%s\n\n# Project-Specific Context\n Make sure to follow the instructions in the context below\n%s", basePrompt, contextContent), nil
+	}
+	return basePrompt, nil
+}
@@ -0,0 +1,28 @@
+package prompt
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/charmbracelet/crush/internal/config"
+)
+
+//go:embed plan.md
+var planPrompt []byte
+
+// PlanPrompt returns the system prompt used while a session is in plan mode.
+func PlanPrompt(contextFiles ...string) string {
+	basePrompt := string(planPrompt)
+	if env := getEnvironmentInfo(); env != "" {
+		basePrompt = fmt.Sprintf("%s\n%s", basePrompt, env)
+	}
+	if lsp := lspInformation(); lsp != "" {
+		basePrompt = fmt.Sprintf("%s\n%s", basePrompt, lsp)
+	}
+
+	contextContent := getContextFromPaths(config.Get().WorkingDir(), contextFiles)
+	if contextContent != "" {
+		return fmt.Sprintf("%s\n\n# Project-Specific Context\n Make sure to follow the instructions in the context below\n%s", basePrompt, contextContent)
+	}
+	return basePrompt
+}
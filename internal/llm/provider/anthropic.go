@@ -109,19 +109,10 @@ func createAnthropicClient(opts providerClientOptions, tp AnthropicClientType) a
 }
 
 func (a *anthropicClient) convertMessages(messages []message.Message) (anthropicMessages []anthropic.MessageParam) {
-	for i, msg := range messages {
-		cache := false
-		if i > len(messages)-3 {
-			cache = true
-		}
+	for _, msg := range messages {
 		switch msg.Role {
 		case message.User:
 			content := anthropic.NewTextBlock(msg.Content().String())
-			if cache && !a.providerOptions.disableCache {
-				content.OfText.CacheControl = anthropic.CacheControlEphemeralParam{
-					Type: "ephemeral",
-				}
-			}
 			var contentBlocks []anthropic.ContentBlockParamUnion
 			contentBlocks = append(contentBlocks, content)
 			for _, binaryContent := range msg.BinaryContent() {
@@ -141,13 +132,7 @@ func (a *anthropicClient) convertMessages(messages []message.Message) (anthropic
 			}
 
 			if msg.Content().String() != "" {
-				content := anthropic.NewTextBlock(msg.Content().String())
-				if cache && !a.providerOptions.disableCache {
-					content.OfText.CacheControl = anthropic.CacheControlEphemeralParam{
-						Type: "ephemeral",
-					}
-				}
-				blocks = append(blocks, content)
+				blocks = append(blocks, anthropic.NewTextBlock(msg.Content().String()))
 			}
 
 			for _, toolCall := range msg.ToolCalls() {
@@ -168,6 +153,9 @@ func (a *anthropicClient) convertMessages(messages []message.Message) (anthropic
 			anthropicMessages = append(anthropicMessages, anthropic.NewAssistantMessage(blocks...))
 
 		case message.Tool:
+			if len(msg.ToolResults()) == 0 {
+				continue
+			}
 			results := make([]anthropic.ContentBlockParamUnion, len(msg.ToolResults()))
 			for i, toolResult := range msg.ToolResults() {
 				results[i] = anthropic.NewToolResultBlock(toolResult.ToolCallID, toolResult.Content, toolResult.IsError)
@@ -175,6 +163,17 @@ func (a *anthropicClient) convertMessages(messages []message.Message) (anthropic
 			anthropicMessages = append(anthropicMessages, anthropic.NewUserMessage(results...))
 		}
 	}
+
+	// Mark the last block of the most recent messages, so the next request
+	// reads the conversation up to them from the cache.
+	cached := a.providerOptions.cachedMessages()
+	for i := len(anthropicMessages) - 1; i >= 0 && cached > 0; i-- {
+		blocks := anthropicMessages[i].Content
+		if cacheControl := blocks[len(blocks)-1].GetCacheControl(); cacheControl != nil {
+			*cacheControl = anthropic.NewCacheControlEphemeralParam()
+			cached--
+		}
+	}
 	return anthropicMessages
 }
 
@@ -195,10 +194,8 @@ func (a *anthropicClient) convertTools(tools []tools.BaseTool) []anthropic.ToolU
 			},
 		}
 
-		if i == len(tools)-1 && !a.providerOptions.disableCache {
-			toolParam.CacheControl = anthropic.CacheControlEphemeralParam{
-				Type: "ephemeral",
-			}
+		if i == len(tools)-1 && a.providerOptions.cacheTools() {
+			toolParam.CacheControl = anthropic.NewCacheControlEphemeralParam()
 		}
 
 		anthropicTools[i] = anthropic.ToolUnionParam{OfTool: &toolParam}
@@ -223,22 +220,14 @@ func (a *anthropicClient) finishReason(reason string) message.FinishReason {
 }
 
 func (a *anthropicClient) isThinkingEnabled() bool {
-	cfg := config.Get()
-	modelConfig := cfg.Models[config.SelectedModelTypeLarge]
-	if a.providerOptions.modelType == config.SelectedModelTypeSmall {
-		modelConfig = cfg.Models[config.SelectedModelTypeSmall]
-	}
+	modelConfig := a.providerOptions.selectedModel()
 	return a.Model().CanReason && modelConfig.Think
 }
 
 func (a *anthropicClient) preparedMessages(messages []anthropic.MessageParam, tools []anthropic.ToolUnionParam) anthropic.MessageNewParams {
 	model := a.providerOptions.model(a.providerOptions.modelType)
 	var thinkingParam anthropic.ThinkingConfigParamUnion
-	cfg := config.Get()
-	modelConfig := cfg.Models[config.SelectedModelTypeLarge]
-	if a.providerOptions.modelType == config.SelectedModelTypeSmall {
-		modelConfig = cfg.Models[config.SelectedModelTypeSmall]
-	}
+	modelConfig := a.providerOptions.selectedModel()
 	temperature := anthropic.Float(0)
 
 	maxTokens := model.DefaultMaxTokens
@@ -268,12 +257,11 @@ func (a *anthropicClient) preparedMessages(messages []anthropic.MessageParam, to
 		})
 	}
 
-	systemBlocks = append(systemBlocks, anthropic.TextBlockParam{
-		Text: a.providerOptions.systemMessage,
-		CacheControl: anthropic.CacheControlEphemeralParam{
-			Type: "ephemeral",
-		},
-	})
+	system := anthropic.TextBlockParam{Text: a.providerOptions.systemMessage}
+	if a.providerOptions.cacheSystem() {
+		system.CacheControl = anthropic.NewCacheControlEphemeralParam()
+	}
+	systemBlocks = append(systemBlocks, system)
 
 	return anthropic.MessageNewParams{
 		Model:       anthropic.Model(model.ID),
@@ -489,7 +477,7 @@ func (a *anthropicClient) shouldRetry(attempts int, err error) (bool, int64, err
 	}
 
 	if attempts > maxRetries {
-		return false, 0, fmt.Errorf("maximum retry attempts reached for rate limit: %d retries", maxRetries)
+		return false, 0, fmt.Errorf("%w for rate limit: %d retries", ErrMaxRetries, maxRetries)
 	}
 
 	if apiErr.StatusCode == 401 {
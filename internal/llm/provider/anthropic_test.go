@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnthropicClientCacheBreakpoints(t *testing.T) {
+	t.Parallel()
+
+	messages := []message.Message{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "List the files"}}},
+		{Role: message.Assistant, Parts: []message.ContentPart{
+			message.ToolCall{ID: "call_1", Name: "ls", Input: "{}", Finished: true},
+		}},
+		{Role: message.Tool, Parts: []message.ContentPart{message.ToolResult{ToolCallID: "call_1", Content: "main.go"}}},
+	}
+	requestTools := []tools.BaseTool{tools.NewGlobTool(t.TempDir()), tools.NewGrepTool(t.TempDir())}
+
+	tests := []struct {
+		name  string
+		cache *config.CacheConfig
+		// cached lists the parts of the request marked for caching.
+		cached []string
+	}{
+		{"rolling by default", nil, []string{"system", "tools", "tool_use", "tool_result"}},
+		{"rolling", &config.CacheConfig{Strategy: config.CacheStrategyRolling, Messages: 1}, []string{"system", "tools", "tool_result"}},
+		{"system and tools", &config.CacheConfig{Strategy: config.CacheStrategySystemTools}, []string{"system", "tools"}},
+		{"system", &config.CacheConfig{Strategy: config.CacheStrategySystem}, []string{"system"}},
+		{"none", &config.CacheConfig{Strategy: config.CacheStrategyNone}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client := &anthropicClient{providerOptions: providerClientOptions{
+				config:        config.ProviderConfig{ID: "anthropic", Cache: tt.cache},
+				modelType:     config.SelectedModelTypeLarge,
+				selected:      &config.SelectedModel{Provider: "anthropic", Model: "test-model"},
+				systemMessage: "test",
+				model: func(config.SelectedModelType) catwalk.Model {
+					return catwalk.Model{ID: "test-model", DefaultMaxTokens: 1000}
+				},
+			}}
+			params := client.preparedMessages(client.convertMessages(messages), client.convertTools(requestTools))
+
+			var cached []string
+			for _, block := range params.System {
+				if block.CacheControl.Type != "" {
+					cached = append(cached, "system")
+				}
+			}
+			for _, tool := range params.Tools {
+				if tool.OfTool.CacheControl.Type != "" {
+					cached = append(cached, "tools")
+				}
+			}
+			for _, msg := range params.Messages {
+				for _, block := range msg.Content {
+					switch {
+					case block.OfToolUse != nil && block.OfToolUse.CacheControl.Type != "":
+						cached = append(cached, "tool_use")
+					case block.OfToolResult != nil && block.OfToolResult.CacheControl.Type != "":
+						cached = append(cached, "tool_result")
+					case block.OfText != nil && block.OfText.CacheControl.Type != "":
+						cached = append(cached, "text")
+					}
+				}
+			}
+			require.Equal(t, tt.cached, cached)
+
+			data, err := json.Marshal(params)
+			require.NoError(t, err)
+			require.Equal(t, len(tt.cached), strings.Count(string(data), `"cache_control"`))
+		})
+	}
+}
+
+func TestAnthropicClientSkipsEmptyToolMessages(t *testing.T) {
+	t.Parallel()
+
+	client := &anthropicClient{providerOptions: providerClientOptions{
+		config: config.ProviderConfig{ID: "anthropic"},
+	}}
+	messages := []message.Message{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "Hi"}}},
+		{Role: message.Tool},
+	}
+	converted := client.convertMessages(messages)
+	require.Len(t, converted, 1)
+	require.NotNil(t, converted[0].Content[0].OfText)
+}
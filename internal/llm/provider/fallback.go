@@ -0,0 +1,36 @@
+package provider
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go"
+	"google.golang.org/genai"
+)
+
+// ShouldFallback reports whether err means the provider is unavailable
+// rather than the request being wrong, so the request may be sent to another
+// model instead: retries were exhausted or the server failed.
+func ShouldFallback(err error) bool {
+	if errors.Is(err, ErrMaxRetries) {
+		return true
+	}
+	var anthropicErr *anthropic.Error
+	if errors.As(err, &anthropicErr) {
+		return isUnavailableStatus(anthropicErr.StatusCode)
+	}
+	var openaiErr *openai.Error
+	if errors.As(err, &openaiErr) {
+		return isUnavailableStatus(openaiErr.StatusCode)
+	}
+	var geminiErr genai.APIError
+	if errors.As(err, &geminiErr) {
+		return isUnavailableStatus(geminiErr.Code)
+	}
+	return false
+}
+
+func isUnavailableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
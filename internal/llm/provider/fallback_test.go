@@ -0,0 +1,39 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genai"
+)
+
+func TestShouldFallback(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"retries exhausted", fmt.Errorf("%w for rate limit: %d retries", ErrMaxRetries, maxRetries), true},
+		{"anthropic overloaded", &anthropic.Error{StatusCode: 529}, true},
+		{"anthropic bad request", &anthropic.Error{StatusCode: 400}, false},
+		{"openai server error", &openai.Error{StatusCode: 502}, true},
+		{"openai unauthorized", &openai.Error{StatusCode: 401}, false},
+		{"gemini unavailable", genai.APIError{Code: 503}, true},
+		{"gemini rate limited", fmt.Errorf("stream: %w", genai.APIError{Code: 429}), true},
+		{"canceled", context.Canceled, false},
+		{"other", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, ShouldFallback(tt.err))
+		})
+	}
+}
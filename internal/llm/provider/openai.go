@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"strconv"
 	"strings"
 	"time"
 
@@ -73,7 +74,7 @@ func (o *openaiClient) convertMessages(messages []message.Message) (openaiMessag
 	}
 
 	system := openai.SystemMessage(systemMessage)
-	if isAnthropicModel && !o.providerOptions.disableCache {
+	if isAnthropicModel && o.providerOptions.cacheSystem() {
 		systemTextBlock := openai.ChatCompletionContentPartTextParam{Text: systemMessage}
 		systemTextBlock.SetExtraFields(
 			map[string]any{
@@ -89,10 +90,7 @@ func (o *openaiClient) convertMessages(messages []message.Message) (openaiMessag
 	openaiMessages = append(openaiMessages, system)
 
 	for i, msg := range messages {
-		cache := false
-		if i > len(messages)-3 {
-			cache = true
-		}
+		cache := isAnthropicModel && i >= len(messages)-o.providerOptions.cachedMessages()
 		switch msg.Role {
 		case message.User:
 			var content []openai.ChatCompletionContentPartUnionParam
@@ -107,14 +105,14 @@ func (o *openaiClient) convertMessages(messages []message.Message) (openaiMessag
 
 				content = append(content, openai.ChatCompletionContentPartUnionParam{OfImageURL: &imageBlock})
 			}
-			if cache && !o.providerOptions.disableCache && isAnthropicModel {
+			if cache {
 				textBlock.SetExtraFields(map[string]any{
 					"cache_control": map[string]string{
 						"type": "ephemeral",
 					},
 				})
 			}
-			if hasBinaryContent || (isAnthropicModel && !o.providerOptions.disableCache) {
+			if hasBinaryContent || cache {
 				openaiMessages = append(openaiMessages, openai.UserMessage(content))
 			} else {
 				openaiMessages = append(openaiMessages, openai.UserMessage(msg.Content().String()))
@@ -153,7 +151,7 @@ func (o *openaiClient) convertMessages(messages []message.Message) (openaiMessag
 				}
 			}
 
-			if cache && !o.providerOptions.disableCache && isAnthropicModel {
+			if cache {
 				assistantMsg.SetExtraFields(map[string]any{
 					"cache_control": map[string]string{
 						"type": "ephemeral",
@@ -217,12 +215,7 @@ func (o *openaiClient) finishReason(reason string) message.FinishReason {
 
 func (o *openaiClient) preparedParams(messages []openai.ChatCompletionMessageParamUnion, tools []openai.ChatCompletionToolParam) openai.ChatCompletionNewParams {
 	model := o.providerOptions.model(o.providerOptions.modelType)
-	cfg := config.Get()
-
-	modelConfig := cfg.Models[config.SelectedModelTypeLarge]
-	if o.providerOptions.modelType == config.SelectedModelTypeSmall {
-		modelConfig = cfg.Models[config.SelectedModelTypeSmall]
-	}
+	modelConfig := o.providerOptions.selectedModel()
 
 	reasoningEffort := modelConfig.ReasoningEffort
 
@@ -570,7 +563,7 @@ func (o *openaiClient) streamWithoutStreaming(ctx context.Context, messages []me
 
 func (o *openaiClient) shouldRetry(attempts int, err error) (bool, int64, error) {
 	if attempts > maxRetries {
-		return false, 0, fmt.Errorf("maximum retry attempts reached for rate limit: %d retries", maxRetries)
+		return false, 0, fmt.Errorf("%w for rate limit: %d retries", ErrMaxRetries, maxRetries)
 	}
 	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 		return false, 0, err
@@ -641,6 +634,11 @@ func (o *openaiClient) toolCalls(completion openai.ChatCompletion) []message.Too
 
 func (o *openaiClient) usage(completion openai.ChatCompletion) TokenUsage {
 	cachedTokens := completion.Usage.PromptTokensDetails.CachedTokens
+	// DeepSeek and compatible servers report cache hits in a field of their
+	// own.
+	if hit, ok := completion.Usage.JSON.ExtraFields["prompt_cache_hit_tokens"]; ok && cachedTokens == 0 {
+		cachedTokens, _ = strconv.ParseInt(hit.Raw(), 10, 64)
+	}
 	inputTokens := completion.Usage.PromptTokens - cachedTokens
 
 	return TokenUsage{
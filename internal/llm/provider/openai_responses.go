@@ -0,0 +1,424 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/packages/param"
+	"github.com/openai/openai-go/responses"
+	"github.com/openai/openai-go/shared"
+)
+
+// openaiResponsesClient talks to OpenAI through the Responses API. It shares
+// the HTTP client and retry handling with openaiClient.
+type openaiResponsesClient struct {
+	*openaiClient
+}
+
+type OpenAIResponsesClient ProviderClient
+
+func newOpenAIResponsesClient(opts providerClientOptions) OpenAIResponsesClient {
+	return &openaiResponsesClient{
+		openaiClient: &openaiClient{
+			providerOptions: opts,
+			client:          createOpenAIClient(opts),
+		},
+	}
+}
+
+// responsesReasoning is a reasoning item of a response. The API needs the
+// items back to carry reasoning across turns, so they are stored as the
+// signature of the message's reasoning.
+type responsesReasoning struct {
+	ID               string `json:"id"`
+	EncryptedContent string `json:"encrypted_content,omitempty"`
+}
+
+// previousResponse finds the last response this provider and model gave in
+// messages. It returns its ID and the index of the first message after it,
+// or an empty ID when the conversation can't be continued from a previous
+// response.
+func (o *openaiResponsesClient) previousResponse(messages []message.Message) (string, int) {
+	// Responses that are not stored can't be continued from.
+	if store, ok := o.providerOptions.extraBody["store"].(bool); ok && !store {
+		return "", 0
+	}
+	model := o.Model()
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg := messages[i]
+		if msg.Role != message.Assistant || msg.ResponseID() == "" {
+			continue
+		}
+		// Once a session is compacted, its history starts with the summary,
+		// followed by the messages kept from before it. Responses to those
+		// carry the conversation the summary replaced.
+		if msg.CreatedAt < messages[0].CreatedAt {
+			return "", 0
+		}
+		if msg.Provider != o.providerOptions.config.ID || msg.Model != model.ID {
+			return "", 0
+		}
+		return msg.ResponseID(), i + 1
+	}
+	return "", 0
+}
+
+func (o *openaiResponsesClient) convertMessages(messages []message.Message) responses.ResponseInputParam {
+	var input responses.ResponseInputParam
+	for _, msg := range messages {
+		switch msg.Role {
+		case message.User:
+			content := responses.ResponseInputMessageContentListParam{
+				responses.ResponseInputContentParamOfInputText(msg.Content().String()),
+			}
+			for _, binaryContent := range msg.BinaryContent() {
+				content = append(content, responses.ResponseInputContentUnionParam{
+					OfInputImage: &responses.ResponseInputImageParam{
+						ImageURL: param.NewOpt(binaryContent.String(catwalk.InferenceProviderOpenAI)),
+						Detail:   responses.ResponseInputImageDetailAuto,
+					},
+				})
+			}
+			input = append(input, responses.ResponseInputItemParamOfMessage(content, responses.EasyInputMessageRoleUser))
+
+		case message.Assistant:
+			// Reasoning of other providers can't be passed back.
+			if msg.Provider == o.providerOptions.config.ID {
+				input = append(input, o.reasoningItems(msg.ReasoningContent())...)
+			}
+			if text := msg.Content().String(); text != "" {
+				input = append(input, responses.ResponseInputItemParamOfMessage(text, responses.EasyInputMessageRoleAssistant))
+			}
+			// Only include finished tool calls; interrupted tool calls must not be resent.
+			for _, call := range msg.ToolCalls() {
+				if call.Finished {
+					input = append(input, responses.ResponseInputItemParamOfFunctionCall(call.Input, call.ID, call.Name))
+				}
+			}
+
+		case message.Tool:
+			for _, result := range msg.ToolResults() {
+				input = append(input, responses.ResponseInputItemParamOfFunctionCallOutput(result.ToolCallID, result.Content))
+			}
+		}
+	}
+	return input
+}
+
+func (o *openaiResponsesClient) reasoningItems(reasoning message.ReasoningContent) []responses.ResponseInputItemUnionParam {
+	if reasoning.Signature == "" {
+		return nil
+	}
+	var items []responsesReasoning
+	if err := json.Unmarshal([]byte(reasoning.Signature), &items); err != nil {
+		slog.Debug("Skipping reasoning without response items", "error", err)
+		return nil
+	}
+	input := make([]responses.ResponseInputItemUnionParam, 0, len(items))
+	for _, item := range items {
+		reasoningItem := responses.ResponseReasoningItemParam{
+			ID:      item.ID,
+			Summary: []responses.ResponseReasoningItemSummaryParam{},
+		}
+		if item.EncryptedContent != "" {
+			reasoningItem.EncryptedContent = param.NewOpt(item.EncryptedContent)
+		}
+		input = append(input, responses.ResponseInputItemUnionParam{OfReasoning: &reasoningItem})
+	}
+	return input
+}
+
+func (o *openaiResponsesClient) convertTools(tools []tools.BaseTool) []responses.ToolUnionParam {
+	responsesTools := make([]responses.ToolUnionParam, len(tools))
+	for i, tool := range tools {
+		info := tool.Info()
+		responsesTools[i] = responses.ToolUnionParam{
+			OfFunction: &responses.FunctionToolParam{
+				Name:        info.Name,
+				Description: openai.String(info.Description),
+				Parameters: map[string]any{
+					"type":       "object",
+					"properties": info.Parameters,
+					"required":   info.Required,
+				},
+				// Tool schemas don't follow the rules of strict mode.
+				Strict: openai.Bool(false),
+			},
+		}
+	}
+	return responsesTools
+}
+
+// preparedParams builds the request for messages. When chain is set and a
+// previous response can be continued from, only the messages after it are
+// sent.
+func (o *openaiResponsesClient) preparedParams(messages []message.Message, tools []tools.BaseTool, chain bool) responses.ResponseNewParams {
+	model := o.Model()
+	modelConfig := o.providerOptions.selectedModel()
+
+	systemMessage := o.providerOptions.systemMessage
+	if o.providerOptions.systemPromptPrefix != "" {
+		systemMessage = o.providerOptions.systemPromptPrefix + "\n" + systemMessage
+	}
+
+	params := responses.ResponseNewParams{
+		Model:        shared.ResponsesModel(model.ID),
+		Instructions: openai.String(systemMessage),
+		Tools:        o.convertTools(tools),
+	}
+
+	if previousID, start := o.previousResponse(messages); chain && previousID != "" {
+		params.PreviousResponseID = openai.String(previousID)
+		messages = messages[start:]
+	}
+	params.Input = responses.ResponseNewParamsInputUnion{OfInputItemList: o.convertMessages(messages)}
+
+	maxTokens := model.DefaultMaxTokens
+	if modelConfig.MaxTokens > 0 {
+		maxTokens = modelConfig.MaxTokens
+	}
+	// Override max tokens if set in provider options
+	if o.providerOptions.maxTokens > 0 {
+		maxTokens = o.providerOptions.maxTokens
+	}
+	params.MaxOutputTokens = openai.Int(maxTokens)
+
+	if model.CanReason {
+		reasoningEffort := modelConfig.ReasoningEffort
+		if reasoningEffort == "" {
+			reasoningEffort = model.DefaultReasoningEffort
+		}
+		params.Reasoning = shared.ReasoningParam{
+			Effort:  shared.ReasoningEffort(reasoningEffort),
+			Summary: shared.ReasoningSummaryAuto,
+		}
+		params.Include = []responses.ResponseIncludable{responses.ResponseIncludableReasoningEncryptedContent}
+	}
+
+	return params
+}
+
+// isPreviousResponseError reports whether a request failed because the
+// previous response can't be continued from, e.g. because it expired.
+func isPreviousResponseError(err error) bool {
+	var apiErr *openai.Error
+	return errors.As(err, &apiErr) && apiErr.Param == "previous_response_id"
+}
+
+func (o *openaiResponsesClient) send(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (*ProviderResponse, error) {
+	params := o.preparedParams(messages, tools, true)
+	attempts := 0
+	for {
+		attempts++
+		resp, err := o.client.Responses.New(ctx, params)
+		if err != nil {
+			if params.PreviousResponseID.Valid() && isPreviousResponseError(err) {
+				slog.Warn("Can't continue from previous response, sending the whole conversation", "error", err)
+				params = o.preparedParams(messages, tools, false)
+				continue
+			}
+			retry, after, retryErr := o.shouldRetry(attempts, err)
+			if retryErr != nil {
+				return nil, retryErr
+			}
+			if retry {
+				slog.Warn("Retrying due to rate limit", "attempt", attempts, "max_retries", maxRetries, "error", err)
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(time.Duration(after) * time.Millisecond):
+					continue
+				}
+			}
+			return nil, retryErr
+		}
+		if resp.Status == responses.ResponseStatusFailed {
+			return nil, fmt.Errorf("response failed: %s", resp.Error.Message)
+		}
+		return o.providerResponse(*resp), nil
+	}
+}
+
+func (o *openaiResponsesClient) stream(ctx context.Context, messages []message.Message, tools []tools.BaseTool) <-chan ProviderEvent {
+	params := o.preparedParams(messages, tools, true)
+	attempts := 0
+	eventChan := make(chan ProviderEvent)
+
+	go func() {
+		defer close(eventChan)
+		for {
+			attempts++
+			stream := o.client.Responses.NewStreaming(ctx, params)
+
+			var final *responses.Response
+			var streamErr error
+			// function call item IDs to tool call IDs
+			callIDs := make(map[string]string)
+			for stream.Next() {
+				event := stream.Current()
+				switch event.Type {
+				case "response.output_text.delta":
+					eventChan <- ProviderEvent{Type: EventContentDelta, Content: event.Delta.OfString}
+				case "response.reasoning_summary_part.added":
+					if event.SummaryIndex > 0 {
+						eventChan <- ProviderEvent{Type: EventThinkingDelta, Thinking: "\n\n"}
+					}
+				case "response.reasoning_summary_text.delta":
+					eventChan <- ProviderEvent{Type: EventThinkingDelta, Thinking: event.Delta.OfString}
+				case "response.output_item.added":
+					if event.Item.Type == "function_call" {
+						callIDs[event.Item.ID] = event.Item.CallID
+						eventChan <- ProviderEvent{
+							Type: EventToolUseStart,
+							ToolCall: &message.ToolCall{
+								ID:       event.Item.CallID,
+								Name:     event.Item.Name,
+								Finished: false,
+							},
+						}
+					}
+				case "response.function_call_arguments.delta":
+					if callID, ok := callIDs[event.ItemID]; ok {
+						eventChan <- ProviderEvent{
+							Type: EventToolUseDelta,
+							ToolCall: &message.ToolCall{
+								ID:       callID,
+								Finished: false,
+								Input:    event.Delta.OfString,
+							},
+						}
+					}
+				case "response.output_item.done":
+					if event.Item.Type == "function_call" {
+						eventChan <- ProviderEvent{
+							Type:     EventToolUseStop,
+							ToolCall: &message.ToolCall{ID: event.Item.CallID},
+						}
+					}
+				case "response.completed", "response.incomplete":
+					final = &event.Response
+				case "response.failed":
+					streamErr = fmt.Errorf("response failed: %s", event.Response.Error.Message)
+				case "error":
+					streamErr = fmt.Errorf("response error %s: %s", event.Code, event.Message)
+				}
+			}
+
+			err := stream.Err()
+			if err == nil || errors.Is(err, io.EOF) {
+				switch {
+				case streamErr != nil:
+					eventChan <- ProviderEvent{Type: EventError, Error: streamErr}
+				case final == nil:
+					eventChan <- ProviderEvent{
+						Type:  EventError,
+						Error: fmt.Errorf("received empty streaming response from OpenAI API - check endpoint configuration"),
+					}
+				default:
+					if signature := responsesSignature(*final); signature != "" {
+						eventChan <- ProviderEvent{Type: EventSignatureDelta, Signature: signature}
+					}
+					eventChan <- ProviderEvent{Type: EventComplete, Response: o.providerResponse(*final)}
+				}
+				return
+			}
+
+			if params.PreviousResponseID.Valid() && isPreviousResponseError(err) {
+				slog.Warn("Can't continue from previous response, sending the whole conversation", "error", err)
+				params = o.preparedParams(messages, tools, false)
+				continue
+			}
+
+			// If there is an error we are going to see if we can retry the call
+			retry, after, retryErr := o.shouldRetry(attempts, err)
+			if retryErr != nil {
+				eventChan <- ProviderEvent{Type: EventError, Error: retryErr}
+				return
+			}
+			if retry {
+				slog.Warn("Retrying due to rate limit", "attempt", attempts, "max_retries", maxRetries, "error", err)
+				select {
+				case <-ctx.Done():
+					// context cancelled
+					if ctx.Err() != nil {
+						eventChan <- ProviderEvent{Type: EventError, Error: ctx.Err()}
+					}
+					return
+				case <-time.After(time.Duration(after) * time.Millisecond):
+					continue
+				}
+			}
+			eventChan <- ProviderEvent{Type: EventError, Error: retryErr}
+			return
+		}
+	}()
+
+	return eventChan
+}
+
+// responsesSignature encodes the reasoning items of a response so they can
+// be sent back on the next turn.
+func responsesSignature(resp responses.Response) string {
+	var items []responsesReasoning
+	for _, item := range resp.Output {
+		if item.Type == "reasoning" {
+			items = append(items, responsesReasoning{ID: item.ID, EncryptedContent: item.EncryptedContent})
+		}
+	}
+	if len(items) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func (o *openaiResponsesClient) providerResponse(resp responses.Response) *ProviderResponse {
+	var toolCalls []message.ToolCall
+	for _, item := range resp.Output {
+		if item.Type != "function_call" {
+			continue
+		}
+		toolCalls = append(toolCalls, message.ToolCall{
+			ID:       item.CallID,
+			Name:     item.Name,
+			Input:    item.Arguments,
+			Type:     "function",
+			Finished: true,
+		})
+	}
+
+	finishReason := message.FinishReasonEndTurn
+	switch {
+	case len(toolCalls) > 0:
+		finishReason = message.FinishReasonToolUse
+	case resp.Status == responses.ResponseStatusIncomplete && resp.IncompleteDetails.Reason == "max_output_tokens":
+		finishReason = message.FinishReasonMaxTokens
+	case resp.Status == responses.ResponseStatusIncomplete:
+		finishReason = message.FinishReasonUnknown
+	}
+
+	cachedTokens := resp.Usage.InputTokensDetails.CachedTokens
+	return &ProviderResponse{
+		Content:   resp.OutputText(),
+		ToolCalls: toolCalls,
+		Usage: TokenUsage{
+			InputTokens:     resp.Usage.InputTokens - cachedTokens,
+			OutputTokens:    resp.Usage.OutputTokens,
+			CacheReadTokens: cachedTokens,
+		},
+		FinishReason: finishReason,
+		ResponseID:   resp.ID,
+	}
+}
@@ -0,0 +1,197 @@
+package provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestResponsesClient(baseURL string) *openaiResponsesClient {
+	return &openaiResponsesClient{
+		openaiClient: &openaiClient{
+			providerOptions: providerClientOptions{
+				config:        config.ProviderConfig{ID: "openai"},
+				modelType:     config.SelectedModelTypeLarge,
+				selected:      &config.SelectedModel{Provider: "openai", Model: "test-model"},
+				systemMessage: "test",
+				model: func(config.SelectedModelType) catwalk.Model {
+					return catwalk.Model{ID: "test-model", CanReason: true, DefaultMaxTokens: 1000}
+				},
+			},
+			client: openai.NewClient(
+				option.WithAPIKey("test-key"),
+				option.WithBaseURL(baseURL),
+			),
+		},
+	}
+}
+
+func TestOpenAIResponsesClientStream(t *testing.T) {
+	t.Parallel()
+
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		events := []map[string]any{
+			{"type": "response.reasoning_summary_part.added", "summary_index": 0},
+			{"type": "response.reasoning_summary_text.delta", "delta": "Thinking"},
+			{"type": "response.output_text.delta", "delta": "Hello"},
+			{"type": "response.completed", "response": map[string]any{
+				"id":     "resp_2",
+				"status": "completed",
+				"output": []any{
+					map[string]any{"type": "reasoning", "id": "rs_2", "encrypted_content": "secret", "summary": []any{}},
+					map[string]any{"type": "message", "id": "msg_2", "role": "assistant", "content": []any{
+						map[string]any{"type": "output_text", "text": "Hello"},
+					}},
+				},
+				"usage": map[string]any{
+					"input_tokens":          100,
+					"input_tokens_details":  map[string]any{"cached_tokens": 40},
+					"output_tokens":         10,
+					"output_tokens_details": map[string]any{"reasoning_tokens": 5},
+				},
+			}},
+		}
+		for _, event := range events {
+			data, _ := json.Marshal(event)
+			w.Write([]byte("event: " + event["type"].(string) + "\ndata: " + string(data) + "\n\n"))
+		}
+	}))
+	defer server.Close()
+
+	client := newTestResponsesClient(server.URL)
+	messages := []message.Message{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "Hi"}}},
+		{
+			Role:     message.Assistant,
+			Provider: "openai",
+			Model:    "test-model",
+			Parts: []message.ContentPart{
+				message.ReasoningContent{Thinking: "Hmm", Signature: `[{"id":"rs_1","encrypted_content":"first"}]`},
+				message.TextContent{Text: "Hey"},
+				message.Finish{Reason: message.FinishReasonEndTurn, ResponseID: "resp_1"},
+			},
+		},
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "Again"}}},
+	}
+
+	var thinking, content, signature string
+	var response *ProviderResponse
+	for event := range client.stream(t.Context(), messages, nil) {
+		switch event.Type {
+		case EventThinkingDelta:
+			thinking += event.Thinking
+		case EventContentDelta:
+			content += event.Content
+		case EventSignatureDelta:
+			signature += event.Signature
+		case EventComplete:
+			response = event.Response
+		case EventError:
+			t.Fatal(event.Error)
+		}
+	}
+
+	require.Equal(t, "resp_1", request["previous_response_id"])
+	require.Len(t, request["input"], 1)
+	require.Equal(t, []any{"reasoning.encrypted_content"}, request["include"])
+
+	require.Equal(t, "Thinking", thinking)
+	require.Equal(t, "Hello", content)
+	require.JSONEq(t, `[{"id":"rs_2","encrypted_content":"secret"}]`, signature)
+	require.NotNil(t, response)
+	require.Equal(t, "resp_2", response.ResponseID)
+	require.Equal(t, "Hello", response.Content)
+	require.Equal(t, message.FinishReasonEndTurn, response.FinishReason)
+	require.Equal(t, int64(60), response.Usage.InputTokens)
+	require.Equal(t, int64(40), response.Usage.CacheReadTokens)
+}
+
+func TestOpenAIResponsesClientPreparedParams(t *testing.T) {
+	t.Parallel()
+
+	client := newTestResponsesClient("http://localhost")
+	messages := []message.Message{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "Hi"}}},
+		{
+			Role:     message.Assistant,
+			Provider: "openai",
+			Model:    "test-model",
+			Parts: []message.ContentPart{
+				message.ReasoningContent{Signature: `[{"id":"rs_1","encrypted_content":"first"}]`},
+				message.ToolCall{ID: "call_1", Name: "ls", Input: "{}", Finished: true},
+				message.Finish{Reason: message.FinishReasonToolUse, ResponseID: "resp_1"},
+			},
+		},
+		{Role: message.Tool, Parts: []message.ContentPart{message.ToolResult{ToolCallID: "call_1", Content: "main.go"}}},
+	}
+
+	t.Run("continues from the previous response", func(t *testing.T) {
+		t.Parallel()
+		params := client.preparedParams(messages, nil, true)
+		require.Equal(t, "resp_1", params.PreviousResponseID.Value)
+		require.Len(t, params.Input.OfInputItemList, 1)
+		require.NotNil(t, params.Input.OfInputItemList[0].OfFunctionCallOutput)
+	})
+
+	t.Run("sends the whole conversation", func(t *testing.T) {
+		t.Parallel()
+		params := client.preparedParams(messages, nil, false)
+		require.False(t, params.PreviousResponseID.Valid())
+		input := params.Input.OfInputItemList
+		require.Len(t, input, 4)
+		require.NotNil(t, input[1].OfReasoning)
+		require.Equal(t, "rs_1", input[1].OfReasoning.ID)
+		require.Equal(t, "first", input[1].OfReasoning.EncryptedContent.Value)
+		require.NotNil(t, input[2].OfFunctionCall)
+		require.NotNil(t, input[3].OfFunctionCallOutput)
+	})
+
+	t.Run("does not continue from other models", func(t *testing.T) {
+		t.Parallel()
+		other := append([]message.Message(nil), messages...)
+		other[1].Model = "other-model"
+		params := client.preparedParams(other, nil, true)
+		require.False(t, params.PreviousResponseID.Valid())
+		require.Len(t, params.Input.OfInputItemList, 4)
+	})
+
+	t.Run("does not continue from before a summary", func(t *testing.T) {
+		t.Parallel()
+		summary := message.Message{
+			Role:      message.User,
+			CreatedAt: 20,
+			Parts:     []message.ContentPart{message.TextContent{Text: "Summary"}},
+		}
+		compacted := []message.Message{summary}
+		for _, msg := range messages {
+			msg.CreatedAt = 10
+			compacted = append(compacted, msg)
+		}
+		params := client.preparedParams(compacted, nil, true)
+		require.False(t, params.PreviousResponseID.Valid())
+		require.Len(t, params.Input.OfInputItemList, 5)
+
+		// Responses after the summary were given the compacted history.
+		next := message.Message{
+			Role:      message.Assistant,
+			Provider:  "openai",
+			Model:     "test-model",
+			CreatedAt: 30,
+			Parts:     []message.ContentPart{message.Finish{Reason: message.FinishReasonEndTurn, ResponseID: "resp_2"}},
+		}
+		params = client.preparedParams(append(compacted, next), nil, true)
+		require.Equal(t, "resp_2", params.PreviousResponseID.Value)
+	})
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/charmbracelet/crush/internal/message"
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMain(m *testing.M) {
@@ -88,3 +89,24 @@ func TestOpenAIClientStreamChoices(t *testing.T) {
 		}
 	}
 }
+
+func TestOpenAIClientUsage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		usage string
+	}{
+		{"openai", `{"prompt_tokens": 100, "completion_tokens": 10, "prompt_tokens_details": {"cached_tokens": 60}}`},
+		{"deepseek", `{"prompt_tokens": 100, "completion_tokens": 10, "prompt_cache_hit_tokens": 60, "prompt_cache_miss_tokens": 40}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var completion openai.ChatCompletion
+			require.NoError(t, json.Unmarshal([]byte(`{"usage": `+tt.usage+`}`), &completion))
+			usage := (&openaiClient{}).usage(completion)
+			require.Equal(t, TokenUsage{InputTokens: 40, OutputTokens: 10, CacheReadTokens: 60}, usage)
+		})
+	}
+}
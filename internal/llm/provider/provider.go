@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/charmbracelet/catwalk/pkg/catwalk"
@@ -15,6 +16,10 @@ type EventType string
 
 const maxRetries = 8
 
+// ErrMaxRetries is returned when a request still fails after maxRetries
+// retries.
+var ErrMaxRetries = errors.New("maximum retry attempts reached")
+
 const (
 	EventContentStart   EventType = "content_start"
 	EventToolUseStart   EventType = "tool_use_start"
@@ -30,17 +35,20 @@ const (
 )
 
 type TokenUsage struct {
-	InputTokens         int64
-	OutputTokens        int64
-	CacheCreationTokens int64
-	CacheReadTokens     int64
+	InputTokens         int64 `json:"input_tokens"`
+	OutputTokens        int64 `json:"output_tokens"`
+	CacheCreationTokens int64 `json:"cache_creation_tokens,omitempty"`
+	CacheReadTokens     int64 `json:"cache_read_tokens,omitempty"`
 }
 
 type ProviderResponse struct {
-	Content      string
-	ToolCalls    []message.ToolCall
-	Usage        TokenUsage
-	FinishReason message.FinishReason
+	Content      string               `json:"content,omitempty"`
+	ToolCalls    []message.ToolCall   `json:"tool_calls,omitempty"`
+	Usage        TokenUsage           `json:"usage"`
+	FinishReason message.FinishReason `json:"finish_reason"`
+	// ResponseID is set by providers that can continue from a previous
+	// response.
+	ResponseID string `json:"response_id,omitempty"`
 }
 
 type ProviderEvent struct {
@@ -66,6 +74,7 @@ type providerClientOptions struct {
 	config             config.ProviderConfig
 	apiKey             string
 	modelType          config.SelectedModelType
+	selected           *config.SelectedModel
 	model              func(config.SelectedModelType) catwalk.Model
 	disableCache       bool
 	disableStream      bool
@@ -77,6 +86,59 @@ type providerClientOptions struct {
 	extraParams        map[string]string
 }
 
+// selectedModel returns the user's settings for the model the client talks
+// to.
+func (o providerClientOptions) selectedModel() config.SelectedModel {
+	if o.selected != nil {
+		return *o.selected
+	}
+	return config.Get().Models[o.modelType]
+}
+
+// maxCachedMessages is how many messages can be cache breakpoints next to
+// the system prompt and the tools; Anthropic allows four breakpoints in all.
+const maxCachedMessages = 2
+
+// cacheStrategy returns which parts of a request the client marks for prompt
+// caching.
+func (o providerClientOptions) cacheStrategy() config.CacheStrategy {
+	switch {
+	case o.disableCache:
+		return config.CacheStrategyNone
+	case o.config.Cache == nil || o.config.Cache.Strategy == "":
+		return config.CacheStrategyRolling
+	}
+	return o.config.Cache.Strategy
+}
+
+func (o providerClientOptions) cacheSystem() bool {
+	switch o.cacheStrategy() {
+	case config.CacheStrategySystem, config.CacheStrategySystemTools, config.CacheStrategyRolling:
+		return true
+	}
+	return false
+}
+
+func (o providerClientOptions) cacheTools() bool {
+	switch o.cacheStrategy() {
+	case config.CacheStrategySystemTools, config.CacheStrategyRolling:
+		return true
+	}
+	return false
+}
+
+// cachedMessages returns how many of the most recent messages are marked for
+// caching.
+func (o providerClientOptions) cachedMessages() int {
+	if o.cacheStrategy() != config.CacheStrategyRolling {
+		return 0
+	}
+	if o.config.Cache != nil && o.config.Cache.Messages > 0 {
+		return min(o.config.Cache.Messages, maxCachedMessages)
+	}
+	return maxCachedMessages
+}
+
 type ProviderClientOption func(*providerClientOptions)
 
 type ProviderClient interface {
@@ -140,6 +202,17 @@ func WithModel(model config.SelectedModelType) ProviderClientOption {
 	}
 }
 
+// WithSelectedModel makes the client talk to the given model instead of the
+// one selected for its model type, e.g. to fall back to another model.
+func WithSelectedModel(model config.SelectedModel) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		options.selected = &model
+		options.model = func(config.SelectedModelType) catwalk.Model {
+			return *config.Get().GetModel(model.Provider, model.Model)
+		}
+	}
+}
+
 func WithDisableCache(disableCache bool) ProviderClientOption {
 	return func(options *providerClientOptions) {
 		options.disableCache = disableCache
@@ -203,6 +276,20 @@ func NewProvider(cfg config.ProviderConfig, opts ...ProviderClientOption) (Provi
 			options: clientOptions,
 			client:  newOpenAIClient(clientOptions),
 		}, nil
+	case config.TypeOpenAIResponses:
+		return &baseProvider[OpenAIResponsesClient]{
+			options: clientOptions,
+			client:  newOpenAIResponsesClient(clientOptions),
+		}, nil
+	case config.TypeReplay:
+		client, err := newReplayClient(clientOptions, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &baseProvider[ReplayClient]{
+			options: clientOptions,
+			client:  client,
+		}, nil
 	case catwalk.TypeGemini:
 		return &baseProvider[GeminiClient]{
 			options: clientOptions,
@@ -0,0 +1,341 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/message"
+)
+
+// ErrNotRecorded is returned when a replay provider has no recorded response
+// for a request.
+var ErrNotRecorded = errors.New("no recorded response for request")
+
+// replayClient records what another provider responds to a cassette, or
+// replays the recorded responses without calling any API.
+type replayClient struct {
+	providerOptions providerClientOptions
+	mode            config.ReplayMode
+	cassette        *cassette
+	// upstream is the provider responses are recorded from.
+	upstream Provider
+}
+
+type ReplayClient ProviderClient
+
+func newReplayClient(opts providerClientOptions, clientOpts []ProviderClientOption) (ReplayClient, error) {
+	replay := opts.config.Replay
+	if replay == nil || replay.Cassette == "" {
+		return nil, fmt.Errorf("replay provider %s has no cassette configured", opts.config.ID)
+	}
+	path := replay.Cassette
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(config.Get().WorkingDir(), path)
+	}
+
+	client := &replayClient{providerOptions: opts, mode: replay.Mode}
+	if client.mode != config.ReplayModeRecord {
+		client.mode = config.ReplayModeReplay
+	}
+	var err error
+	client.cassette, err = openCassette(path, client.mode == config.ReplayModeRecord)
+	if err != nil {
+		return nil, err
+	}
+
+	if client.mode == config.ReplayModeRecord {
+		upstreamCfg, ok := config.Get().Providers.Get(replay.Provider)
+		if !ok {
+			return nil, fmt.Errorf("provider %s to record from not found", replay.Provider)
+		}
+		model := opts.model(opts.modelType)
+		if config.Get().GetModel(upstreamCfg.ID, model.ID) == nil {
+			return nil, fmt.Errorf("model %s not found in provider %s", model.ID, upstreamCfg.ID)
+		}
+		selected := opts.selectedModel()
+		selected.Provider = upstreamCfg.ID
+		selected.Model = model.ID
+		upstreamOpts := append(clientOpts[:len(clientOpts):len(clientOpts)], WithSelectedModel(selected))
+		client.upstream, err = NewProvider(upstreamCfg, upstreamOpts...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+func (r *replayClient) send(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (*ProviderResponse, error) {
+	key := requestKey("send", messages, tools)
+	if r.mode == config.ReplayModeRecord {
+		resp, err := r.upstream.SendMessages(ctx, messages, tools)
+		if ctx.Err() == nil {
+			event := ProviderEvent{Type: EventComplete, Response: resp}
+			if err != nil {
+				event = ProviderEvent{Type: EventError, Error: err}
+			}
+			r.record(key, []recordedEvent{newRecordedEvent(event)})
+		}
+		return resp, err
+	}
+
+	events, ok := r.cassette.replay(key)
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrNotRecorded, key)
+	}
+	for _, event := range events {
+		switch event.Type {
+		case EventError:
+			return nil, errors.New(event.Error)
+		case EventComplete:
+			return event.Response, nil
+		}
+	}
+	return nil, fmt.Errorf("recorded response for request %s is incomplete", key)
+}
+
+func (r *replayClient) stream(ctx context.Context, messages []message.Message, tools []tools.BaseTool) <-chan ProviderEvent {
+	key := requestKey("stream", messages, tools)
+	eventChan := make(chan ProviderEvent)
+
+	if r.mode == config.ReplayModeRecord {
+		upstream := r.upstream.StreamResponse(ctx, messages, tools)
+		go func() {
+			defer close(eventChan)
+			var events []recordedEvent
+			for event := range upstream {
+				events = append(events, newRecordedEvent(event))
+				eventChan <- event
+			}
+			// Don't record requests the user cancelled.
+			if ctx.Err() == nil {
+				r.record(key, events)
+			}
+		}()
+		return eventChan
+	}
+
+	go func() {
+		defer close(eventChan)
+		events, ok := r.cassette.replay(key)
+		if !ok {
+			eventChan <- ProviderEvent{Type: EventError, Error: fmt.Errorf("%w %s", ErrNotRecorded, key)}
+			return
+		}
+		for _, event := range events {
+			select {
+			case <-ctx.Done():
+				eventChan <- ProviderEvent{Type: EventError, Error: ctx.Err()}
+				return
+			case eventChan <- event.providerEvent():
+			}
+		}
+	}()
+	return eventChan
+}
+
+func (r *replayClient) record(key string, events []recordedEvent) {
+	if err := r.cassette.record(key, events); err != nil {
+		slog.Error("Failed to record response", "cassette", r.cassette.path, "error", err)
+	}
+}
+
+func (r *replayClient) Model() catwalk.Model {
+	if r.upstream != nil {
+		return r.upstream.Model()
+	}
+	return r.providerOptions.model(r.providerOptions.modelType)
+}
+
+// requestKey identifies a request by what the model gets to see of the
+// conversation and the tools, leaving out IDs and timestamps that change
+// between runs.
+func requestKey(method string, messages []message.Message, tools []tools.BaseTool) string {
+	type keyMessage struct {
+		Role        message.MessageRole `json:"role"`
+		Text        string              `json:"text,omitempty"`
+		Binary      []string            `json:"binary,omitempty"`
+		ToolCalls   [][]string          `json:"tool_calls,omitempty"`
+		ToolResults []any               `json:"tool_results,omitempty"`
+	}
+	type keyTool struct {
+		Name       string         `json:"name"`
+		Parameters map[string]any `json:"parameters"`
+		Required   []string       `json:"required"`
+	}
+	request := struct {
+		Method   string       `json:"method"`
+		Messages []keyMessage `json:"messages"`
+		Tools    []keyTool    `json:"tools"`
+	}{Method: method}
+
+	for _, msg := range messages {
+		km := keyMessage{
+			Role: msg.Role,
+			Text: msg.Content().String(),
+		}
+		for _, call := range msg.ToolCalls() {
+			km.ToolCalls = append(km.ToolCalls, []string{call.ID, call.Name, call.Input})
+		}
+		// Tool result metadata is only shown to the user.
+		for _, result := range msg.ToolResults() {
+			km.ToolResults = append(km.ToolResults, []any{result.ToolCallID, result.Content, result.IsError})
+		}
+		for _, binary := range msg.BinaryContent() {
+			sum := sha256.Sum256(binary.Data)
+			km.Binary = append(km.Binary, binary.MIMEType+":"+hex.EncodeToString(sum[:]))
+		}
+		request.Messages = append(request.Messages, km)
+	}
+	for _, tool := range tools {
+		info := tool.Info()
+		request.Tools = append(request.Tools, keyTool{Name: info.Name, Parameters: info.Parameters, Required: info.Required})
+	}
+
+	data, _ := json.Marshal(request)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// recordedEvent is a ProviderEvent as stored in a cassette.
+type recordedEvent struct {
+	Type      EventType         `json:"type"`
+	Content   string            `json:"content,omitempty"`
+	Thinking  string            `json:"thinking,omitempty"`
+	Signature string            `json:"signature,omitempty"`
+	Response  *ProviderResponse `json:"response,omitempty"`
+	ToolCall  *message.ToolCall `json:"tool_call,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+func newRecordedEvent(event ProviderEvent) recordedEvent {
+	recorded := recordedEvent{
+		Type:      event.Type,
+		Content:   event.Content,
+		Thinking:  event.Thinking,
+		Signature: event.Signature,
+		Response:  event.Response,
+		ToolCall:  event.ToolCall,
+	}
+	if event.Error != nil {
+		recorded.Error = event.Error.Error()
+	}
+	return recorded
+}
+
+func (e recordedEvent) providerEvent() ProviderEvent {
+	event := ProviderEvent{
+		Type:      e.Type,
+		Content:   e.Content,
+		Thinking:  e.Thinking,
+		Signature: e.Signature,
+		Response:  e.Response,
+		ToolCall:  e.ToolCall,
+	}
+	if e.Error != "" {
+		event.Error = errors.New(e.Error)
+	}
+	return event
+}
+
+type interaction struct {
+	Key    string          `json:"key"`
+	Events []recordedEvent `json:"events"`
+}
+
+// cassette is a file of recorded interactions. Requests recorded more than
+// once are replayed in the order they were recorded.
+type cassette struct {
+	path string
+
+	mu           sync.Mutex
+	interactions []interaction
+	// replayed counts how often each request was replayed.
+	replayed map[string]int
+}
+
+var (
+	cassettesMu sync.Mutex
+	// Providers share cassettes so they don't overwrite each other's
+	// recordings.
+	cassettes = make(map[string]*cassette)
+)
+
+func openCassette(path string, create bool) (*cassette, error) {
+	cassettesMu.Lock()
+	defer cassettesMu.Unlock()
+	if c, ok := cassettes[path]; ok {
+		return c, nil
+	}
+
+	c, err := readCassette(path, create)
+	if err != nil {
+		return nil, err
+	}
+	cassettes[path] = c
+	return c, nil
+}
+
+// readCassette reads the cassette at path. A missing cassette is created
+// empty when create is set.
+func readCassette(path string, create bool) (*cassette, error) {
+	c := &cassette{path: path, replayed: make(map[string]int)}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && create:
+	case err != nil:
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	default:
+		var file struct {
+			Interactions []interaction `json:"interactions"`
+		}
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+		}
+		c.interactions = file.Interactions
+	}
+	return c, nil
+}
+
+func (c *cassette) replay(key string) ([]recordedEvent, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var recorded [][]recordedEvent
+	for _, i := range c.interactions {
+		if i.Key == key {
+			recorded = append(recorded, i.Events)
+		}
+	}
+	if len(recorded) == 0 {
+		return nil, false
+	}
+	n := c.replayed[key]
+	c.replayed[key]++
+	// Keep answering with the last recording once all were replayed.
+	return recorded[min(n, len(recorded)-1)], true
+}
+
+func (c *cassette) record(key string, events []recordedEvent) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions = append(c.interactions, interaction{Key: key, Events: events})
+
+	data, err := json.MarshalIndent(map[string]any{"interactions": c.interactions}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0o644)
+}
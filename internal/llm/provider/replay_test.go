@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedProvider streams the same events for every request.
+type scriptedProvider struct {
+	events []ProviderEvent
+	calls  int
+}
+
+func (p *scriptedProvider) SendMessages(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (*ProviderResponse, error) {
+	p.calls++
+	return p.events[len(p.events)-1].Response, nil
+}
+
+func (p *scriptedProvider) StreamResponse(ctx context.Context, messages []message.Message, tools []tools.BaseTool) <-chan ProviderEvent {
+	p.calls++
+	eventChan := make(chan ProviderEvent, len(p.events))
+	for _, event := range p.events {
+		eventChan <- event
+	}
+	close(eventChan)
+	return eventChan
+}
+
+func (p *scriptedProvider) Model() catwalk.Model {
+	return catwalk.Model{ID: "scripted"}
+}
+
+func collectEvents(events <-chan ProviderEvent) []ProviderEvent {
+	var collected []ProviderEvent
+	for event := range events {
+		collected = append(collected, event)
+	}
+	return collected
+}
+
+func TestReplayClient(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	upstream := &scriptedProvider{events: []ProviderEvent{
+		{Type: EventContentDelta, Content: "Hello"},
+		{Type: EventComplete, Response: &ProviderResponse{
+			Content:      "Hello",
+			FinishReason: message.FinishReasonEndTurn,
+			Usage:        TokenUsage{InputTokens: 10, OutputTokens: 1},
+		}},
+	}}
+	conversation := func(id string) []message.Message {
+		return []message.Message{{
+			ID:        id,
+			Role:      message.User,
+			CreatedAt: time.Now().UnixNano(),
+			Parts:     []message.ContentPart{message.TextContent{Text: "Hi"}},
+		}}
+	}
+
+	recording, err := readCassette(path, true)
+	require.NoError(t, err)
+	recorder := &replayClient{mode: config.ReplayModeRecord, cassette: recording, upstream: upstream}
+	recorded := collectEvents(recorder.stream(t.Context(), conversation("a"), nil))
+	require.Equal(t, upstream.events, recorded)
+	resp, err := recorder.send(t.Context(), conversation("a"), nil)
+	require.NoError(t, err)
+	require.Equal(t, "Hello", resp.Content)
+	require.Equal(t, 2, upstream.calls)
+
+	replaying, err := readCassette(path, false)
+	require.NoError(t, err)
+	replayer := &replayClient{mode: config.ReplayModeReplay, cassette: replaying}
+
+	t.Run("replays recorded responses", func(t *testing.T) {
+		replayed := collectEvents(replayer.stream(t.Context(), conversation("b"), nil))
+		require.Equal(t, upstream.events, replayed)
+
+		resp, err := replayer.send(t.Context(), conversation("c"), nil)
+		require.NoError(t, err)
+		require.Equal(t, int64(10), resp.Usage.InputTokens)
+	})
+
+	t.Run("fails for requests not recorded", func(t *testing.T) {
+		other := conversation("d")
+		other[0].Parts = []message.ContentPart{message.TextContent{Text: "Bye"}}
+		replayed := collectEvents(replayer.stream(t.Context(), other, nil))
+		require.Len(t, replayed, 1)
+		require.ErrorIs(t, replayed[0].Error, ErrNotRecorded)
+
+		_, err := replayer.send(t.Context(), conversation("e"), []tools.BaseTool{tools.NewGlobTool(t.TempDir())})
+		require.ErrorIs(t, err, ErrNotRecorded)
+	})
+
+	t.Run("missing cassette", func(t *testing.T) {
+		_, err := readCassette(filepath.Join(t.TempDir(), "missing.json"), false)
+		require.Error(t, err)
+	})
+}
@@ -2,17 +2,23 @@ package tools
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
-	"syscall"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/home"
+	"github.com/charmbracelet/crush/internal/job"
 	"github.com/charmbracelet/crush/internal/permission"
 	"github.com/charmbracelet/crush/internal/shell"
 )
@@ -52,9 +58,9 @@ func streamReaderLabeled(r io.Reader, label string, cb func(string), doneCh chan
 type BashParams struct {
 	Command string `json:"command"`
 	Timeout int    `json:"timeout"`
-	// Optional: run the command as a background process. When true, the tool
-	// will start the command and return immediately with the process details.
-	// Output will not be streamed.
+	// Optional: run the command as a background job. When true, the tool
+	// will start the command and return immediately with the job details.
+	// Output is kept by the job service instead of being streamed.
 	IsBackground bool `json:"is_background,omitempty"`
 	// Optional: directory to run the command in. When empty, uses the current
 	// persistent shell working directory. Relative paths are resolved against
@@ -78,7 +84,13 @@ type BashResponseMetadata struct {
 }
 type bashTool struct {
 	permissions permission.Service
+	jobs        job.Service
 	workingDir  string
+	sandbox     *shell.Sandbox
+
+	// protected fingerprints the protectedPaths when the sandbox is on.
+	protectedMu sync.Mutex
+	protected   map[string]string
 }
 
 const (
@@ -163,8 +175,15 @@ var bannedCommands = []string{
 	"ufw",
 }
 
-func bashDescription() string {
+func bashDescription(sandbox *shell.Sandbox) string {
 	bannedCommandsStr := strings.Join(bannedCommands, ", ")
+	sandboxNote := ""
+	if sandbox != nil {
+		sandboxNote = "\n - Commands run in a sandbox: they may read any file, but only write to the project directory and temporary directories. Writing anywhere else fails with a permission error; don't try to work around it, ask the User instead."
+		if sandbox.BlockNetwork {
+			sandboxNote += " They also have no network access."
+		}
+	}
 	return fmt.Sprintf(`Executes a given bash command in a persistent shell session with optional timeout, ensuring proper handling and security measures.
 
 SHELL SUPPORT:
@@ -196,8 +215,8 @@ Before executing the command, please follow these steps:
 Usage notes:
 - The command argument is required.
 - You can specify an optional timeout in milliseconds (up to 600000ms / 10 minutes). If not specified, commands will timeout after 30 minutes.
- - To run long‑lived commands (e.g., dev servers) without blocking, set 'is_background' to true. The command will be launched and the tool will return the spawned PID.
- - To run in a specific directory, set 'directory'. Relative paths are resolved from the project root.
+ - To run long‑lived commands (e.g., dev servers) without blocking, set 'is_background' to true. The command will be launched as a background job and the tool will return its job ID. Its output is kept: use the job_output, job_wait and job_stop tools with the job ID to read it, wait for a line of it (e.g. until a server is listening) and stop the job.
+ - To run in a specific directory, set 'directory'. Relative paths are resolved from the project root.%s
 - VERY IMPORTANT: You MUST avoid using search commands like 'find' and 'grep'. Instead use Grep, Glob, or Agent tools to search. You MUST avoid read tools like 'cat', 'head', 'tail', and 'ls', and use FileRead and LS tools to read files.
 - When issuing multiple commands, use the ';' or '&&' operator to separate them. DO NOT use newlines (newlines are ok in quoted strings).
 - IMPORTANT: All commands share the same shell session. Shell state (environment variables, virtual environments, current directory, etc.) persist between commands. For example, if you set an environment variable as part of a command, the environment variable will persist for subsequent commands.
@@ -315,7 +334,7 @@ EOF
 
 Important:
 - Return an empty response - the user will see the gh output directly
-- Never update git config`, bannedCommandsStr, MaxOutputLength)
+- Never update git config`, bannedCommandsStr, MaxOutputLength, sandboxNote)
 }
 
 func blockFuncs() []shell.BlockFunc {
@@ -350,15 +369,105 @@ func blockFuncs() []shell.BlockFunc {
 	}
 }
 
-func NewBashTool(permission permission.Service, workingDir string) BaseTool {
+func NewBashTool(permission permission.Service, jobs job.Service, workingDir string) BaseTool {
 	// Set up command blocking on the persistent shell
 	persistentShell := shell.GetPersistentShell(workingDir)
 	persistentShell.SetBlockFuncs(blockFuncs())
+	sandbox := newSandbox(workingDir)
+	persistentShell.SetSandbox(sandbox)
 
-	return &bashTool{
+	tool := &bashTool{
 		permissions: permission,
+		jobs:        jobs,
 		workingDir:  workingDir,
+		sandbox:     sandbox,
+	}
+	if sandbox != nil {
+		tool.protected = fingerprintFiles(workingDir, protectedPaths)
+	}
+	return tool
+}
+
+// newSandbox returns the sandbox configured for shell commands, or nil when
+// sandboxing is off.
+func newSandbox(workingDir string) *shell.Sandbox {
+	cfg := config.Get()
+	if cfg == nil || cfg.Options == nil || cfg.Options.Sandbox == nil || !cfg.Options.Sandbox.Enabled {
+		return nil
+	}
+	sandbox := &shell.Sandbox{
+		WritableDirs: []string{workingDir},
+		BlockNetwork: cfg.Options.Sandbox.BlockNetwork,
+	}
+	for _, path := range cfg.Options.Sandbox.WritablePaths {
+		path = home.Long(path)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workingDir, path)
+		}
+		sandbox.WritableDirs = append(sandbox.WritableDirs, path)
+	}
+	return sandbox
+}
+
+// protectedPaths are the paths in the working directory that decide what runs
+// outside of the sandbox, like the project configuration and git hooks.
+// Sandboxed programs can write to them, so they are watched for changes.
+var protectedPaths = []string{"crush.json", ".crush.json", filepath.Join(".git", "hooks")}
+
+// fingerprintFiles returns a fingerprint of each file at or under paths,
+// keyed by the file's path. Paths are relative to dir.
+func fingerprintFiles(dir string, paths []string) map[string]string {
+	fingerprints := make(map[string]string)
+	for _, root := range paths {
+		_ = filepath.WalkDir(filepath.Join(dir, root), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			fingerprint := info.Mode().String()
+			if info.Mode().IsRegular() {
+				if content, err := os.ReadFile(path); err == nil {
+					fingerprint += fmt.Sprintf(" %x", sha256.Sum256(content))
+				}
+			}
+			rel, _ := filepath.Rel(dir, path)
+			fingerprints[rel] = fingerprint
+			return nil
+		})
+	}
+	return fingerprints
+}
+
+// changedFiles returns the files whose fingerprints differ between before and
+// after, including the ones that were added or removed.
+func changedFiles(before, after map[string]string) []string {
+	var changed []string
+	for path, fingerprint := range after {
+		if before[path] != fingerprint {
+			changed = append(changed, path)
+		}
 	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+// protectedChanges returns the protectedPaths that changed since the last
+// call, or since the tool was created.
+func (b *bashTool) protectedChanges() []string {
+	b.protectedMu.Lock()
+	defer b.protectedMu.Unlock()
+	current := fingerprintFiles(b.workingDir, protectedPaths)
+	changed := changedFiles(b.protected, current)
+	b.protected = current
+	return changed
 }
 
 func (b *bashTool) Name() string {
@@ -368,7 +477,7 @@ func (b *bashTool) Name() string {
 func (b *bashTool) Info() ToolInfo {
 	return ToolInfo{
 		Name:        BashToolName,
-		Description: bashDescription(),
+		Description: bashDescription(b.sandbox),
 		Parameters: map[string]any{
 			"command": map[string]any{
 				"type":        "string",
@@ -380,7 +489,7 @@ func (b *bashTool) Info() ToolInfo {
 			},
 			"is_background": map[string]any{
 				"type":        "boolean",
-				"description": "If true, start the command in background and return immediately with the process ID",
+				"description": "If true, start the command as a background job and return immediately with the job ID",
 			},
 			"directory": map[string]any{
 				"type":        "string",
@@ -396,6 +505,20 @@ func (b *bashTool) Info() ToolInfo {
 }
 
 func (b *bashTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	resp, err := b.run(ctx, call)
+	if b.sandbox == nil || err != nil {
+		return resp, err
+	}
+	// Commands, and background jobs, may have changed files that the sandbox
+	// doesn't protect but that run outside of it.
+	if changed := b.protectedChanges(); len(changed) > 0 {
+		slog.Warn("Protected files changed during a sandboxed session", "files", changed)
+		resp.Content += fmt.Sprintf("\n\nWarning: these files changed while commands were running in the sandbox, which doesn't protect them: %s. Ask the user to review the changes.", strings.Join(changed, ", "))
+	}
+	return resp, nil
+}
+
+func (b *bashTool) run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	var params BashParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
 		return NewTextErrorResponse("invalid parameters"), nil
@@ -427,24 +550,25 @@ func (b *bashTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	if sessionID == "" || messageID == "" {
 		return ToolResponse{}, fmt.Errorf("session ID and message ID are required for executing shell command")
 	}
-	if !isSafeReadOnly {
-		shell := shell.GetPersistentShell(b.workingDir)
-		p := b.permissions.Request(
-			permission.CreatePermissionRequest{
-				SessionID:   sessionID,
-				Path:        shell.GetWorkingDir(),
-				ToolCallID:  call.ID,
-				ToolName:    BashToolName,
-				Action:      "execute",
-				Description: fmt.Sprintf("Execute command: %s", params.Command),
-				Params: BashPermissionsParams{
-					Command: params.Command,
-				},
-			},
-		)
-		if !p {
-			return ToolResponse{}, permission.ErrorPermissionDenied
-		}
+	permissionReq := permission.CreatePermissionRequest{
+		SessionID:   sessionID,
+		Path:        shell.GetPersistentShell(b.workingDir).GetWorkingDir(),
+		Command:     params.Command,
+		ToolCallID:  call.ID,
+		ToolName:    BashToolName,
+		Action:      "execute",
+		Description: fmt.Sprintf("Execute command: %s", params.Command),
+		Params: BashPermissionsParams{
+			Command: params.Command,
+		},
+	}
+	// Deny rules apply to safe commands too, which may be chained with
+	// others.
+	if err := b.permissions.Check(permissionReq); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if !isSafeReadOnly && !b.permissions.Request(permissionReq) {
+		return ToolResponse{}, permission.ErrorPermissionDenied
 	}
 	startTime := time.Now()
 	var currentWorkingDir string
@@ -463,35 +587,23 @@ func (b *bashTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		}
 	}
 
-	// Background execution path: start an OS subprocess and return immediately
+	// Background execution path: start a job and return immediately
 	if params.IsBackground {
-		// Use bash -lc to execute the command
-		name := "bash"
-		args := []string{"-lc", params.Command}
-		cmd := exec.CommandContext(ctx, name, args...)
-		if currentWorkingDir != "" {
-			cmd.Dir = currentWorkingDir
-		} else {
-			cmd.Dir = shell.GetPersistentShell(b.workingDir).GetWorkingDir()
+		dir := currentWorkingDir
+		if dir == "" {
+			dir = shell.GetPersistentShell(b.workingDir).GetWorkingDir()
 		}
-		// Inherit environment
-		cmd.Env = os.Environ()
-		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
-		if err := cmd.Start(); err != nil {
-			return ToolResponse{}, fmt.Errorf("failed to start background command: %w", err)
+		j, err := b.jobs.Start(sessionID, params.Command, dir, b.sandbox)
+		if err != nil {
+			return ToolResponse{}, err
 		}
-		pid := cmd.Process.Pid
-		pgid := pid
-		// When Setpgid is enabled, PGID equals PID for the group leader
-		pgid = pid
-		// Do not wait; process continues in background
 		metadata := BashResponseMetadata{
 			StartTime:        startTime.UnixMilli(),
 			EndTime:          time.Now().UnixMilli(),
-			Output:           fmt.Sprintf("Started background process PID: %d", pid),
-			WorkingDirectory: cmd.Dir,
+			Output:           fmt.Sprintf("Started background job %d (PID %d)", j.ID, j.PID),
+			WorkingDirectory: dir,
 		}
-		msg := fmt.Sprintf("<background>\nPID: %d\nPGID: %d\nDirectory: %s\nCommand: %s\n</background>", pid, pgid, cmd.Dir, params.Command)
+		msg := fmt.Sprintf("<background>\nJob ID: %d\nPID: %d\nDirectory: %s\nCommand: %s\n</background>\n\nUse job_output to read its output, job_wait to wait for a line of its output and job_stop to stop it.", j.ID, j.PID, dir, params.Command)
 		return WithResponseMetadata(NewTextResponse(msg), metadata), nil
 	}
 
@@ -521,6 +633,11 @@ func (b *bashTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 				cmd.Dir = shell.GetPersistentShell(b.workingDir).GetWorkingDir()
 			}
 			cmd.Env = os.Environ()
+			if b.sandbox != nil {
+				if err := b.sandbox.Command(cmd); err != nil {
+					return ToolResponse{}, fmt.Errorf("failed to sandbox command: %w", err)
+				}
+			}
 
 			stdoutPipe, _ := cmd.StdoutPipe()
 			stderrPipe, _ := cmd.StderrPipe()
@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtectedChanges(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	hooks := filepath.Join(dir, ".git", "hooks")
+	require.NoError(t, os.MkdirAll(hooks, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "crush.json"), []byte("{}"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0o644))
+
+	b := &bashTool{workingDir: dir, protected: fingerprintFiles(dir, protectedPaths)}
+	require.Empty(t, b.protectedChanges())
+
+	// Other files may change freely.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package other"), 0o644))
+	require.Empty(t, b.protectedChanges())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "crush.json"), []byte(`{"permissions":{}}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(hooks, "pre-commit"), []byte("#!/bin/sh"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".crush.json"), []byte("{}"), 0o644))
+	require.Equal(t, []string{
+		".crush.json",
+		filepath.Join(".git", "hooks", "pre-commit"),
+		"crush.json",
+	}, b.protectedChanges())
+
+	// Changes are reported once.
+	require.Empty(t, b.protectedChanges())
+
+	require.NoError(t, os.Remove(filepath.Join(hooks, "pre-commit")))
+	require.Equal(t, []string{filepath.Join(".git", "hooks", "pre-commit")}, b.protectedChanges())
+}
@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+)
+
+type DefinitionParams struct {
+	NavigationParams
+	Implementation bool `json:"implementation,omitempty"`
+}
+
+type definitionTool struct {
+	lspClients map[string]*lsp.Client
+	workingDir string
+}
+
+const (
+	DefinitionToolName    = "definition"
+	definitionDescription = `Finds where a symbol is defined, using the language server of the file.
+WHEN TO USE THIS TOOL:
+- Use when you need the definition of a function, method, type or variable used in the code
+- Prefer it over grep for symbol names: it finds the exact function, method or overload the code refers to
+HOW TO USE:
+- Give the file, the line number and the name of the symbol as it appears on that line
+- Set implementation to true to find the implementations of an interface or abstract method instead
+- Each result shows the path, line and column of the definition and the lines around it
+LIMITATIONS:
+- Only works for files handled by a configured LSP
+TIPS:
+- Use the view tool with an offset to read more of a definition`
+
+	// The lines shown around the start of a definition.
+	definitionLinesBefore = 2
+	definitionLinesAfter  = 10
+)
+
+func NewDefinitionTool(lspClients map[string]*lsp.Client, workingDir string) BaseTool {
+	return &definitionTool{
+		lspClients: lspClients,
+		workingDir: workingDir,
+	}
+}
+
+func (d *definitionTool) Name() string {
+	return DefinitionToolName
+}
+
+func (d *definitionTool) Info() ToolInfo {
+	parameters := navigationParameters()
+	parameters["implementation"] = map[string]any{
+		"type":        "boolean",
+		"description": "Find the implementations of the symbol instead of its definition (default false)",
+	}
+	return ToolInfo{
+		Name:        DefinitionToolName,
+		Description: definitionDescription,
+		Parameters:  parameters,
+		Required:    []string{"file_path", "line", "symbol"},
+		ReadOnly:    true,
+	}
+}
+
+func (d *definitionTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params DefinitionParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+
+	method, what := "textDocument/definition", "Definition"
+	if params.Implementation {
+		method, what = "textDocument/implementation", "Implementations"
+	}
+	client, position, err := resolveSymbol(ctx, d.lspClients, d.workingDir, params.NavigationParams, method)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	var locations []protocol.Location
+	if params.Implementation {
+		result, err := client.Implementation(ctx, protocol.ImplementationParams{TextDocumentPositionParams: position})
+		if err != nil {
+			return ToolResponse{}, fmt.Errorf("error finding implementations: %w", err)
+		}
+		locations = definitionLocations(result.Value)
+	} else {
+		result, err := client.Definition(ctx, protocol.DefinitionParams{TextDocumentPositionParams: position})
+		if err != nil {
+			return ToolResponse{}, fmt.Errorf("error finding definition: %w", err)
+		}
+		locations = definitionLocations(result.Value)
+	}
+	if len(locations) == 0 {
+		return NewTextResponse(fmt.Sprintf("No %s found for %s", strings.ToLower(what), params.Symbol)), nil
+	}
+
+	s := newSnippets(d.workingDir)
+	var output strings.Builder
+	fmt.Fprintf(&output, "%s of %s:", what, params.Symbol)
+	for i, loc := range locations {
+		if i == maxNavigationResults {
+			fmt.Fprintf(&output, "\n\n(%d more not shown)", len(locations)-i)
+			break
+		}
+		fmt.Fprintf(&output, "\n\n%s\n%s", s.position(loc), s.block(loc, definitionLinesBefore, definitionLinesAfter))
+	}
+	return NewTextResponse(output.String()), nil
+}
@@ -66,6 +66,7 @@ func (b *diagnosticsTool) Info() ToolInfo {
 			},
 		},
 		Required: []string{},
+		ReadOnly: true,
 	}
 }
 
@@ -137,18 +137,19 @@ func (t *downloadTool) Run(ctx context.Context, call ToolCall) (ToolResponse, er
 		return ToolResponse{}, fmt.Errorf("session ID and message ID are required for downloading files")
 	}
 
-	p := t.permissions.Request(
-		permission.CreatePermissionRequest{
-			SessionID:   sessionID,
-			Path:        filePath,
-			ToolName:    DownloadToolName,
-			Action:      "download",
-			Description: fmt.Sprintf("Download file from URL: %s to %s", params.URL, filePath),
-			Params:      DownloadPermissionsParams(params),
-		},
-	)
-
-	if !p {
+	permissionReq := permission.CreatePermissionRequest{
+		SessionID:   sessionID,
+		Path:        filePath,
+		FilePath:    filePath,
+		ToolName:    DownloadToolName,
+		Action:      "download",
+		Description: fmt.Sprintf("Download file from URL: %s to %s", params.URL, filePath),
+		Params:      DownloadPermissionsParams(params),
+	}
+	if err := t.permissions.Check(permissionReq); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if !t.permissions.Request(permissionReq) {
 		return ToolResponse{}, permission.ErrorPermissionDenied
 	}
 
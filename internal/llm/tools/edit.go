@@ -214,22 +214,24 @@ func (e *editTool) createNewFile(ctx context.Context, filePath, content string,
 		content,
 		strings.TrimPrefix(filePath, e.workingDir),
 	)
-	p := e.permissions.Request(
-		permission.CreatePermissionRequest{
-			SessionID:   sessionID,
-			Path:        fsext.PathOrPrefix(filePath, e.workingDir),
-			ToolCallID:  call.ID,
-			ToolName:    EditToolName,
-			Action:      "write",
-			Description: fmt.Sprintf("Create file %s", filePath),
-			Params: EditPermissionsParams{
-				FilePath:   filePath,
-				OldContent: "",
-				NewContent: content,
-			},
+	permissionReq := permission.CreatePermissionRequest{
+		SessionID:   sessionID,
+		Path:        fsext.PathOrPrefix(filePath, e.workingDir),
+		FilePath:    filePath,
+		ToolCallID:  call.ID,
+		ToolName:    EditToolName,
+		Action:      "write",
+		Description: fmt.Sprintf("Create file %s", filePath),
+		Params: EditPermissionsParams{
+			FilePath:   filePath,
+			OldContent: "",
+			NewContent: content,
 		},
-	)
-	if !p {
+	}
+	if err := e.permissions.Check(permissionReq); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if !e.permissions.Request(permissionReq) {
 		return ToolResponse{}, permission.ErrorPermissionDenied
 	}
 
@@ -238,15 +240,20 @@ func (e *editTool) createNewFile(ctx context.Context, filePath, content string,
 		return ToolResponse{}, fmt.Errorf("failed to write file: %w", err)
 	}
 
+	content, formatNote := formatOnWrite(ctx, e.lspClients, e.workingDir, filePath, content)
+	if formatNote != "" {
+		_, additions, removals = diff.GenerateDiff("", content, strings.TrimPrefix(filePath, e.workingDir))
+	}
+
 	// File can't be in the history so we create a new file history
-	_, err = e.files.Create(ctx, sessionID, filePath, "")
+	_, err = e.files.CreateNew(ctx, sessionID, messageID, filePath)
 	if err != nil {
 		// Log error but don't fail the operation
 		return ToolResponse{}, fmt.Errorf("error creating file history: %w", err)
 	}
 
 	// Add the new content to the file history
-	_, err = e.files.CreateVersion(ctx, sessionID, filePath, content)
+	_, err = e.files.CreateVersion(ctx, sessionID, messageID, filePath, content)
 	if err != nil {
 		// Log error but don't fail the operation
 		slog.Debug("Error creating file history version", "error", err)
@@ -256,7 +263,7 @@ func (e *editTool) createNewFile(ctx context.Context, filePath, content string,
 	recordFileRead(filePath)
 
 	return WithResponseMetadata(
-		NewTextResponse("File created: "+filePath),
+		NewTextResponse("File created: "+filePath+formatNote),
 		EditResponseMetadata{
 			OldContent: "",
 			NewContent: content,
@@ -335,22 +342,24 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 		strings.TrimPrefix(filePath, e.workingDir),
 	)
 
-	p := e.permissions.Request(
-		permission.CreatePermissionRequest{
-			SessionID:   sessionID,
-			Path:        fsext.PathOrPrefix(filePath, e.workingDir),
-			ToolCallID:  call.ID,
-			ToolName:    EditToolName,
-			Action:      "write",
-			Description: fmt.Sprintf("Delete content from file %s", filePath),
-			Params: EditPermissionsParams{
-				FilePath:   filePath,
-				OldContent: oldContent,
-				NewContent: newContent,
-			},
+	permissionReq := permission.CreatePermissionRequest{
+		SessionID:   sessionID,
+		Path:        fsext.PathOrPrefix(filePath, e.workingDir),
+		FilePath:    filePath,
+		ToolCallID:  call.ID,
+		ToolName:    EditToolName,
+		Action:      "write",
+		Description: fmt.Sprintf("Delete content from file %s", filePath),
+		Params: EditPermissionsParams{
+			FilePath:   filePath,
+			OldContent: oldContent,
+			NewContent: newContent,
 		},
-	)
-	if !p {
+	}
+	if err := e.permissions.Check(permissionReq); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if !e.permissions.Request(permissionReq) {
 		return ToolResponse{}, permission.ErrorPermissionDenied
 	}
 
@@ -363,10 +372,15 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 		return ToolResponse{}, fmt.Errorf("failed to write file: %w", err)
 	}
 
+	newContent, formatNote := formatOnWrite(ctx, e.lspClients, e.workingDir, filePath, newContent)
+	if formatNote != "" {
+		_, additions, removals = diff.GenerateDiff(oldContent, newContent, strings.TrimPrefix(filePath, e.workingDir))
+	}
+
 	// Check if file exists in history
 	file, err := e.files.GetByPathAndSession(ctx, filePath, sessionID)
 	if err != nil {
-		_, err = e.files.Create(ctx, sessionID, filePath, oldContent)
+		_, err = e.files.Create(ctx, sessionID, messageID, filePath, oldContent)
 		if err != nil {
 			// Log error but don't fail the operation
 			return ToolResponse{}, fmt.Errorf("error creating file history: %w", err)
@@ -374,13 +388,13 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 	}
 	if file.Content != oldContent {
 		// User Manually changed the content store an intermediate version
-		_, err = e.files.CreateVersion(ctx, sessionID, filePath, oldContent)
+		_, err = e.files.CreateVersion(ctx, sessionID, messageID, filePath, oldContent)
 		if err != nil {
 			slog.Debug("Error creating file history version", "error", err)
 		}
 	}
 	// Store the new version
-	_, err = e.files.CreateVersion(ctx, sessionID, filePath, "")
+	_, err = e.files.CreateVersion(ctx, sessionID, messageID, filePath, newContent)
 	if err != nil {
 		slog.Debug("Error creating file history version", "error", err)
 	}
@@ -389,7 +403,7 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 	recordFileRead(filePath)
 
 	return WithResponseMetadata(
-		NewTextResponse("Content deleted from file: "+filePath),
+		NewTextResponse("Content deleted from file: "+filePath+formatNote),
 		EditResponseMetadata{
 			OldContent: oldContent,
 			NewContent: newContent,
@@ -470,22 +484,24 @@ func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newS
 		strings.TrimPrefix(filePath, e.workingDir),
 	)
 
-	p := e.permissions.Request(
-		permission.CreatePermissionRequest{
-			SessionID:   sessionID,
-			Path:        fsext.PathOrPrefix(filePath, e.workingDir),
-			ToolCallID:  call.ID,
-			ToolName:    EditToolName,
-			Action:      "write",
-			Description: fmt.Sprintf("Replace content in file %s", filePath),
-			Params: EditPermissionsParams{
-				FilePath:   filePath,
-				OldContent: oldContent,
-				NewContent: newContent,
-			},
+	permissionReq := permission.CreatePermissionRequest{
+		SessionID:   sessionID,
+		Path:        fsext.PathOrPrefix(filePath, e.workingDir),
+		FilePath:    filePath,
+		ToolCallID:  call.ID,
+		ToolName:    EditToolName,
+		Action:      "write",
+		Description: fmt.Sprintf("Replace content in file %s", filePath),
+		Params: EditPermissionsParams{
+			FilePath:   filePath,
+			OldContent: oldContent,
+			NewContent: newContent,
 		},
-	)
-	if !p {
+	}
+	if err := e.permissions.Check(permissionReq); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if !e.permissions.Request(permissionReq) {
 		return ToolResponse{}, permission.ErrorPermissionDenied
 	}
 
@@ -498,10 +514,15 @@ func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newS
 		return ToolResponse{}, fmt.Errorf("failed to write file: %w", err)
 	}
 
+	newContent, formatNote := formatOnWrite(ctx, e.lspClients, e.workingDir, filePath, newContent)
+	if formatNote != "" {
+		_, additions, removals = diff.GenerateDiff(oldContent, newContent, strings.TrimPrefix(filePath, e.workingDir))
+	}
+
 	// Check if file exists in history
 	file, err := e.files.GetByPathAndSession(ctx, filePath, sessionID)
 	if err != nil {
-		_, err = e.files.Create(ctx, sessionID, filePath, oldContent)
+		_, err = e.files.Create(ctx, sessionID, messageID, filePath, oldContent)
 		if err != nil {
 			// Log error but don't fail the operation
 			return ToolResponse{}, fmt.Errorf("error creating file history: %w", err)
@@ -509,13 +530,13 @@ func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newS
 	}
 	if file.Content != oldContent {
 		// User Manually changed the content store an intermediate version
-		_, err = e.files.CreateVersion(ctx, sessionID, filePath, oldContent)
+		_, err = e.files.CreateVersion(ctx, sessionID, messageID, filePath, oldContent)
 		if err != nil {
 			slog.Debug("Error creating file history version", "error", err)
 		}
 	}
 	// Store the new version
-	_, err = e.files.CreateVersion(ctx, sessionID, filePath, newContent)
+	_, err = e.files.CreateVersion(ctx, sessionID, messageID, filePath, newContent)
 	if err != nil {
 		slog.Debug("Error creating file history version", "error", err)
 	}
@@ -524,7 +545,7 @@ func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newS
 	recordFileRead(filePath)
 
 	return WithResponseMetadata(
-		NewTextResponse("Content replaced in file: "+filePath),
+		NewTextResponse("Content replaced in file: "+filePath+formatNote),
 		EditResponseMetadata{
 			OldContent: oldContent,
 			NewContent: newContent,
@@ -105,6 +105,7 @@ func (t *fetchTool) Info() ToolInfo {
 			},
 		},
 		Required: []string{"url", "format"},
+		ReadOnly: true,
 	}
 }
 
@@ -132,19 +133,19 @@ func (t *fetchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		return ToolResponse{}, fmt.Errorf("session ID and message ID are required for creating a new file")
 	}
 
-	p := t.permissions.Request(
-		permission.CreatePermissionRequest{
-			SessionID:   sessionID,
-			Path:        t.workingDir,
-			ToolCallID:  call.ID,
-			ToolName:    FetchToolName,
-			Action:      "fetch",
-			Description: fmt.Sprintf("Fetch content from URL: %s", params.URL),
-			Params:      FetchPermissionsParams(params),
-		},
-	)
-
-	if !p {
+	permissionReq := permission.CreatePermissionRequest{
+		SessionID:   sessionID,
+		Path:        t.workingDir,
+		ToolCallID:  call.ID,
+		ToolName:    FetchToolName,
+		Action:      "fetch",
+		Description: fmt.Sprintf("Fetch content from URL: %s", params.URL),
+		Params:      FetchPermissionsParams(params),
+	}
+	if err := t.permissions.Check(permissionReq); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if !t.permissions.Request(permissionReq) {
 		return ToolResponse{}, permission.ErrorPermissionDenied
 	}
 
@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/diff"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+	"github.com/charmbracelet/crush/internal/lsp/util"
+)
+
+// formatTimeout bounds how long an external formatter may run.
+const formatTimeout = 10 * time.Second
+
+// formatOnWrite formats filePath, which a tool just wrote content to, with
+// the LSP server configured to format it on write, or else with the external
+// formatter configured for it. It returns the content of the file afterwards
+// and, when formatting changed it, a note with the diff for the model.
+// Formatting errors are logged and leave the file as it was written.
+func formatOnWrite(ctx context.Context, lspClients map[string]*lsp.Client, workingDir, filePath, content string) (string, string) {
+	cfg := config.Get()
+	if cfg == nil {
+		return content, ""
+	}
+
+	var (
+		formatted string
+		formatter string
+		err       error
+	)
+	if name, client := formattingClient(cfg, lspClients, filePath); client != nil {
+		formatter = name
+		formatted, err = lspFormat(ctx, client, filePath, content)
+	} else if name, f, ok := externalFormatter(cfg, filePath); ok {
+		formatter = name
+		formatted, err = externalFormat(ctx, f, workingDir, filePath, content)
+	} else {
+		return content, ""
+	}
+	if err != nil {
+		slog.Warn("Error formatting file", "path", filePath, "formatter", formatter, "error", err)
+		return content, ""
+	}
+	if formatted == content {
+		return content, ""
+	}
+	if err := os.WriteFile(filePath, []byte(formatted), 0o644); err != nil {
+		slog.Warn("Error writing formatted file", "path", filePath, "error", err)
+		return content, ""
+	}
+
+	before, _ := fsext.ToUnixLineEndings(content)
+	after, _ := fsext.ToUnixLineEndings(formatted)
+	d, _, _ := diff.GenerateDiff(before, after, strings.TrimPrefix(filePath, workingDir))
+	return formatted, fmt.Sprintf("\n\nThe file was formatted with %s after it was written, which changed it like this:\n%s", formatter, d)
+}
+
+// formattingClient returns the LSP client, in order of name, that is
+// configured to format on write, handles filePath and supports formatting.
+func formattingClient(cfg *config.Config, lspClients map[string]*lsp.Client, filePath string) (string, *lsp.Client) {
+	for _, l := range cfg.LSP.Sorted() {
+		if !l.LSP.FormatOnWrite {
+			continue
+		}
+		client, ok := lspClients[l.Name]
+		if ok && client.HandlesFile(filePath) && client.IsMethodSupported("textDocument/formatting") {
+			return l.Name, client
+		}
+	}
+	return "", nil
+}
+
+// externalFormatter returns the formatter, in order of name, that handles
+// filePath.
+func externalFormatter(cfg *config.Config, filePath string) (string, config.Formatter, bool) {
+	for _, name := range slices.Sorted(maps.Keys(cfg.Formatters)) {
+		if f := cfg.Formatters[name]; f.HandlesFile(filePath) {
+			return name, f, true
+		}
+	}
+	return "", config.Formatter{}, false
+}
+
+func lspFormat(ctx context.Context, client *lsp.Client, filePath, content string) (string, error) {
+	// The server formats its own copy of the file, so it needs to see what
+	// was just written first.
+	if client.IsFileOpen(filePath) {
+		if err := client.NotifyChange(ctx, filePath); err != nil {
+			return "", err
+		}
+	} else if err := client.OpenFile(ctx, filePath); err != nil {
+		return "", err
+	}
+
+	edits, err := client.Formatting(ctx, protocol.DocumentFormattingParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: protocol.URIFromPath(filePath)},
+		Options: protocol.FormattingOptions{
+			TabSize:      4,
+			InsertSpaces: !indentsWithTabs(content),
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return util.ApplyTextEdits(content, edits)
+}
+
+func externalFormat(ctx context.Context, f config.Formatter, workingDir, filePath, content string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, formatTimeout)
+	defer cancel()
+
+	args := make([]string, len(f.Args))
+	for i, arg := range f.Args {
+		args[i] = strings.ReplaceAll(arg, "{file}", filePath)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, f.Command, args...)
+	cmd.Dir = workingDir
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s %s: %w: %s", filepath.Base(f.Command), strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	if stdout.Len() == 0 && content != "" {
+		return "", fmt.Errorf("%s printed nothing", filepath.Base(f.Command))
+	}
+	return stdout.String(), nil
+}
+
+// indentsWithTabs reports whether more lines of content are indented with
+// tabs than with spaces.
+func indentsWithTabs(content string) bool {
+	tabs, spaces := 0, 0
+	for line := range strings.SplitSeq(content, "\n") {
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			tabs++
+		case strings.HasPrefix(line, " "):
+			spaces++
+		}
+	}
+	return tabs > spaces
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalFormat(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs tr and sh")
+	}
+
+	t.Run("formats stdin", func(t *testing.T) {
+		t.Parallel()
+		formatted, err := externalFormat(t.Context(), config.Formatter{Command: "tr", Args: []string{"a-z", "A-Z"}}, t.TempDir(), "main.go", "package main\n")
+		require.NoError(t, err)
+		require.Equal(t, "PACKAGE MAIN\n", formatted)
+	})
+
+	t.Run("replaces the file placeholder", func(t *testing.T) {
+		t.Parallel()
+		formatted, err := externalFormat(t.Context(), config.Formatter{Command: "sh", Args: []string{"-c", "echo $0", "{file}"}}, t.TempDir(), "/tmp/main.go", "x")
+		require.NoError(t, err)
+		require.Equal(t, "/tmp/main.go\n", formatted)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Parallel()
+		_, err := externalFormat(t.Context(), config.Formatter{Command: "sh", Args: []string{"-c", "echo bad syntax >&2; exit 2"}}, t.TempDir(), "main.go", "x")
+		require.ErrorContains(t, err, "bad syntax")
+	})
+}
+
+func TestExternalFormatter(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{Formatters: map[string]config.Formatter{
+		"prettier": {Command: "prettier", FileTypes: []string{"ts", ".tsx"}},
+		"gofmt":    {Command: "gofmt", FileTypes: []string{"go"}},
+	}}
+
+	name, f, ok := externalFormatter(cfg, "/src/App.TSX")
+	require.True(t, ok)
+	require.Equal(t, "prettier", name)
+	require.Equal(t, "prettier", f.Command)
+
+	name, _, ok = externalFormatter(cfg, "main.go")
+	require.True(t, ok)
+	require.Equal(t, "gofmt", name)
+
+	_, _, ok = externalFormatter(cfg, "go.mod")
+	require.False(t, ok)
+}
+
+func TestIndentsWithTabs(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, indentsWithTabs("func main() {\n\tprintln(1)\n}\n"))
+	require.False(t, indentsWithTabs("def main():\n    print(1)\n"))
+	require.False(t, indentsWithTabs(""))
+}
@@ -96,6 +96,7 @@ func (g *globTool) Info() ToolInfo {
 			},
 		},
 		Required: []string{"pattern"},
+		ReadOnly: true,
 	}
 }
 
@@ -175,6 +175,7 @@ func (g *grepTool) Info() ToolInfo {
 			},
 		},
 		Required: []string{"pattern"},
+		ReadOnly: true,
 	}
 }
 
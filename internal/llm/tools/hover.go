@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+)
+
+type HoverParams = NavigationParams
+
+type hoverTool struct {
+	lspClients map[string]*lsp.Client
+	workingDir string
+}
+
+const (
+	HoverToolName    = "hover"
+	hoverDescription = `Shows the type, signature and documentation of a symbol, using the language server of the file.
+WHEN TO USE THIS TOOL:
+- Use to learn the type of a variable or expression, or the signature and documentation of a function, without reading its definition
+- Helpful to check which overload or method a call resolves to
+HOW TO USE:
+- Give the file, the line number and the name of the symbol as it appears on that line
+LIMITATIONS:
+- Only works for files handled by a configured LSP
+- What is shown depends on the language server`
+)
+
+func NewHoverTool(lspClients map[string]*lsp.Client, workingDir string) BaseTool {
+	return &hoverTool{
+		lspClients: lspClients,
+		workingDir: workingDir,
+	}
+}
+
+func (h *hoverTool) Name() string {
+	return HoverToolName
+}
+
+func (h *hoverTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        HoverToolName,
+		Description: hoverDescription,
+		Parameters:  navigationParameters(),
+		Required:    []string{"file_path", "line", "symbol"},
+		ReadOnly:    true,
+	}
+}
+
+func (h *hoverTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params HoverParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+
+	client, position, err := resolveSymbol(ctx, h.lspClients, h.workingDir, params, "textDocument/hover")
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	hover, err := client.Hover(ctx, protocol.HoverParams{TextDocumentPositionParams: position})
+	if err != nil {
+		return ToolResponse{}, fmt.Errorf("error getting hover information: %w", err)
+	}
+	content := strings.TrimSpace(hover.Contents.Value)
+	if content == "" {
+		return NewTextResponse(fmt.Sprintf("No information available for %s", params.Symbol)), nil
+	}
+	return NewTextResponse(content), nil
+}
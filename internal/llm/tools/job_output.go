@@ -0,0 +1,143 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/charmbracelet/crush/internal/job"
+)
+
+const (
+	JobOutputToolName    = "job_output"
+	jobOutputDescription = `Reads the output of a background job started with the bash tool's is_background parameter.
+
+WHEN TO USE THIS TOOL:
+- Use to check what a dev server, watcher or other long-running command printed
+- Use to look for errors in the output of a background job
+
+HOW TO USE:
+- Provide the job ID returned by the bash tool or listed by the jobs tool
+- By default the last 50 lines are returned; set lines to get more or fewer
+- Set pattern to a regular expression to get only the matching lines instead
+- Lines of stdout and stderr are numbered together, in the order they were written, and stderr lines are marked
+
+LIMITATIONS:
+- Only the last 5000 lines of output of each job are kept
+- Very long lines are truncated
+
+TIPS:
+- Use job_wait instead to wait for a line that hasn't been printed yet`
+
+	defaultJobOutputLines = 50
+	maxJobOutputMatches   = 100
+)
+
+type JobOutputParams struct {
+	JobID   int    `json:"job_id"`
+	Lines   int    `json:"lines,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+}
+
+type jobOutputTool struct {
+	jobs job.Service
+}
+
+func NewJobOutputTool(jobs job.Service) BaseTool {
+	return &jobOutputTool{
+		jobs: jobs,
+	}
+}
+
+func (t *jobOutputTool) Name() string {
+	return JobOutputToolName
+}
+
+func (t *jobOutputTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        JobOutputToolName,
+		Description: jobOutputDescription,
+		Parameters: map[string]any{
+			"job_id": map[string]any{
+				"type":        "number",
+				"description": "The ID of the background job",
+			},
+			"lines": map[string]any{
+				"type":        "number",
+				"description": "How many of the last lines of output to return (default 50)",
+			},
+			"pattern": map[string]any{
+				"type":        "string",
+				"description": "Optional regular expression; only lines that match it are returned",
+			},
+		},
+		Required: []string{"job_id"},
+		ReadOnly: true,
+	}
+}
+
+func (t *jobOutputTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params JobOutputParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	if params.Lines <= 0 {
+		params.Lines = defaultJobOutputLines
+	}
+	var re *regexp.Regexp
+	if params.Pattern != "" {
+		var err error
+		if re, err = regexp.Compile(params.Pattern); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("invalid pattern: %s", err)), nil
+		}
+	}
+
+	j, lines, err := t.jobs.Output(params.JobID)
+	if err == nil {
+		err = jobInSession(ctx, j)
+	}
+	if errors.Is(err, job.ErrNotFound) {
+		return NewTextErrorResponse(err.Error()), nil
+	} else if err != nil {
+		return ToolResponse{}, err
+	}
+
+	header := describeJob(j)
+	if re != nil {
+		matches, total := grepJobLines(lines, re, maxJobOutputMatches)
+		if total == 0 {
+			return NewTextResponse(fmt.Sprintf("%s\n\nNo lines of output match %q.", header, params.Pattern)), nil
+		}
+		header += fmt.Sprintf("\n\n%d lines match %q", total, params.Pattern)
+		if total > len(matches) {
+			header += fmt.Sprintf(", showing the last %d", len(matches))
+		}
+		return NewTextResponse(fmt.Sprintf("%s:\n%s", header, formatJobLines(matches))), nil
+	}
+
+	if len(lines) == 0 {
+		return NewTextResponse(header + "\n\nThe job has printed nothing yet."), nil
+	}
+	if len(lines) > params.Lines {
+		lines = lines[len(lines)-params.Lines:]
+	}
+	return NewTextResponse(fmt.Sprintf("%s\n\n%s", header, formatJobLines(lines))), nil
+}
+
+// grepJobLines returns the last limit lines that match re, and how many
+// lines match in total.
+func grepJobLines(lines []job.Line, re *regexp.Regexp, limit int) ([]job.Line, int) {
+	var matches []job.Line
+	for _, line := range lines {
+		if re.MatchString(line.Text) {
+			matches = append(matches, line)
+		}
+	}
+	total := len(matches)
+	if total > limit {
+		matches = matches[total-limit:]
+	}
+	return matches, total
+}
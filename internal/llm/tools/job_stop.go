@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/crush/internal/job"
+)
+
+const (
+	JobStopToolName    = "job_stop"
+	jobStopDescription = `Stops a background job started with the bash tool's is_background parameter, and the processes it started.
+
+WHEN TO USE THIS TOOL:
+- Use to stop a dev server or watcher you no longer need, or before starting it again with other settings
+
+HOW TO USE:
+- Provide the job ID returned by the bash tool or listed by the jobs tool
+- The job is sent SIGTERM, and SIGKILL if it hasn't exited after 5 seconds
+
+TIPS:
+- Background jobs are stopped when Crush exits, so you don't need to stop them at the end of a task`
+)
+
+type JobStopParams struct {
+	JobID int `json:"job_id"`
+}
+
+type jobStopTool struct {
+	jobs job.Service
+}
+
+func NewJobStopTool(jobs job.Service) BaseTool {
+	return &jobStopTool{
+		jobs: jobs,
+	}
+}
+
+func (t *jobStopTool) Name() string {
+	return JobStopToolName
+}
+
+func (t *jobStopTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        JobStopToolName,
+		Description: jobStopDescription,
+		Parameters: map[string]any{
+			"job_id": map[string]any{
+				"type":        "number",
+				"description": "The ID of the background job to stop",
+			},
+		},
+		Required: []string{"job_id"},
+	}
+}
+
+func (t *jobStopTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params JobStopParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+
+	j, err := t.jobs.Get(params.JobID)
+	if err == nil {
+		err = jobInSession(ctx, j)
+	}
+	if errors.Is(err, job.ErrNotFound) {
+		return NewTextErrorResponse(err.Error()), nil
+	} else if err != nil {
+		return ToolResponse{}, err
+	}
+	if j.Status != job.StatusRunning {
+		return NewTextResponse(fmt.Sprintf("%s\n\nThe job is not running.", describeJob(j))), nil
+	}
+
+	j, err = t.jobs.Stop(params.JobID)
+	if err != nil {
+		return ToolResponse{}, err
+	}
+	return NewTextResponse(describeJob(j)), nil
+}
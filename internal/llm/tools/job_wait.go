@@ -0,0 +1,147 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/job"
+)
+
+const (
+	JobWaitToolName    = "job_wait"
+	jobWaitDescription = `Waits until a background job prints a line that matches a pattern, or until it exits.
+
+WHEN TO USE THIS TOOL:
+- Use after starting a dev server in the background to wait until it is ready, e.g. with the pattern "listening on"
+- Use to wait for a build or test run in the background to finish
+
+HOW TO USE:
+- Provide the job ID returned by the bash tool or listed by the jobs tool
+- Provide pattern, a regular expression to match against each new line of output
+- Leave pattern empty to wait until the job exits
+- Set timeout to how many seconds to wait at most (default 60, max 600)
+
+LIMITATIONS:
+- Only lines printed after the call starts are matched; use job_output to search what was printed before
+
+TIPS:
+- Match a pattern for failures too, e.g. "listening on|error", so that you don't wait for the timeout when the job fails
+- The last lines of output are returned when the job exits or the timeout expires, so you can see what happened`
+
+	defaultJobWaitTimeout = 60
+	maxJobWaitTimeout     = 600
+	jobWaitContextLines   = 20
+)
+
+type JobWaitParams struct {
+	JobID   int    `json:"job_id"`
+	Pattern string `json:"pattern,omitempty"`
+	Timeout int    `json:"timeout,omitempty"`
+}
+
+type jobWaitTool struct {
+	jobs job.Service
+}
+
+func NewJobWaitTool(jobs job.Service) BaseTool {
+	return &jobWaitTool{
+		jobs: jobs,
+	}
+}
+
+func (t *jobWaitTool) Name() string {
+	return JobWaitToolName
+}
+
+func (t *jobWaitTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        JobWaitToolName,
+		Description: jobWaitDescription,
+		Parameters: map[string]any{
+			"job_id": map[string]any{
+				"type":        "number",
+				"description": "The ID of the background job",
+			},
+			"pattern": map[string]any{
+				"type":        "string",
+				"description": "Regular expression to wait for in the output; leave empty to wait until the job exits",
+			},
+			"timeout": map[string]any{
+				"type":        "number",
+				"description": "How many seconds to wait at most (default 60, max 600)",
+			},
+		},
+		Required: []string{"job_id"},
+		ReadOnly: true,
+	}
+}
+
+func (t *jobWaitTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params JobWaitParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	if params.Timeout <= 0 {
+		params.Timeout = defaultJobWaitTimeout
+	} else if params.Timeout > maxJobWaitTimeout {
+		params.Timeout = maxJobWaitTimeout
+	}
+	match := func(string) bool { return false }
+	if params.Pattern != "" {
+		re, err := regexp.Compile(params.Pattern)
+		if err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("invalid pattern: %s", err)), nil
+		}
+		match = re.MatchString
+	}
+
+	j, lines, err := t.jobs.Output(params.JobID)
+	if err == nil {
+		err = jobInSession(ctx, j)
+	}
+	if errors.Is(err, job.ErrNotFound) {
+		return NewTextErrorResponse(err.Error()), nil
+	} else if err != nil {
+		return ToolResponse{}, err
+	}
+	since := 0
+	if len(lines) > 0 {
+		since = lines[len(lines)-1].N
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, time.Duration(params.Timeout)*time.Second)
+	defer cancel()
+	line, j, err := t.jobs.Wait(waitCtx, params.JobID, since, match)
+	switch {
+	case err == nil && line.N > 0:
+		return NewTextResponse(fmt.Sprintf("%s\n\nMatched line %d: %s", describeJob(j), line.N, line.Text)), nil
+	case err == nil:
+		return NewTextResponse(t.withTail(describeJob(j), params.JobID)), nil
+	case ctx.Err() != nil:
+		return ToolResponse{}, ctx.Err()
+	case errors.Is(err, context.DeadlineExceeded):
+		msg := fmt.Sprintf("%s\n\nTimed out after %d seconds", describeJob(j), params.Timeout)
+		if params.Pattern != "" {
+			msg += fmt.Sprintf(" without output matching %q", params.Pattern)
+		}
+		return NewTextResponse(t.withTail(msg+".", params.JobID)), nil
+	default:
+		return ToolResponse{}, err
+	}
+}
+
+// withTail appends the last lines of output of a job to msg.
+func (t *jobWaitTool) withTail(msg string, id int) string {
+	_, lines, err := t.jobs.Output(id)
+	if err != nil || len(lines) == 0 {
+		return msg
+	}
+	if len(lines) > jobWaitContextLines {
+		lines = lines[len(lines)-jobWaitContextLines:]
+	}
+	return fmt.Sprintf("%s\n\nLast lines of output:\n%s", msg, formatJobLines(lines))
+}
@@ -0,0 +1,112 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/job"
+)
+
+const (
+	JobsToolName    = "jobs"
+	jobsDescription = `Lists the background jobs started in this session with the bash tool's is_background parameter.
+
+WHEN TO USE THIS TOOL:
+- Use to find the ID of a background job, for example a dev server you started earlier
+- Use to check whether background jobs are still running or how they exited
+
+HOW TO USE:
+- Call it without parameters
+- Each job is listed with its ID, status, PID, how long it has been running and its command
+
+TIPS:
+- Use job_output to read the output of a job, job_wait to wait for a line of its output and job_stop to stop it`
+)
+
+type JobsResponseMetadata struct {
+	Jobs []job.Job `json:"jobs"`
+}
+
+type jobsTool struct {
+	jobs job.Service
+}
+
+func NewJobsTool(jobs job.Service) BaseTool {
+	return &jobsTool{
+		jobs: jobs,
+	}
+}
+
+func (t *jobsTool) Name() string {
+	return JobsToolName
+}
+
+func (t *jobsTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        JobsToolName,
+		Description: jobsDescription,
+		Parameters:  map[string]any{},
+		Required:    []string{},
+		ReadOnly:    true,
+	}
+}
+
+func (t *jobsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	sessionID, _ := GetContextValues(ctx)
+	if sessionID == "" {
+		return ToolResponse{}, fmt.Errorf("session ID is required to list background jobs")
+	}
+
+	jobs := t.jobs.List(sessionID)
+	if len(jobs) == 0 {
+		return NewTextResponse("No background jobs were started in this session."), nil
+	}
+	var sb strings.Builder
+	for _, j := range jobs {
+		sb.WriteString(describeJob(j))
+		sb.WriteString("\n")
+	}
+	return WithResponseMetadata(
+		NewTextResponse(strings.TrimSuffix(sb.String(), "\n")),
+		JobsResponseMetadata{Jobs: jobs},
+	), nil
+}
+
+// jobInSession returns job.ErrNotFound when j was started in another session
+// than the one of ctx, whose jobs the model may not see.
+func jobInSession(ctx context.Context, j job.Job) error {
+	if sessionID, _ := GetContextValues(ctx); j.SessionID != sessionID {
+		return fmt.Errorf("%w: %d", job.ErrNotFound, j.ID)
+	}
+	return nil
+}
+
+// describeJob formats a job on one line for the model.
+func describeJob(j job.Job) string {
+	var status string
+	switch j.Status {
+	case job.StatusRunning:
+		status = fmt.Sprintf("running for %s", time.Since(j.StartedAt).Round(time.Second))
+	case job.StatusStopped:
+		status = "stopped"
+	default:
+		status = fmt.Sprintf("exited with code %d", j.ExitCode)
+	}
+	return fmt.Sprintf("Job %d (PID %d, %s): %s", j.ID, j.PID, status, j.Command)
+}
+
+// formatJobLines formats lines of output of a job for the model, numbered
+// and with lines written to stderr marked.
+func formatJobLines(lines []job.Line) string {
+	var sb strings.Builder
+	for _, line := range lines {
+		stream := ""
+		if line.Stderr {
+			stream = " [stderr]"
+		}
+		fmt.Fprintf(&sb, "%6d%s| %s\n", line.N, stream, line.Text)
+	}
+	return truncateOutput(strings.TrimSuffix(sb.String(), "\n"))
+}
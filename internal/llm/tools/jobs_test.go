@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/job"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrepJobLines(t *testing.T) {
+	t.Parallel()
+
+	lines := []job.Line{
+		{N: 1, Text: "compiling"},
+		{N: 2, Stderr: true, Text: "error: a"},
+		{N: 3, Text: "ok"},
+		{N: 4, Stderr: true, Text: "error: b"},
+		{N: 5, Stderr: true, Text: "error: c"},
+	}
+
+	matches, total := grepJobLines(lines, regexp.MustCompile("^error"), 2)
+	require.Equal(t, 3, total)
+	require.Equal(t, []job.Line{lines[3], lines[4]}, matches)
+
+	matches, total = grepJobLines(lines, regexp.MustCompile("warning"), 2)
+	require.Zero(t, total)
+	require.Empty(t, matches)
+}
+
+func TestFormatJobLines(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "     9| listening on :8080\n    10 [stderr]| GET / 404", formatJobLines([]job.Line{
+		{N: 9, Text: "listening on :8080"},
+		{N: 10, Stderr: true, Text: "GET / 404"},
+	}))
+}
+
+func TestDescribeJob(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "Job 2 (PID 42, exited with code 1): npm test", describeJob(job.Job{ID: 2, PID: 42, Status: job.StatusExited, ExitCode: 1, Command: "npm test"}))
+	require.Equal(t, "Job 3 (PID 43, stopped): npm run dev", describeJob(job.Job{ID: 3, PID: 43, Status: job.StatusStopped, ExitCode: -1, Command: "npm run dev"}))
+}
+
+func TestJobInSession(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(t.Context(), SessionIDContextKey, "session")
+	require.NoError(t, jobInSession(ctx, job.Job{ID: 1, SessionID: "session"}))
+	require.ErrorIs(t, jobInSession(ctx, job.Job{ID: 2, SessionID: "other"}), job.ErrNotFound)
+}
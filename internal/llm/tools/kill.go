@@ -83,7 +83,7 @@ func (k *killTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	if params.KillGroup && runtime.GOOS != "windows" {
 		desc += " (process group)"
 	}
-	p := k.permissions.Request(permission.CreatePermissionRequest{
+	permissionReq := permission.CreatePermissionRequest{
 		SessionID:   sessionID,
 		ToolCallID:  call.ID,
 		Path:        k.workingDir,
@@ -91,8 +91,11 @@ func (k *killTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		Action:      "execute",
 		Description: desc,
 		Params:      map[string]any{"pid": params.PID, "signal": params.Signal, "kill_group": params.KillGroup},
-	})
-	if !p {
+	}
+	if err := k.permissions.Check(permissionReq); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if !k.permissions.Request(permissionReq) {
 		return ToolResponse{}, permission.ErrorPermissionDenied
 	}
 
@@ -101,6 +101,7 @@ func (l *lsTool) Info() ToolInfo {
 			},
 		},
 		Required: []string{"path"},
+		ReadOnly: true,
 	}
 }
 
@@ -144,19 +145,20 @@ func (l *lsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 			return ToolResponse{}, fmt.Errorf("session ID and message ID are required for accessing directories outside working directory")
 		}
 
-		granted := l.permissions.Request(
-			permission.CreatePermissionRequest{
-				SessionID:   sessionID,
-				Path:        absSearchPath,
-				ToolCallID:  call.ID,
-				ToolName:    LSToolName,
-				Action:      "list",
-				Description: fmt.Sprintf("List directory outside working directory: %s", absSearchPath),
-				Params:      LSPermissionsParams(params),
-			},
-		)
-
-		if !granted {
+		permissionReq := permission.CreatePermissionRequest{
+			SessionID:   sessionID,
+			Path:        absSearchPath,
+			FilePath:    absSearchPath,
+			ToolCallID:  call.ID,
+			ToolName:    LSToolName,
+			Action:      "list",
+			Description: fmt.Sprintf("List directory outside working directory: %s", absSearchPath),
+			Params:      LSPermissionsParams(params),
+		}
+		if err := l.permissions.Check(permissionReq); err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+		if !l.permissions.Request(permissionReq) {
 			return ToolResponse{}, permission.ErrorPermissionDenied
 		}
 	}
@@ -251,9 +251,10 @@ func (m *multiEditTool) processMultiEditWithCreation(ctx context.Context, params
 	// Check permissions
 	_, additions, removals := diff.GenerateDiff("", currentContent, strings.TrimPrefix(params.FilePath, m.workingDir))
 
-	p := m.permissions.Request(permission.CreatePermissionRequest{
+	permissionReq := permission.CreatePermissionRequest{
 		SessionID:   sessionID,
 		Path:        fsext.PathOrPrefix(params.FilePath, m.workingDir),
+		FilePath:    params.FilePath,
 		ToolCallID:  call.ID,
 		ToolName:    MultiEditToolName,
 		Action:      "write",
@@ -263,8 +264,11 @@ func (m *multiEditTool) processMultiEditWithCreation(ctx context.Context, params
 			OldContent: "",
 			NewContent: currentContent,
 		},
-	})
-	if !p {
+	}
+	if err := m.permissions.Check(permissionReq); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if !m.permissions.Request(permissionReq) {
 		return ToolResponse{}, permission.ErrorPermissionDenied
 	}
 
@@ -274,13 +278,18 @@ func (m *multiEditTool) processMultiEditWithCreation(ctx context.Context, params
 		return ToolResponse{}, fmt.Errorf("failed to write file: %w", err)
 	}
 
+	currentContent, formatNote := formatOnWrite(ctx, m.lspClients, m.workingDir, params.FilePath, currentContent)
+	if formatNote != "" {
+		_, additions, removals = diff.GenerateDiff("", currentContent, strings.TrimPrefix(params.FilePath, m.workingDir))
+	}
+
 	// Update file history
-	_, err = m.files.Create(ctx, sessionID, params.FilePath, "")
+	_, err = m.files.CreateNew(ctx, sessionID, messageID, params.FilePath)
 	if err != nil {
 		return ToolResponse{}, fmt.Errorf("error creating file history: %w", err)
 	}
 
-	_, err = m.files.CreateVersion(ctx, sessionID, params.FilePath, currentContent)
+	_, err = m.files.CreateVersion(ctx, sessionID, messageID, params.FilePath, currentContent)
 	if err != nil {
 		slog.Debug("Error creating file history version", "error", err)
 	}
@@ -289,7 +298,7 @@ func (m *multiEditTool) processMultiEditWithCreation(ctx context.Context, params
 	recordFileRead(params.FilePath)
 
 	return WithResponseMetadata(
-		NewTextResponse(fmt.Sprintf("File created with %d edits: %s", len(params.Edits), params.FilePath)),
+		NewTextResponse(fmt.Sprintf("File created with %d edits: %s", len(params.Edits), params.FilePath)+formatNote),
 		MultiEditResponseMetadata{
 			OldContent:   "",
 			NewContent:   currentContent,
@@ -360,9 +369,10 @@ func (m *multiEditTool) processMultiEditExistingFile(ctx context.Context, params
 
 	// Generate diff and check permissions
 	_, additions, removals := diff.GenerateDiff(oldContent, currentContent, strings.TrimPrefix(params.FilePath, m.workingDir))
-	p := m.permissions.Request(permission.CreatePermissionRequest{
+	permissionReq := permission.CreatePermissionRequest{
 		SessionID:   sessionID,
 		Path:        fsext.PathOrPrefix(params.FilePath, m.workingDir),
+		FilePath:    params.FilePath,
 		ToolCallID:  call.ID,
 		ToolName:    MultiEditToolName,
 		Action:      "write",
@@ -372,8 +382,11 @@ func (m *multiEditTool) processMultiEditExistingFile(ctx context.Context, params
 			OldContent: oldContent,
 			NewContent: currentContent,
 		},
-	})
-	if !p {
+	}
+	if err := m.permissions.Check(permissionReq); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if !m.permissions.Request(permissionReq) {
 		return ToolResponse{}, permission.ErrorPermissionDenied
 	}
 
@@ -387,24 +400,29 @@ func (m *multiEditTool) processMultiEditExistingFile(ctx context.Context, params
 		return ToolResponse{}, fmt.Errorf("failed to write file: %w", err)
 	}
 
+	currentContent, formatNote := formatOnWrite(ctx, m.lspClients, m.workingDir, params.FilePath, currentContent)
+	if formatNote != "" {
+		_, additions, removals = diff.GenerateDiff(oldContent, currentContent, strings.TrimPrefix(params.FilePath, m.workingDir))
+	}
+
 	// Update file history
 	file, err := m.files.GetByPathAndSession(ctx, params.FilePath, sessionID)
 	if err != nil {
-		_, err = m.files.Create(ctx, sessionID, params.FilePath, oldContent)
+		_, err = m.files.Create(ctx, sessionID, messageID, params.FilePath, oldContent)
 		if err != nil {
 			return ToolResponse{}, fmt.Errorf("error creating file history: %w", err)
 		}
 	}
 	if file.Content != oldContent {
 		// User manually changed the content, store an intermediate version
-		_, err = m.files.CreateVersion(ctx, sessionID, params.FilePath, oldContent)
+		_, err = m.files.CreateVersion(ctx, sessionID, messageID, params.FilePath, oldContent)
 		if err != nil {
 			slog.Debug("Error creating file history version", "error", err)
 		}
 	}
 
 	// Store the new version
-	_, err = m.files.CreateVersion(ctx, sessionID, params.FilePath, currentContent)
+	_, err = m.files.CreateVersion(ctx, sessionID, messageID, params.FilePath, currentContent)
 	if err != nil {
 		slog.Debug("Error creating file history version", "error", err)
 	}
@@ -413,7 +431,7 @@ func (m *multiEditTool) processMultiEditExistingFile(ctx context.Context, params
 	recordFileRead(params.FilePath)
 
 	return WithResponseMetadata(
-		NewTextResponse(fmt.Sprintf("Applied %d edits to file: %s", len(params.Edits), params.FilePath)),
+		NewTextResponse(fmt.Sprintf("Applied %d edits to file: %s", len(params.Edits), params.FilePath)+formatNote),
 		MultiEditResponseMetadata{
 			OldContent:   oldContent,
 			NewContent:   currentContent,
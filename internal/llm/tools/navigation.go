@@ -0,0 +1,244 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+)
+
+// maxNavigationResults caps the locations and symbols the navigation tools
+// list, so a common name doesn't flood the context.
+const maxNavigationResults = 100
+
+// NavigationParams point the definition, references and hover tools at a
+// symbol: the file and line it appears on, and its name.
+type NavigationParams struct {
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+	Symbol   string `json:"symbol"`
+}
+
+func navigationParameters() map[string]any {
+	return map[string]any{
+		"file_path": map[string]any{
+			"type":        "string",
+			"description": "The path to the file the symbol appears in",
+		},
+		"line": map[string]any{
+			"type":        "integer",
+			"description": "The line number the symbol appears on, starting at 1",
+		},
+		"symbol": map[string]any{
+			"type":        "string",
+			"description": "The name of the symbol, exactly as it appears on the line",
+		},
+	}
+}
+
+// navigationClient returns the LSP client, in order of name, that handles
+// filePath and supports method.
+func navigationClient(lspClients map[string]*lsp.Client, filePath, method string) *lsp.Client {
+	names := make([]string, 0, len(lspClients))
+	for name := range lspClients {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		client := lspClients[name]
+		if client.HandlesFile(filePath) && client.IsMethodSupported(method) {
+			return client
+		}
+	}
+	return nil
+}
+
+// absPath resolves path against workingDir.
+func absPath(workingDir, path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workingDir, path)
+	}
+	return filepath.Clean(path)
+}
+
+// workingDirFile resolves path against workingDir and errors when the file
+// is outside of it. The navigation tools only read files of the project.
+func workingDirFile(workingDir, path string) (string, error) {
+	filePath := absPath(workingDir, path)
+	rel, err := filepath.Rel(absPath(workingDir, "."), filePath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("%s is outside the working directory", path)
+	}
+	return filePath, nil
+}
+
+// symbolPosition returns where symbol appears on line, counted from 1, of
+// content. Whole-word matches are preferred over matches inside longer
+// identifiers. Positions count lines from 0 and characters in UTF-16 code
+// units, like the LSP does.
+func symbolPosition(content string, line int, symbol string) (protocol.Position, error) {
+	lines := strings.Split(content, "\n")
+	if line < 1 || line > len(lines) {
+		return protocol.Position{}, fmt.Errorf("line %d is out of range, the file has %d lines", line, len(lines))
+	}
+	text := strings.TrimSuffix(lines[line-1], "\r")
+	if symbol == "" {
+		return protocol.Position{}, fmt.Errorf("symbol is required")
+	}
+
+	column := -1
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], symbol)
+		if i == -1 {
+			break
+		}
+		i += offset
+		if column == -1 {
+			column = i
+		}
+		before, _ := utf8.DecodeLastRuneInString(text[:i])
+		after, _ := utf8.DecodeRuneInString(text[i+len(symbol):])
+		if !isIdentRune(before) && !isIdentRune(after) {
+			column = i
+			break
+		}
+		offset = i + len(symbol)
+	}
+	if column == -1 {
+		return protocol.Position{}, fmt.Errorf("symbol %q not found on line %d: %s", symbol, line, strings.TrimSpace(text))
+	}
+	return protocol.Position{
+		Line:      uint32(line - 1),
+		Character: uint32(len(utf16.Encode([]rune(text[:column])))),
+	}, nil
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// resolveSymbol opens the file of params in the LSP client that handles it
+// and supports method, and returns where the symbol is in that file. Errors
+// are meant for the model.
+func resolveSymbol(ctx context.Context, lspClients map[string]*lsp.Client, workingDir string, params NavigationParams, method string) (*lsp.Client, protocol.TextDocumentPositionParams, error) {
+	if params.FilePath == "" {
+		return nil, protocol.TextDocumentPositionParams{}, fmt.Errorf("file_path is required")
+	}
+	filePath, err := workingDirFile(workingDir, params.FilePath)
+	if err != nil {
+		return nil, protocol.TextDocumentPositionParams{}, err
+	}
+	client := navigationClient(lspClients, filePath, method)
+	if client == nil {
+		return nil, protocol.TextDocumentPositionParams{}, fmt.Errorf("no LSP client supports %s for %s", method, params.FilePath)
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, protocol.TextDocumentPositionParams{}, fmt.Errorf("error reading file: %w", err)
+	}
+	position, err := symbolPosition(string(content), params.Line, params.Symbol)
+	if err != nil {
+		return nil, protocol.TextDocumentPositionParams{}, err
+	}
+	if err := client.OpenFile(ctx, filePath); err != nil {
+		return nil, protocol.TextDocumentPositionParams{}, fmt.Errorf("error opening file in the LSP: %w", err)
+	}
+	return client, protocol.TextDocumentPositionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: protocol.URIFromPath(filePath)},
+		Position:     position,
+	}, nil
+}
+
+// definitionLocations flattens the different shapes of a definition or
+// implementation result into locations.
+func definitionLocations(value any) []protocol.Location {
+	switch v := value.(type) {
+	case protocol.Definition:
+		return definitionLocations(v.Value)
+	case protocol.Location:
+		return []protocol.Location{v}
+	case []protocol.Location:
+		return v
+	case []protocol.DefinitionLink:
+		locations := make([]protocol.Location, len(v))
+		for i, link := range v {
+			locations[i] = protocol.Location{URI: link.TargetURI, Range: link.TargetSelectionRange}
+		}
+		return locations
+	}
+	return nil
+}
+
+// snippets reads the files locations point at, reading each file once.
+type snippets struct {
+	workingDir string
+	files      map[protocol.DocumentURI][]string
+}
+
+func newSnippets(workingDir string) *snippets {
+	return &snippets{
+		workingDir: workingDir,
+		files:      make(map[protocol.DocumentURI][]string),
+	}
+}
+
+func (s *snippets) lines(uri protocol.DocumentURI) []string {
+	if lines, ok := s.files[uri]; ok {
+		return lines
+	}
+	var lines []string
+	if path, err := uri.Path(); err == nil {
+		if content, err := os.ReadFile(path); err == nil {
+			lines = strings.Split(string(content), "\n")
+		}
+	}
+	s.files[uri] = lines
+	return lines
+}
+
+// path returns the path of uri relative to the working directory when it is
+// inside of it.
+func (s *snippets) path(uri protocol.DocumentURI) string {
+	path, err := uri.Path()
+	if err != nil {
+		return string(uri)
+	}
+	if rel, err := filepath.Rel(s.workingDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// position formats where loc starts as path:line:column, counted from 1.
+func (s *snippets) position(loc protocol.Location) string {
+	return fmt.Sprintf("%s:%d:%d", s.path(loc.URI), loc.Range.Start.Line+1, loc.Range.Start.Character+1)
+}
+
+// line returns the trimmed text of the line loc starts on.
+func (s *snippets) line(loc protocol.Location) string {
+	lines := s.lines(loc.URI)
+	if int(loc.Range.Start.Line) >= len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(lines[loc.Range.Start.Line])
+}
+
+// block returns the lines from before to after lines around the start of
+// loc, with line numbers.
+func (s *snippets) block(loc protocol.Location, before, after int) string {
+	lines := s.lines(loc.URI)
+	start := max(0, int(loc.Range.Start.Line)-before)
+	end := min(len(lines), int(loc.Range.Start.Line)+after+1)
+	if start >= end {
+		return ""
+	}
+	return addLineNumbers(strings.Join(lines[start:end], "\n"), start+1)
+}
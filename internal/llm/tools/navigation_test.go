@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSymbolPosition(t *testing.T) {
+	t.Parallel()
+
+	content := "package main\n\nfunc newServer() *Server {\n\treturn &Server{name: \"héllo\", server: nil}\n}\n"
+
+	t.Run("whole word", func(t *testing.T) {
+		t.Parallel()
+		pos, err := symbolPosition(content, 3, "Server")
+		require.NoError(t, err)
+		require.Equal(t, protocol.Position{Line: 2, Character: 18}, pos)
+	})
+
+	t.Run("inside an identifier", func(t *testing.T) {
+		t.Parallel()
+		pos, err := symbolPosition(content, 3, "Serv")
+		require.NoError(t, err)
+		require.Equal(t, protocol.Position{Line: 2, Character: 8}, pos)
+	})
+
+	t.Run("utf-16 columns", func(t *testing.T) {
+		t.Parallel()
+		pos, err := symbolPosition(content, 4, "server")
+		require.NoError(t, err)
+		require.Equal(t, protocol.Position{Line: 3, Character: 31}, pos)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		_, err := symbolPosition(content, 1, "Server")
+		require.ErrorContains(t, err, "not found on line 1: package main")
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		t.Parallel()
+		_, err := symbolPosition(content, 42, "Server")
+		require.ErrorContains(t, err, "out of range")
+	})
+}
+
+func TestDefinitionLocations(t *testing.T) {
+	t.Parallel()
+
+	loc := protocol.Location{URI: "file:///a.go", Range: protocol.Range{Start: protocol.Position{Line: 3}}}
+	require.Equal(t, []protocol.Location{loc}, definitionLocations(protocol.Definition{Value: loc}))
+	require.Equal(t, []protocol.Location{loc}, definitionLocations(protocol.Definition{Value: []protocol.Location{loc}}))
+	require.Equal(t, []protocol.Location{loc}, definitionLocations([]protocol.DefinitionLink{{
+		TargetURI:            loc.URI,
+		TargetRange:          protocol.Range{End: protocol.Position{Line: 10}},
+		TargetSelectionRange: loc.Range,
+	}}))
+	require.Empty(t, definitionLocations(nil))
+}
+
+func TestWorkingDirFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path, err := workingDirFile(dir, "main.go")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "main.go"), path)
+
+	_, err = workingDirFile(dir, "../secret.go")
+	require.Error(t, err)
+	_, err = workingDirFile(dir, "/etc/passwd")
+	require.Error(t, err)
+
+	_, _, err = resolveSymbol(t.Context(), nil, dir, NavigationParams{FilePath: "/etc/passwd", Line: 1, Symbol: "root"}, "textDocument/hover")
+	require.ErrorContains(t, err, "outside the working directory")
+}
+
+func TestSnippets(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n\nfunc main() {\n\tprintln(1)\n}\n"), 0o644))
+
+	s := newSnippets(dir)
+	loc := protocol.Location{
+		URI:   protocol.URIFromPath(path),
+		Range: protocol.Range{Start: protocol.Position{Line: 3, Character: 1}},
+	}
+	require.Equal(t, "main.go:4:2", s.position(loc))
+	require.Equal(t, "println(1)", s.line(loc))
+	require.Equal(t, "     3|func main() {\n     4|\tprintln(1)\n     5|}", s.block(loc, 1, 1))
+}
+
+func TestOutline(t *testing.T) {
+	t.Parallel()
+
+	symbols := []protocol.DocumentSymbol{{
+		Name:   "Server",
+		Kind:   protocol.Struct,
+		Detail: "struct{...}",
+		Range:  protocol.Range{Start: protocol.Position{Line: 2}, End: protocol.Position{Line: 5}},
+		Children: []protocol.DocumentSymbol{{
+			Name:  "name",
+			Kind:  protocol.Field,
+			Range: protocol.Range{Start: protocol.Position{Line: 3}, End: protocol.Position{Line: 3}},
+		}},
+	}}
+	require.Equal(t, []string{
+		"Struct Server (lines 3-6) struct{...}",
+		"  Field name (line 4)",
+	}, outline(symbols, 0, nil))
+}
@@ -0,0 +1,555 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/charmbracelet/crush/internal/diff"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+	"github.com/charmbracelet/crush/internal/lsp/util"
+	"github.com/charmbracelet/crush/internal/permission"
+)
+
+type RefactorParams struct {
+	Operation string `json:"operation"`
+	FilePath  string `json:"file_path"`
+	Line      int    `json:"line"`
+	EndLine   int    `json:"end_line,omitempty"`
+	Symbol    string `json:"symbol,omitempty"`
+	NewName   string `json:"new_name,omitempty"`
+	Action    string `json:"action,omitempty"`
+}
+
+// RefactorFileChange is the content of a file before and after a refactoring.
+type RefactorFileChange struct {
+	FilePath   string `json:"file_path"`
+	OldContent string `json:"old_content,omitempty"`
+	NewContent string `json:"new_content,omitempty"`
+}
+
+type RefactorPermissionsParams struct {
+	Files []RefactorFileChange `json:"files"`
+}
+
+type RefactorResponseMetadata struct {
+	Files     []RefactorFileChange `json:"files"`
+	Additions int                  `json:"additions"`
+	Removals  int                  `json:"removals"`
+}
+
+type refactorTool struct {
+	lspClients  map[string]*lsp.Client
+	permissions permission.Service
+	files       history.Service
+	workingDir  string
+}
+
+const (
+	RefactorToolName    = "refactor"
+	refactorDescription = `Renames symbols and applies code actions across the project, using the language server of the file.
+WHEN TO USE THIS TOOL:
+- Use to rename a function, method, type, field or variable: every reference in every file is updated, unlike with edit or multiedit
+- Use to run the code actions of the language server, like organizing imports, filling a struct literal, extracting a function or applying a quick fix for a diagnostic
+HOW TO USE:
+- Set operation to "rename" or "code_action"
+- For rename, give the file, the line and the symbol as it appears on that line, and new_name
+- For code_action, give the file and line, and either the symbol the action applies to or end_line to select whole lines
+- Leave action empty to list the code actions available there, then call again with action set to the kind (e.g. "source.organizeImports", "refactor.extract") or part of the title of the one to apply
+- The changes are shown as a diff for approval before they are written
+LIMITATIONS:
+- Only works for files handled by a configured LSP
+- Code actions that only run a command on the language server, and edits that create, rename or delete files, are not supported
+- Which code actions exist depends on the language server and on what is selected
+TIPS:
+- Use the references tool first to see what a rename will touch
+- Check the diagnostics in the result: a rename can still clash with an existing name`
+)
+
+func NewRefactorTool(lspClients map[string]*lsp.Client, permissions permission.Service, files history.Service, workingDir string) BaseTool {
+	return &refactorTool{
+		lspClients:  lspClients,
+		permissions: permissions,
+		files:       files,
+		workingDir:  workingDir,
+	}
+}
+
+func (r *refactorTool) Name() string {
+	return RefactorToolName
+}
+
+func (r *refactorTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        RefactorToolName,
+		Description: refactorDescription,
+		Parameters: map[string]any{
+			"operation": map[string]any{
+				"type":        "string",
+				"description": "The refactoring to do",
+				"enum":        []string{"rename", "code_action"},
+			},
+			"file_path": map[string]any{
+				"type":        "string",
+				"description": "The path to the file the symbol or code appears in",
+			},
+			"line": map[string]any{
+				"type":        "integer",
+				"description": "The line number the symbol appears on, or the first line of the code, starting at 1",
+			},
+			"end_line": map[string]any{
+				"type":        "integer",
+				"description": "The last line of the code a code action applies to, when no symbol is given (defaults to line)",
+			},
+			"symbol": map[string]any{
+				"type":        "string",
+				"description": "The name of the symbol, exactly as it appears on the line (required for rename)",
+			},
+			"new_name": map[string]any{
+				"type":        "string",
+				"description": "The new name of the symbol (required for rename)",
+			},
+			"action": map[string]any{
+				"type":        "string",
+				"description": "The kind or part of the title of the code action to apply; leave empty to list the available actions",
+			},
+		},
+		Required: []string{"operation", "file_path", "line"},
+	}
+}
+
+func (r *refactorTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params RefactorParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+	if params.FilePath == "" {
+		return NewTextErrorResponse("file_path is required"), nil
+	}
+
+	var (
+		edit        protocol.WorkspaceEdit
+		description string
+		err         error
+	)
+	switch params.Operation {
+	case "rename":
+		edit, description, err = r.rename(ctx, params)
+	case "code_action":
+		if params.Action == "" {
+			return r.listActions(ctx, params)
+		}
+		edit, description, err = r.codeAction(ctx, params)
+	default:
+		return NewTextErrorResponse(`operation must be "rename" or "code_action"`), nil
+	}
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	response, err := r.apply(ctx, call, params, edit, description)
+	if err != nil || response.IsError {
+		return response, err
+	}
+
+	filePath := absPath(r.workingDir, params.FilePath)
+	waitForLspDiagnostics(ctx, filePath, r.lspClients)
+	text := fmt.Sprintf("<result>\n%s\n</result>\n", response.Content)
+	text += getDiagnostics(filePath, r.lspClients)
+	response.Content = text
+	return response, nil
+}
+
+func (r *refactorTool) rename(ctx context.Context, params RefactorParams) (protocol.WorkspaceEdit, string, error) {
+	if params.Symbol == "" || params.NewName == "" {
+		return protocol.WorkspaceEdit{}, "", fmt.Errorf("symbol and new_name are required for rename")
+	}
+	client, position, err := resolveSymbol(ctx, r.lspClients, r.workingDir, NavigationParams{
+		FilePath: params.FilePath,
+		Line:     params.Line,
+		Symbol:   params.Symbol,
+	}, "textDocument/rename")
+	if err != nil {
+		return protocol.WorkspaceEdit{}, "", err
+	}
+
+	if client.IsMethodSupported("textDocument/prepareRename") {
+		result, err := client.PrepareRename(ctx, protocol.PrepareRenameParams{TextDocumentPositionParams: position})
+		if err != nil {
+			return protocol.WorkspaceEdit{}, "", fmt.Errorf("cannot rename %s: %w", params.Symbol, err)
+		}
+		if result.Value == nil {
+			return protocol.WorkspaceEdit{}, "", fmt.Errorf("cannot rename %s: the language server does not allow renaming it", params.Symbol)
+		}
+	}
+
+	edit, err := client.Rename(ctx, protocol.RenameParams{
+		TextDocument: position.TextDocument,
+		Position:     position.Position,
+		NewName:      params.NewName,
+	})
+	if err != nil {
+		return protocol.WorkspaceEdit{}, "", fmt.Errorf("error renaming %s: %w", params.Symbol, err)
+	}
+	return edit, fmt.Sprintf("Rename %s to %s", params.Symbol, params.NewName), nil
+}
+
+// codeActions asks the language server of the file of params for the code
+// actions at the symbol or lines params select.
+func (r *refactorTool) codeActions(ctx context.Context, params RefactorParams) (*lsp.Client, []protocol.CodeAction, error) {
+	filePath, err := workingDirFile(r.workingDir, params.FilePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	client := navigationClient(r.lspClients, filePath, "textDocument/codeAction")
+	if client == nil {
+		return nil, nil, fmt.Errorf("no LSP client supports textDocument/codeAction for %s", params.FilePath)
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading file: %w", err)
+	}
+	rng, err := actionRange(string(content), params)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := client.OpenFile(ctx, filePath); err != nil {
+		return nil, nil, fmt.Errorf("error opening file in the LSP: %w", err)
+	}
+
+	uri := protocol.URIFromPath(filePath)
+	var only []protocol.CodeActionKind
+	if isCodeActionKind(params.Action) {
+		only = []protocol.CodeActionKind{protocol.CodeActionKind(params.Action)}
+	}
+	result, err := client.CodeAction(ctx, protocol.CodeActionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Range:        rng,
+		Context: protocol.CodeActionContext{
+			Diagnostics: diagnosticsInRange(client.GetFileDiagnostics(uri), rng),
+			Only:        only,
+		},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listing code actions: %w", err)
+	}
+
+	actions := make([]protocol.CodeAction, 0, len(result))
+	for _, item := range result {
+		switch v := item.Value.(type) {
+		case protocol.CodeAction:
+			actions = append(actions, v)
+		case protocol.Command:
+			actions = append(actions, protocol.CodeAction{Title: v.Title, Command: &v})
+		}
+	}
+	return client, actions, nil
+}
+
+func (r *refactorTool) listActions(ctx context.Context, params RefactorParams) (ToolResponse, error) {
+	_, actions, err := r.codeActions(ctx, params)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if len(actions) == 0 {
+		return NewTextResponse(fmt.Sprintf("No code actions available at %s:%d", params.FilePath, params.Line)), nil
+	}
+	return NewTextResponse(fmt.Sprintf("Code actions available at %s:%d:\n\n%s", params.FilePath, params.Line, formatActions(actions))), nil
+}
+
+func (r *refactorTool) codeAction(ctx context.Context, params RefactorParams) (protocol.WorkspaceEdit, string, error) {
+	client, actions, err := r.codeActions(ctx, params)
+	if err != nil {
+		return protocol.WorkspaceEdit{}, "", err
+	}
+	action, err := matchAction(actions, params.Action)
+	if err != nil {
+		return protocol.WorkspaceEdit{}, "", err
+	}
+	if action.Disabled != nil {
+		return protocol.WorkspaceEdit{}, "", fmt.Errorf("code action %q is disabled: %s", action.Title, action.Disabled.Reason)
+	}
+
+	// Servers may leave the edit out of the list and fill it in when the
+	// action is resolved.
+	if action.Edit == nil && action.Data != nil && client.IsMethodSupported("codeAction/resolve") {
+		resolved, err := client.ResolveCodeAction(ctx, action)
+		if err != nil {
+			return protocol.WorkspaceEdit{}, "", fmt.Errorf("error resolving code action %q: %w", action.Title, err)
+		}
+		action = resolved
+	}
+	if action.Edit == nil {
+		if action.Command != nil {
+			return protocol.WorkspaceEdit{}, "", fmt.Errorf("code action %q only runs the %s command on the language server, which is not supported", action.Title, action.Command.Command)
+		}
+		return protocol.WorkspaceEdit{}, "", fmt.Errorf("code action %q has no changes to apply", action.Title)
+	}
+	if action.Command != nil {
+		slog.Debug("Skipping command of code action", "title", action.Title, "command", action.Command.Command)
+	}
+	return *action.Edit, action.Title, nil
+}
+
+// apply writes the changes of edit after asking for permission, and records
+// the old and new content of each file in the history.
+func (r *refactorTool) apply(ctx context.Context, call ToolCall, params RefactorParams, edit protocol.WorkspaceEdit, description string) (ToolResponse, error) {
+	changes, err := util.WorkspaceTextEdits(edit)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	uris := make([]protocol.DocumentURI, 0, len(changes))
+	for uri := range changes {
+		uris = append(uris, uri)
+	}
+	slices.Sort(uris)
+
+	var (
+		files     []RefactorFileChange
+		contents  []string
+		diffs     []string
+		additions int
+		removals  int
+	)
+	for _, uri := range uris {
+		path, err := uri.Path()
+		if err != nil {
+			return ToolResponse{}, fmt.Errorf("invalid URI %s: %w", uri, err)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return ToolResponse{}, fmt.Errorf("failed to read file: %w", err)
+		}
+		newContent, err := util.ApplyTextEdits(string(content), changes[uri])
+		if err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error applying the changes to %s: %s", path, err)), nil
+		}
+		oldContent, _ := fsext.ToUnixLineEndings(string(content))
+		unixContent, _ := fsext.ToUnixLineEndings(newContent)
+		if oldContent == unixContent {
+			continue
+		}
+
+		d, a, rm := diff.GenerateDiff(oldContent, unixContent, strings.TrimPrefix(path, r.workingDir))
+		files = append(files, RefactorFileChange{
+			FilePath:   path,
+			OldContent: oldContent,
+			NewContent: unixContent,
+		})
+		contents = append(contents, newContent)
+		diffs = append(diffs, d)
+		additions += a
+		removals += rm
+	}
+	if len(files) == 0 {
+		return NewTextErrorResponse(fmt.Sprintf("%s made no changes", description)), nil
+	}
+
+	sessionID, messageID := GetContextValues(ctx)
+	if sessionID == "" || messageID == "" {
+		return ToolResponse{}, fmt.Errorf("session ID and message ID are required for refactoring")
+	}
+
+	filePath := absPath(r.workingDir, params.FilePath)
+	permissionReq := permission.CreatePermissionRequest{
+		SessionID:   sessionID,
+		Path:        fsext.PathOrPrefix(filePath, r.workingDir),
+		FilePath:    filePath,
+		ToolCallID:  call.ID,
+		ToolName:    RefactorToolName,
+		Action:      "write",
+		Description: fmt.Sprintf("%s (%s)", description, fileCount(len(files))),
+		Params:      RefactorPermissionsParams{Files: files},
+	}
+	// Rules are checked against every file the refactoring touches, but the
+	// user is asked once for all of them.
+	for _, file := range files {
+		req := permissionReq
+		req.Path = fsext.PathOrPrefix(file.FilePath, r.workingDir)
+		req.FilePath = file.FilePath
+		if err := r.permissions.Check(req); err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+	}
+	if !r.permissions.Request(permissionReq) {
+		return ToolResponse{}, permission.ErrorPermissionDenied
+	}
+
+	for i, file := range files {
+		if err := os.WriteFile(file.FilePath, []byte(contents[i]), 0o644); err != nil {
+			return ToolResponse{}, fmt.Errorf("failed to write file: %w", err)
+		}
+		if err := r.recordHistory(ctx, sessionID, messageID, file); err != nil {
+			return ToolResponse{}, err
+		}
+		recordFileWrite(file.FilePath)
+		recordFileRead(file.FilePath)
+		if file.FilePath != filePath {
+			r.notifyChange(ctx, file.FilePath)
+		}
+	}
+
+	return WithResponseMetadata(
+		NewTextResponse(fmt.Sprintf("%s: changed %s\n\n%s", description, fileCount(len(files)), strings.Join(diffs, "\n"))),
+		RefactorResponseMetadata{
+			Files:     files,
+			Additions: additions,
+			Removals:  removals,
+		},
+	), nil
+}
+
+func (r *refactorTool) recordHistory(ctx context.Context, sessionID, messageID string, change RefactorFileChange) error {
+	file, err := r.files.GetByPathAndSession(ctx, change.FilePath, sessionID)
+	if err != nil {
+		_, err = r.files.Create(ctx, sessionID, messageID, change.FilePath, change.OldContent)
+		if err != nil {
+			return fmt.Errorf("error creating file history: %w", err)
+		}
+	}
+	if file.Content != change.OldContent {
+		// User manually changed the content, store an intermediate version
+		_, err = r.files.CreateVersion(ctx, sessionID, messageID, change.FilePath, change.OldContent)
+		if err != nil {
+			slog.Debug("Error creating file history version", "error", err)
+		}
+	}
+
+	// Store the new version
+	_, err = r.files.CreateVersion(ctx, sessionID, messageID, change.FilePath, change.NewContent)
+	if err != nil {
+		slog.Debug("Error creating file history version", "error", err)
+	}
+	return nil
+}
+
+// notifyChange tells the LSP clients that have path open that it changed, so
+// their view of the project stays in sync with the files on disk.
+func (r *refactorTool) notifyChange(ctx context.Context, path string) {
+	for name, client := range r.lspClients {
+		if !client.IsFileOpen(path) {
+			continue
+		}
+		if err := client.NotifyChange(ctx, path); err != nil {
+			slog.Debug("Error notifying LSP of change", "lsp", name, "path", path, "error", err)
+		}
+	}
+}
+
+// actionRange returns the range a code action applies to: the symbol on line
+// when there is one, otherwise the whole lines from line to end_line.
+func actionRange(content string, params RefactorParams) (protocol.Range, error) {
+	if params.Symbol != "" {
+		start, err := symbolPosition(content, params.Line, params.Symbol)
+		if err != nil {
+			return protocol.Range{}, err
+		}
+		end := start
+		end.Character += uint32(len(utf16.Encode([]rune(params.Symbol))))
+		return protocol.Range{Start: start, End: end}, nil
+	}
+
+	lines := strings.Split(content, "\n")
+	endLine := max(params.EndLine, params.Line)
+	if params.Line < 1 || endLine > len(lines) {
+		return protocol.Range{}, fmt.Errorf("lines %d-%d are out of range, the file has %d lines", params.Line, endLine, len(lines))
+	}
+	last := strings.TrimSuffix(lines[endLine-1], "\r")
+	return protocol.Range{
+		Start: protocol.Position{Line: uint32(params.Line - 1)},
+		End:   protocol.Position{Line: uint32(endLine - 1), Character: uint32(len(utf16.Encode([]rune(last))))},
+	}, nil
+}
+
+// diagnosticsInRange returns the diagnostics on the lines of rng, which the
+// server needs to offer quick fixes for them.
+func diagnosticsInRange(diagnostics []protocol.Diagnostic, rng protocol.Range) []protocol.Diagnostic {
+	result := []protocol.Diagnostic{}
+	for _, d := range diagnostics {
+		if d.Range.Start.Line <= rng.End.Line && d.Range.End.Line >= rng.Start.Line {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// isCodeActionKind reports whether action looks like a code action kind,
+// such as "quickfix" or "source.organizeImports", rather than a title.
+func isCodeActionKind(action string) bool {
+	if action == "" || strings.ContainsFunc(action, unicode.IsSpace) {
+		return false
+	}
+	switch protocol.CodeActionKind(action) {
+	case protocol.QuickFix, protocol.Refactor, protocol.Source:
+		return true
+	}
+	return strings.Contains(action, ".")
+}
+
+// matchAction picks the code action whose kind is, or is nested in, action,
+// or whose title contains it. When several match, the preferred one wins.
+func matchAction(actions []protocol.CodeAction, action string) (protocol.CodeAction, error) {
+	var matches []protocol.CodeAction
+	for _, a := range actions {
+		kind := string(a.Kind)
+		if kind == action || strings.HasPrefix(kind, action+".") ||
+			strings.Contains(strings.ToLower(a.Title), strings.ToLower(action)) {
+			matches = append(matches, a)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		if len(actions) == 0 {
+			return protocol.CodeAction{}, fmt.Errorf("no code actions available here")
+		}
+		return protocol.CodeAction{}, fmt.Errorf("no code action matches %q, the available actions are:\n%s", action, formatActions(actions))
+	case 1:
+		return matches[0], nil
+	}
+	var preferred []protocol.CodeAction
+	for _, a := range matches {
+		if a.IsPreferred {
+			preferred = append(preferred, a)
+		}
+	}
+	if len(preferred) == 1 {
+		return preferred[0], nil
+	}
+	return protocol.CodeAction{}, fmt.Errorf("%d code actions match %q, use a more specific action:\n%s", len(matches), action, formatActions(matches))
+}
+
+func formatActions(actions []protocol.CodeAction) string {
+	lines := make([]string, len(actions))
+	for i, a := range actions {
+		line := "- " + a.Title
+		if a.Kind != "" {
+			line += fmt.Sprintf(" (%s)", a.Kind)
+		}
+		if a.IsPreferred {
+			line += " [preferred]"
+		}
+		if a.Disabled != nil {
+			line += " [disabled: " + a.Disabled.Reason + "]"
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func fileCount(n int) string {
+	if n == 1 {
+		return "1 file"
+	}
+	return fmt.Sprintf("%d files", n)
+}
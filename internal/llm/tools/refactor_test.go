@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionRange(t *testing.T) {
+	t.Parallel()
+
+	content := "package main\n\nfunc main() {\n\ts := \"héllo\"\n\tprintln(s)\n}\n"
+
+	t.Run("symbol", func(t *testing.T) {
+		t.Parallel()
+		rng, err := actionRange(content, RefactorParams{Line: 5, Symbol: "println"})
+		require.NoError(t, err)
+		require.Equal(t, protocol.Range{
+			Start: protocol.Position{Line: 4, Character: 1},
+			End:   protocol.Position{Line: 4, Character: 8},
+		}, rng)
+	})
+
+	t.Run("lines", func(t *testing.T) {
+		t.Parallel()
+		rng, err := actionRange(content, RefactorParams{Line: 4, EndLine: 5})
+		require.NoError(t, err)
+		require.Equal(t, protocol.Range{
+			Start: protocol.Position{Line: 3},
+			End:   protocol.Position{Line: 4, Character: 11},
+		}, rng)
+	})
+
+	t.Run("utf-16 line end", func(t *testing.T) {
+		t.Parallel()
+		rng, err := actionRange(content, RefactorParams{Line: 4})
+		require.NoError(t, err)
+		require.Equal(t, uint32(13), rng.End.Character)
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		t.Parallel()
+		_, err := actionRange(content, RefactorParams{Line: 6, EndLine: 42})
+		require.ErrorContains(t, err, "out of range")
+	})
+}
+
+func TestMatchAction(t *testing.T) {
+	t.Parallel()
+
+	actions := []protocol.CodeAction{
+		{Title: "Organize Imports", Kind: protocol.SourceOrganizeImports},
+		{Title: "Extract function", Kind: protocol.RefactorExtract},
+		{Title: "Extract variable", Kind: protocol.RefactorExtract, IsPreferred: true},
+		{Title: "Fill Server", Kind: protocol.RefactorRewrite},
+		{Title: "Fill Client", Kind: protocol.RefactorRewrite},
+	}
+
+	for _, tt := range []struct {
+		action string
+		title  string
+	}{
+		{"source.organizeImports", "Organize Imports"},
+		{"source", "Organize Imports"},
+		{"extract function", "Extract function"},
+		{"refactor.extract", "Extract variable"},
+		{"fill server", "Fill Server"},
+	} {
+		action, err := matchAction(actions, tt.action)
+		require.NoError(t, err, tt.action)
+		require.Equal(t, tt.title, action.Title, tt.action)
+	}
+
+	_, err := matchAction(actions, "inline")
+	require.ErrorContains(t, err, "- Fill Server (refactor.rewrite)")
+
+	_, err = matchAction(actions, "Fill")
+	require.ErrorContains(t, err, "2 code actions match")
+}
+
+func TestIsCodeActionKind(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, isCodeActionKind("quickfix"))
+	require.True(t, isCodeActionKind("source.organizeImports"))
+	require.False(t, isCodeActionKind("Fill"))
+	require.False(t, isCodeActionKind("Extract function"))
+	require.False(t, isCodeActionKind(""))
+}
@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+)
+
+type ReferencesParams = NavigationParams
+
+type referencesTool struct {
+	lspClients map[string]*lsp.Client
+	workingDir string
+}
+
+const (
+	ReferencesToolName    = "references"
+	referencesDescription = `Finds every reference to a symbol in the project, using the language server of the file.
+WHEN TO USE THIS TOOL:
+- Use before changing the signature or behavior of a function, method, type or field, to find the code that depends on it
+- Prefer it over grep for symbol names: it only lists uses of this exact symbol, not of others with the same name
+HOW TO USE:
+- Give the file, the line number and the name of the symbol as it appears on that line
+- Each result shows the path, line and column of the reference and the text of that line
+LIMITATIONS:
+- Only works for files handled by a configured LSP
+- Results are limited to 100 references`
+)
+
+func NewReferencesTool(lspClients map[string]*lsp.Client, workingDir string) BaseTool {
+	return &referencesTool{
+		lspClients: lspClients,
+		workingDir: workingDir,
+	}
+}
+
+func (r *referencesTool) Name() string {
+	return ReferencesToolName
+}
+
+func (r *referencesTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ReferencesToolName,
+		Description: referencesDescription,
+		Parameters:  navigationParameters(),
+		Required:    []string{"file_path", "line", "symbol"},
+		ReadOnly:    true,
+	}
+}
+
+func (r *referencesTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params ReferencesParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+
+	client, position, err := resolveSymbol(ctx, r.lspClients, r.workingDir, params, "textDocument/references")
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	locations, err := client.References(ctx, protocol.ReferenceParams{
+		TextDocumentPositionParams: position,
+		Context:                    protocol.ReferenceContext{IncludeDeclaration: false},
+	})
+	if err != nil {
+		return ToolResponse{}, fmt.Errorf("error finding references: %w", err)
+	}
+	if len(locations) == 0 {
+		return NewTextResponse(fmt.Sprintf("No references found for %s", params.Symbol)), nil
+	}
+
+	slices.SortFunc(locations, func(a, b protocol.Location) int {
+		return cmp.Or(
+			strings.Compare(string(a.URI), string(b.URI)),
+			cmp.Compare(a.Range.Start.Line, b.Range.Start.Line),
+			cmp.Compare(a.Range.Start.Character, b.Range.Start.Character),
+		)
+	})
+	s := newSnippets(r.workingDir)
+	var output strings.Builder
+	fmt.Fprintf(&output, "%d references to %s:\n", len(locations), params.Symbol)
+	for i, loc := range locations {
+		if i == maxNavigationResults {
+			fmt.Fprintf(&output, "\n(%d more not shown)", len(locations)-i)
+			break
+		}
+		fmt.Fprintf(&output, "\n%s: %s", s.position(loc), s.line(loc))
+	}
+	return NewTextResponse(output.String()), nil
+}
@@ -0,0 +1,199 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+)
+
+type SymbolsParams struct {
+	FilePath string `json:"file_path,omitempty"`
+	Query    string `json:"query,omitempty"`
+}
+
+type symbolsTool struct {
+	lspClients map[string]*lsp.Client
+	workingDir string
+}
+
+const (
+	SymbolsToolName    = "symbols"
+	symbolsDescription = `Lists the symbols of a file, or searches the symbols of the whole project, using the language servers.
+WHEN TO USE THIS TOOL:
+- Use with a file path to get the outline of a file: its types, functions, methods and fields with their lines
+- Use with a query to find where a type, function or method is declared when you don't know the file
+- Prefer it over grep to find declarations: it skips comments, strings and uses of the name
+HOW TO USE:
+- Give either file_path for the outline of a file or query to search the project
+- Results show the kind, name and location of each symbol
+LIMITATIONS:
+- Only works for files handled by a configured LSP
+- How a query matches names (exact, prefix or fuzzy) depends on the language server
+- Results are limited to 100 symbols
+TIPS:
+- Follow up with the definition, references or hover tools using the line of a symbol`
+)
+
+func NewSymbolsTool(lspClients map[string]*lsp.Client, workingDir string) BaseTool {
+	return &symbolsTool{
+		lspClients: lspClients,
+		workingDir: workingDir,
+	}
+}
+
+func (s *symbolsTool) Name() string {
+	return SymbolsToolName
+}
+
+func (s *symbolsTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        SymbolsToolName,
+		Description: symbolsDescription,
+		Parameters: map[string]any{
+			"file_path": map[string]any{
+				"type":        "string",
+				"description": "The path to the file to list the symbols of",
+			},
+			"query": map[string]any{
+				"type":        "string",
+				"description": "The name, or part of the name, of the symbols to search the project for",
+			},
+		},
+		Required: []string{},
+		ReadOnly: true,
+	}
+}
+
+func (s *symbolsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params SymbolsParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+
+	switch {
+	case params.FilePath != "":
+		return s.documentSymbols(ctx, params.FilePath)
+	case params.Query != "":
+		return s.workspaceSymbols(ctx, params.Query)
+	default:
+		return NewTextErrorResponse("either file_path or query is required"), nil
+	}
+}
+
+func (s *symbolsTool) documentSymbols(ctx context.Context, path string) (ToolResponse, error) {
+	filePath, err := workingDirFile(s.workingDir, path)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	client := navigationClient(s.lspClients, filePath, "textDocument/documentSymbol")
+	if client == nil {
+		return NewTextErrorResponse(fmt.Sprintf("no LSP client supports textDocument/documentSymbol for %s", path)), nil
+	}
+	if err := client.OpenFile(ctx, filePath); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error opening file in the LSP: %s", err)), nil
+	}
+	result, err := client.DocumentSymbol(ctx, protocol.DocumentSymbolParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: protocol.URIFromPath(filePath)},
+	})
+	if err != nil {
+		return ToolResponse{}, fmt.Errorf("error listing symbols: %w", err)
+	}
+
+	var lines []string
+	switch v := result.Value.(type) {
+	case []protocol.DocumentSymbol:
+		lines = outline(v, 0, lines)
+	case []protocol.SymbolInformation:
+		for _, symbol := range v {
+			line := fmt.Sprintf("%s %s%s", symbolKind(symbol.Kind), symbol.Name, lineRange(symbol.Location.Range))
+			if symbol.ContainerName != "" {
+				line += " in " + symbol.ContainerName
+			}
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return NewTextResponse(fmt.Sprintf("No symbols found in %s", path)), nil
+	}
+	if len(lines) > maxNavigationResults {
+		lines = append(lines[:maxNavigationResults], fmt.Sprintf("(%d more not shown)", len(lines)-maxNavigationResults))
+	}
+	return NewTextResponse(fmt.Sprintf("Symbols in %s:\n\n%s", path, strings.Join(lines, "\n"))), nil
+}
+
+func (s *symbolsTool) workspaceSymbols(ctx context.Context, query string) (ToolResponse, error) {
+	names := make([]string, 0, len(s.lspClients))
+	for name, client := range s.lspClients {
+		if client.IsMethodSupported("workspace/symbol") {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return NewTextErrorResponse("no LSP client supports workspace/symbol"), nil
+	}
+	slices.Sort(names)
+
+	snippets := newSnippets(s.workingDir)
+	var lines []string
+	for _, name := range names {
+		result, err := s.lspClients[name].Symbol(ctx, protocol.WorkspaceSymbolParams{Query: query})
+		if err != nil {
+			return ToolResponse{}, fmt.Errorf("error searching symbols with %s: %w", name, err)
+		}
+		symbols, err := result.Results()
+		if err != nil {
+			return ToolResponse{}, fmt.Errorf("error searching symbols with %s: %w", name, err)
+		}
+		for _, symbol := range symbols {
+			var kind protocol.SymbolKind
+			switch v := symbol.(type) {
+			case *protocol.WorkspaceSymbol:
+				kind = v.Kind
+			case *protocol.SymbolInformation:
+				kind = v.Kind
+			}
+			lines = append(lines, fmt.Sprintf("%s %s %s", symbolKind(kind), symbol.GetName(), snippets.position(symbol.GetLocation())))
+		}
+	}
+	if len(lines) == 0 {
+		return NewTextResponse(fmt.Sprintf("No symbols found for %s", query)), nil
+	}
+	if len(lines) > maxNavigationResults {
+		lines = append(lines[:maxNavigationResults], fmt.Sprintf("(%d more not shown)", len(lines)-maxNavigationResults))
+	}
+	return NewTextResponse(fmt.Sprintf("Symbols matching %s:\n\n%s", query, strings.Join(lines, "\n"))), nil
+}
+
+// outline appends the symbols and their children to lines, indented by
+// depth.
+func outline(symbols []protocol.DocumentSymbol, depth int, lines []string) []string {
+	for _, symbol := range symbols {
+		line := fmt.Sprintf("%s%s %s%s", strings.Repeat("  ", depth), symbolKind(symbol.Kind), symbol.Name, lineRange(symbol.Range))
+		if symbol.Detail != "" {
+			line += " " + symbol.Detail
+		}
+		lines = append(lines, line)
+		lines = outline(symbol.Children, depth+1, lines)
+	}
+	return lines
+}
+
+func symbolKind(kind protocol.SymbolKind) string {
+	if name, ok := protocol.TableKindMap[kind]; ok {
+		return name
+	}
+	return "Symbol"
+}
+
+// lineRange formats the lines r spans, counted from 1.
+func lineRange(r protocol.Range) string {
+	if r.End.Line > r.Start.Line {
+		return fmt.Sprintf(" (lines %d-%d)", r.Start.Line+1, r.End.Line+1)
+	}
+	return fmt.Sprintf(" (line %d)", r.Start.Line+1)
+}
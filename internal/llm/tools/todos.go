@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/todo"
+)
+
+const (
+	TodosToolName    = "todos"
+	todosDescription = `Creates and updates the task list of the current session. The list is shown to the user and kept when the conversation is summarized.
+
+WHEN TO USE THIS TOOL:
+- Use for tasks that take three or more distinct steps, or when the user gives you several things to do
+- Use it to plan the work before you start, then to track your progress while you work
+- Skip it for simple, single-step tasks and for purely conversational requests
+
+HOW TO USE:
+- Pass the complete list every time: it replaces the previous one
+- Each task has a short, imperative description and a status: pending, in_progress or completed
+- Mark a task in_progress before you start working on it, and keep only one task in_progress at a time
+- Mark a task completed as soon as it is done, not in batches at the end
+- Add tasks you discover along the way, and remove tasks that are no longer relevant
+
+TIPS:
+- Only mark a task completed when it is fully done; if it is blocked or failed, keep it in_progress and add a task for what needs to happen next
+- Call the tool with an empty list to clear the task list`
+)
+
+type TodosParams struct {
+	Todos []todo.Todo `json:"todos"`
+}
+
+type TodosResponseMetadata struct {
+	Completed int `json:"completed"`
+	Total     int `json:"total"`
+}
+
+type todosTool struct {
+	todos todo.Service
+}
+
+func NewTodosTool(todos todo.Service) BaseTool {
+	return &todosTool{
+		todos: todos,
+	}
+}
+
+func (t *todosTool) Name() string {
+	return TodosToolName
+}
+
+func (t *todosTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        TodosToolName,
+		Description: todosDescription,
+		Parameters: map[string]any{
+			"todos": map[string]any{
+				"type":        "array",
+				"description": "The complete, updated task list",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"content": map[string]any{
+							"type":        "string",
+							"description": "What the task is about",
+						},
+						"status": map[string]any{
+							"type":        "string",
+							"description": "How far along the task is",
+							"enum":        []string{string(todo.StatusPending), string(todo.StatusInProgress), string(todo.StatusCompleted)},
+						},
+					},
+					"required": []string{"content", "status"},
+				},
+			},
+		},
+		Required: []string{"todos"},
+	}
+}
+
+func (t *todosTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params TodosParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+	}
+
+	completed := 0
+	for i, item := range params.Todos {
+		params.Todos[i].Content = strings.TrimSpace(item.Content)
+		if params.Todos[i].Content == "" {
+			return NewTextErrorResponse(fmt.Sprintf("task %d has no content", i+1)), nil
+		}
+		if !item.Status.Valid() {
+			return NewTextErrorResponse(fmt.Sprintf("task %d has an invalid status: %q", i+1, item.Status)), nil
+		}
+		if item.Status == todo.StatusCompleted {
+			completed++
+		}
+	}
+
+	sessionID, _ := GetContextValues(ctx)
+	if sessionID == "" {
+		return ToolResponse{}, fmt.Errorf("session ID is required to update the task list")
+	}
+	if err := t.todos.Set(ctx, sessionID, params.Todos); err != nil {
+		return ToolResponse{}, fmt.Errorf("error updating the task list: %w", err)
+	}
+
+	content := "The task list is now empty."
+	if len(params.Todos) > 0 {
+		content = "The task list was updated:\n" + todo.Format(params.Todos)
+	}
+	return WithResponseMetadata(
+		NewTextResponse(content),
+		TodosResponseMetadata{
+			Completed: completed,
+			Total:     len(params.Todos),
+		},
+	), nil
+}
@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/todo"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryTodos struct {
+	*pubsub.Broker[todo.List]
+	lists map[string][]todo.Todo
+}
+
+func (m *memoryTodos) Get(_ context.Context, sessionID string) ([]todo.Todo, error) {
+	return m.lists[sessionID], nil
+}
+
+func (m *memoryTodos) Set(_ context.Context, sessionID string, todos []todo.Todo) error {
+	m.lists[sessionID] = todos
+	return nil
+}
+
+func TestTodosTool(t *testing.T) {
+	t.Parallel()
+
+	todos := &memoryTodos{Broker: pubsub.NewBroker[todo.List](), lists: map[string][]todo.Todo{}}
+	tool := NewTodosTool(todos)
+	ctx := context.WithValue(t.Context(), SessionIDContextKey, "session")
+
+	resp, err := tool.Run(ctx, ToolCall{Input: `{"todos":[{"content":" Add the tool ","status":"completed"},{"content":"Render it","status":"in_progress"}]}`})
+	require.NoError(t, err)
+	require.False(t, resp.IsError, resp.Content)
+	require.Equal(t, []todo.Todo{
+		{Content: "Add the tool", Status: todo.StatusCompleted},
+		{Content: "Render it", Status: todo.StatusInProgress},
+	}, todos.lists["session"])
+	require.Contains(t, resp.Content, "- [x] Add the tool\n- [ ] Render it (in progress)\n")
+
+	resp, err = tool.Run(ctx, ToolCall{Input: `{"todos":[{"content":"Ship it","status":"done"}]}`})
+	require.NoError(t, err)
+	require.True(t, resp.IsError)
+	require.Len(t, todos.lists["session"], 2, "invalid lists are not saved")
+}
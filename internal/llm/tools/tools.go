@@ -10,6 +10,9 @@ type ToolInfo struct {
 	Description string
 	Parameters  map[string]any
 	Required    []string
+	// ReadOnly marks tools that don't change anything, so the agent can run
+	// several calls to them at once.
+	ReadOnly bool
 }
 
 type toolResponseType string
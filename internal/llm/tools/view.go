@@ -107,6 +107,7 @@ func (v *viewTool) Info() ToolInfo {
 			},
 		},
 		Required: []string{"file_path"},
+		ReadOnly: true,
 	}
 }
 
@@ -138,27 +139,30 @@ func (v *viewTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		return ToolResponse{}, fmt.Errorf("error resolving file path: %w", err)
 	}
 
+	sessionID, messageID := GetContextValues(ctx)
+	permissionReq := permission.CreatePermissionRequest{
+		SessionID:   sessionID,
+		Path:        absFilePath,
+		ToolCallID:  call.ID,
+		ToolName:    ViewToolName,
+		Action:      "read",
+		Description: fmt.Sprintf("Read file outside working directory: %s", absFilePath),
+		Params:      ViewPermissionsParams(params),
+		FilePath:    absFilePath,
+	}
+	// Permission rules apply to every read, inside the working directory too
+	if err := v.permissions.Check(permissionReq); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
 	relPath, err := filepath.Rel(absWorkingDir, absFilePath)
 	if err != nil || strings.HasPrefix(relPath, "..") {
 		// File is outside working directory, request permission
-		sessionID, messageID := GetContextValues(ctx)
 		if sessionID == "" || messageID == "" {
 			return ToolResponse{}, fmt.Errorf("session ID and message ID are required for accessing files outside working directory")
 		}
 
-		granted := v.permissions.Request(
-			permission.CreatePermissionRequest{
-				SessionID:   sessionID,
-				Path:        absFilePath,
-				ToolCallID:  call.ID,
-				ToolName:    ViewToolName,
-				Action:      "read",
-				Description: fmt.Sprintf("Read file outside working directory: %s", absFilePath),
-				Params:      ViewPermissionsParams(params),
-			},
-		)
-
-		if !granted {
+		if !v.permissions.Request(permissionReq) {
 			return ToolResponse{}, permission.ErrorPermissionDenied
 		}
 	}
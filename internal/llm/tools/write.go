@@ -162,28 +162,30 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		return ToolResponse{}, fmt.Errorf("session_id and message_id are required")
 	}
 
-	diff, additions, removals := diff.GenerateDiff(
+	fileDiff, additions, removals := diff.GenerateDiff(
 		oldContent,
 		params.Content,
 		strings.TrimPrefix(filePath, w.workingDir),
 	)
 
-	p := w.permissions.Request(
-		permission.CreatePermissionRequest{
-			SessionID:   sessionID,
-			Path:        fsext.PathOrPrefix(filePath, w.workingDir),
-			ToolCallID:  call.ID,
-			ToolName:    WriteToolName,
-			Action:      "write",
-			Description: fmt.Sprintf("Create file %s", filePath),
-			Params: WritePermissionsParams{
-				FilePath:   filePath,
-				OldContent: oldContent,
-				NewContent: params.Content,
-			},
+	permissionReq := permission.CreatePermissionRequest{
+		SessionID:   sessionID,
+		Path:        fsext.PathOrPrefix(filePath, w.workingDir),
+		FilePath:    filePath,
+		ToolCallID:  call.ID,
+		ToolName:    WriteToolName,
+		Action:      "write",
+		Description: fmt.Sprintf("Create file %s", filePath),
+		Params: WritePermissionsParams{
+			FilePath:   filePath,
+			OldContent: oldContent,
+			NewContent: params.Content,
 		},
-	)
-	if !p {
+	}
+	if err := w.permissions.Check(permissionReq); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if !w.permissions.Request(permissionReq) {
 		return ToolResponse{}, permission.ErrorPermissionDenied
 	}
 
@@ -192,10 +194,19 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		return ToolResponse{}, fmt.Errorf("error writing file: %w", err)
 	}
 
+	content, formatNote := formatOnWrite(ctx, w.lspClients, w.workingDir, filePath, params.Content)
+	if formatNote != "" {
+		fileDiff, additions, removals = diff.GenerateDiff(oldContent, content, strings.TrimPrefix(filePath, w.workingDir))
+	}
+
 	// Check if file exists in history
 	file, err := w.files.GetByPathAndSession(ctx, filePath, sessionID)
 	if err != nil {
-		_, err = w.files.Create(ctx, sessionID, filePath, oldContent)
+		if fileInfo == nil {
+			_, err = w.files.CreateNew(ctx, sessionID, messageID, filePath)
+		} else {
+			_, err = w.files.Create(ctx, sessionID, messageID, filePath, oldContent)
+		}
 		if err != nil {
 			// Log error but don't fail the operation
 			return ToolResponse{}, fmt.Errorf("error creating file history: %w", err)
@@ -203,13 +214,13 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 	}
 	if file.Content != oldContent {
 		// User Manually changed the content store an intermediate version
-		_, err = w.files.CreateVersion(ctx, sessionID, filePath, oldContent)
+		_, err = w.files.CreateVersion(ctx, sessionID, messageID, filePath, oldContent)
 		if err != nil {
 			slog.Debug("Error creating file history version", "error", err)
 		}
 	}
 	// Store the new version
-	_, err = w.files.CreateVersion(ctx, sessionID, filePath, params.Content)
+	_, err = w.files.CreateVersion(ctx, sessionID, messageID, filePath, content)
 	if err != nil {
 		slog.Debug("Error creating file history version", "error", err)
 	}
@@ -219,11 +230,11 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 	waitForLspDiagnostics(ctx, filePath, w.lspClients)
 
 	result := fmt.Sprintf("File successfully written: %s", filePath)
-	result = fmt.Sprintf("<result>\n%s\n</result>", result)
+	result = fmt.Sprintf("<result>\n%s%s\n</result>", result, formatNote)
 	result += getDiagnostics(filePath, w.lspClients)
 	return WithResponseMetadata(NewTextResponse(result),
 		WriteResponseMetadata{
-			Diff:      diff,
+			Diff:      fileDiff,
 			Additions: additions,
 			Removals:  removals,
 		},
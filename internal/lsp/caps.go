@@ -102,8 +102,13 @@ func (c *Client) IsMethodSupported(method string) bool {
 		return caps.DocumentRangeFormattingProvider != nil
 	case "textDocument/onTypeFormatting":
 		return caps.DocumentOnTypeFormattingProvider != nil
-	case "textDocument/rename", "textDocument/prepareRename":
+	case "textDocument/rename":
 		return caps.RenameProvider != nil
+	case "textDocument/prepareRename":
+		// Only servers that advertise RenameOptions with prepareProvider
+		// answer prepareRename.
+		opts, ok := caps.RenameProvider.(map[string]any)
+		return ok && opts["prepareProvider"] == true
 	case "workspace/executeCommand":
 		return caps.ExecuteCommandProvider != nil
 	default:
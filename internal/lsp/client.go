@@ -198,6 +198,9 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 						DynamicRegistration:    true,
 						RelativePatternSupport: true,
 					},
+					WorkspaceEdit: &protocol.WorkspaceEditClientCapabilities{
+						DocumentChanges: true,
+					},
 				},
 				TextDocument: protocol.TextDocumentClientCapabilities{
 					Synchronization: &protocol.TextDocumentSyncClientCapabilities{
@@ -217,6 +220,14 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 								ValueSet: []protocol.CodeActionKind{},
 							},
 						},
+						IsPreferredSupport: true,
+						DataSupport:        true,
+						ResolveSupport: &protocol.ClientCodeActionResolveOptions{
+							Properties: []string{"edit"},
+						},
+					},
+					Rename: &protocol.RenameClientCapabilities{
+						PrepareSupport: true,
 					},
 					PublishDiagnostics: protocol.PublishDiagnosticsClientCapabilities{
 						VersionSupport: true,
@@ -310,6 +321,20 @@ const (
 	StateError
 )
 
+// String returns the string representation of the server state.
+func (s ServerState) String() string {
+	switch s {
+	case StateStarting:
+		return "starting"
+	case StateReady:
+		return "ready"
+	case StateError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
 // GetServerState returns the current state of the LSP server
 func (c *Client) GetServerState() ServerState {
 	if val := c.serverState.Load(); val != nil {
@@ -1,7 +1,6 @@
 package util
 
 import (
-	"bytes"
 	"fmt"
 	"os"
 	"sort"
@@ -22,25 +21,40 @@ func applyTextEdits(uri protocol.DocumentURI, edits []protocol.TextEdit) error {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
 
+	newContent, err := ApplyTextEdits(string(content), edits)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, []byte(newContent), 0o644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+// ApplyTextEdits returns content with the given edits applied, keeping its
+// line endings.
+func ApplyTextEdits(content string, edits []protocol.TextEdit) (string, error) {
 	// Detect line ending style
 	var lineEnding string
-	if bytes.Contains(content, []byte("\r\n")) {
+	if strings.Contains(content, "\r\n") {
 		lineEnding = "\r\n"
 	} else {
 		lineEnding = "\n"
 	}
 
 	// Track if file ends with a newline
-	endsWithNewline := len(content) > 0 && bytes.HasSuffix(content, []byte(lineEnding))
+	endsWithNewline := len(content) > 0 && strings.HasSuffix(content, lineEnding)
 
 	// Split into lines without the endings
-	lines := strings.Split(string(content), lineEnding)
+	lines := strings.Split(content, lineEnding)
 
 	// Check for overlapping edits
 	for i, edit1 := range edits {
 		for j := i + 1; j < len(edits); j++ {
 			if rangesOverlap(edit1.Range, edits[j].Range) {
-				return fmt.Errorf("overlapping edits detected between edit %d and %d", i, j)
+				return "", fmt.Errorf("overlapping edits detected between edit %d and %d", i, j)
 			}
 		}
 	}
@@ -59,7 +73,7 @@ func applyTextEdits(uri protocol.DocumentURI, edits []protocol.TextEdit) error {
 	for _, edit := range sortedEdits {
 		newLines, err := applyTextEdit(lines, edit)
 		if err != nil {
-			return fmt.Errorf("failed to apply edit: %w", err)
+			return "", fmt.Errorf("failed to apply edit: %w", err)
 		}
 		lines = newLines
 	}
@@ -78,11 +92,7 @@ func applyTextEdits(uri protocol.DocumentURI, edits []protocol.TextEdit) error {
 		newContent.WriteString(lineEnding)
 	}
 
-	if err := os.WriteFile(path, []byte(newContent.String()), 0o644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-
-	return nil
+	return newContent.String(), nil
 }
 
 func applyTextEdit(lines []string, edit protocol.TextEdit) ([]string, error) {
@@ -247,6 +257,38 @@ func ApplyWorkspaceEdit(edit protocol.WorkspaceEdit) error {
 	return nil
 }
 
+// WorkspaceTextEdits collects the text edits of the given WorkspaceEdit by
+// document, so they can be reviewed before they are applied. Edits that
+// create, rename or delete files are not supported and return an error.
+func WorkspaceTextEdits(edit protocol.WorkspaceEdit) (map[protocol.DocumentURI][]protocol.TextEdit, error) {
+	edits := make(map[protocol.DocumentURI][]protocol.TextEdit)
+	for uri, textEdits := range edit.Changes {
+		edits[uri] = append(edits[uri], textEdits...)
+	}
+
+	for _, change := range edit.DocumentChanges {
+		switch {
+		case change.CreateFile != nil:
+			return nil, fmt.Errorf("creating files is not supported: %s", change.CreateFile.URI)
+		case change.RenameFile != nil:
+			return nil, fmt.Errorf("renaming files is not supported: %s", change.RenameFile.OldURI)
+		case change.DeleteFile != nil:
+			return nil, fmt.Errorf("deleting files is not supported: %s", change.DeleteFile.URI)
+		case change.TextDocumentEdit != nil:
+			uri := change.TextDocumentEdit.TextDocument.URI
+			for _, e := range change.TextDocumentEdit.Edits {
+				textEdit, err := e.AsTextEdit()
+				if err != nil {
+					return nil, fmt.Errorf("invalid edit type: %w", err)
+				}
+				edits[uri] = append(edits[uri], textEdit)
+			}
+		}
+	}
+
+	return edits, nil
+}
+
 func rangesOverlap(r1, r2 protocol.Range) bool {
 	if r1.Start.Line > r2.End.Line || r2.Start.Line > r1.End.Line {
 		return false
@@ -0,0 +1,80 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/lsp/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyTextEdits(t *testing.T) {
+	t.Parallel()
+
+	edit := func(startLine, startChar, endLine, endChar uint32, text string) protocol.TextEdit {
+		return protocol.TextEdit{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: startLine, Character: startChar},
+				End:   protocol.Position{Line: endLine, Character: endChar},
+			},
+			NewText: text,
+		}
+	}
+
+	t.Run("edits on several lines", func(t *testing.T) {
+		t.Parallel()
+		content, err := ApplyTextEdits("func old() {}\n\nfunc main() { old() }\n", []protocol.TextEdit{
+			edit(0, 5, 0, 8, "renamed"),
+			edit(2, 14, 2, 17, "renamed"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, "func renamed() {}\n\nfunc main() { renamed() }\n", content)
+	})
+
+	t.Run("keeps crlf line endings", func(t *testing.T) {
+		t.Parallel()
+		content, err := ApplyTextEdits("a\r\nb\r\n", []protocol.TextEdit{edit(1, 0, 1, 1, "c")})
+		require.NoError(t, err)
+		require.Equal(t, "a\r\nc\r\n", content)
+	})
+
+	t.Run("overlapping edits", func(t *testing.T) {
+		t.Parallel()
+		_, err := ApplyTextEdits("abcdef\n", []protocol.TextEdit{
+			edit(0, 0, 0, 3, "x"),
+			edit(0, 2, 0, 4, "y"),
+		})
+		require.ErrorContains(t, err, "overlapping edits")
+	})
+}
+
+func TestWorkspaceTextEdits(t *testing.T) {
+	t.Parallel()
+
+	textEdit := protocol.TextEdit{NewText: "x"}
+
+	edits, err := WorkspaceTextEdits(protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentURI][]protocol.TextEdit{
+			"file:///a.go": {textEdit},
+		},
+		DocumentChanges: []protocol.DocumentChange{{
+			TextDocumentEdit: &protocol.TextDocumentEdit{
+				TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+					TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: "file:///b.go"},
+				},
+				Edits: []protocol.Or_TextDocumentEdit_edits_Elem{{Value: textEdit}},
+			},
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[protocol.DocumentURI][]protocol.TextEdit{
+		"file:///a.go": {textEdit},
+		"file:///b.go": {textEdit},
+	}, edits)
+
+	_, err = WorkspaceTextEdits(protocol.WorkspaceEdit{
+		DocumentChanges: []protocol.DocumentChange{{
+			CreateFile: &protocol.CreateFile{URI: "file:///c.go"},
+		}},
+	})
+	require.ErrorContains(t, err, "creating files is not supported")
+}
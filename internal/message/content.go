@@ -26,6 +26,7 @@ const (
 	FinishReasonCanceled         FinishReason = "canceled"
 	FinishReasonError            FinishReason = "error"
 	FinishReasonPermissionDenied FinishReason = "permission_denied"
+	FinishReasonBudgetExceeded   FinishReason = "budget_exceeded"
 
 	// Should never happen
 	FinishReasonUnknown FinishReason = "unknown"
@@ -109,10 +110,29 @@ type Finish struct {
 	Time    int64        `json:"time"`
 	Message string       `json:"message,omitempty"`
 	Details string       `json:"details,omitempty"`
+	// ResponseID is the ID the provider gave the response, used to continue
+	// the conversation from it.
+	ResponseID string `json:"response_id,omitempty"`
 }
 
 func (Finish) isPart() {}
 
+// Usage is the token usage and cost of the model call that produced an
+// assistant message.
+type Usage struct {
+	InputTokens      int64   `json:"input_tokens"`
+	OutputTokens     int64   `json:"output_tokens"`
+	CacheReadTokens  int64   `json:"cache_read_tokens"`
+	CacheWriteTokens int64   `json:"cache_write_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// PromptTokens returns how many tokens the prompt had, whether they were read
+// from the cache or not.
+func (u Usage) PromptTokens() int64 {
+	return u.InputTokens + u.CacheReadTokens + u.CacheWriteTokens
+}
+
 type Message struct {
 	ID        string
 	Role      MessageRole
@@ -120,6 +140,7 @@ type Message struct {
 	Parts     []ContentPart
 	Model     string
 	Provider  string
+	Usage     Usage
 	CreatedAt int64
 	UpdatedAt int64
 }
@@ -377,6 +398,25 @@ func (m *Message) AddFinish(reason FinishReason, message, details string) {
 	m.Parts = append(m.Parts, Finish{Reason: reason, Time: time.Now().Unix(), Message: message, Details: details})
 }
 
+// SetResponseID records the provider's ID for the finished response.
+func (m *Message) SetResponseID(id string) {
+	for i, part := range m.Parts {
+		if c, ok := part.(Finish); ok {
+			c.ResponseID = id
+			m.Parts[i] = c
+			return
+		}
+	}
+}
+
+// ResponseID returns the provider's ID for the response, if any.
+func (m *Message) ResponseID() string {
+	if finish := m.FinishPart(); finish != nil {
+		return finish.ResponseID
+	}
+	return ""
+}
+
 func (m *Message) AddImageURL(url, detail string) {
 	m.Parts = append(m.Parts, ImageURLContent{URL: url, Detail: detail})
 }
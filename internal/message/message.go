@@ -17,6 +17,7 @@ type CreateMessageParams struct {
 	Parts    []ContentPart
 	Model    string
 	Provider string
+	Usage    Usage
 }
 
 type Service interface {
@@ -65,12 +66,17 @@ func (s *service) Create(ctx context.Context, sessionID string, params CreateMes
 		return Message{}, err
 	}
 	dbMessage, err := s.q.CreateMessage(ctx, db.CreateMessageParams{
-		ID:        uuid.New().String(),
-		SessionID: sessionID,
-		Role:      string(params.Role),
-		Parts:     string(partsJSON),
-		Model:     sql.NullString{String: string(params.Model), Valid: true},
-		Provider:  sql.NullString{String: params.Provider, Valid: params.Provider != ""},
+		ID:               uuid.New().String(),
+		SessionID:        sessionID,
+		Role:             string(params.Role),
+		Parts:            string(partsJSON),
+		Model:            sql.NullString{String: string(params.Model), Valid: true},
+		Provider:         sql.NullString{String: params.Provider, Valid: params.Provider != ""},
+		InputTokens:      params.Usage.InputTokens,
+		OutputTokens:     params.Usage.OutputTokens,
+		CacheReadTokens:  params.Usage.CacheReadTokens,
+		CacheWriteTokens: params.Usage.CacheWriteTokens,
+		Cost:             params.Usage.Cost,
 	})
 	if err != nil {
 		return Message{}, err
@@ -110,9 +116,16 @@ func (s *service) Update(ctx context.Context, message Message) error {
 		finishedAt.Valid = true
 	}
 	err = s.q.UpdateMessage(ctx, db.UpdateMessageParams{
-		ID:         message.ID,
-		Parts:      string(parts),
-		FinishedAt: finishedAt,
+		ID:               message.ID,
+		Parts:            string(parts),
+		FinishedAt:       finishedAt,
+		InputTokens:      message.Usage.InputTokens,
+		OutputTokens:     message.Usage.OutputTokens,
+		CacheReadTokens:  message.Usage.CacheReadTokens,
+		CacheWriteTokens: message.Usage.CacheWriteTokens,
+		Cost:             message.Usage.Cost,
+		Model:            sql.NullString{String: message.Model, Valid: true},
+		Provider:         sql.NullString{String: message.Provider, Valid: message.Provider != ""},
 	})
 	if err != nil {
 		return err
@@ -157,11 +170,86 @@ func (s *service) fromDBItem(item db.Message) (Message, error) {
 		Parts:     parts,
 		Model:     item.Model.String,
 		Provider:  item.Provider.String,
+		Usage: Usage{
+			InputTokens:      item.InputTokens,
+			OutputTokens:     item.OutputTokens,
+			CacheReadTokens:  item.CacheReadTokens,
+			CacheWriteTokens: item.CacheWriteTokens,
+			Cost:             item.Cost,
+		},
 		CreatedAt: item.CreatedAt,
 		UpdatedAt: item.UpdatedAt,
 	}, nil
 }
 
+type messageJSON struct {
+	ID        string          `json:"id"`
+	SessionID string          `json:"session_id"`
+	Role      MessageRole     `json:"role"`
+	Parts     json.RawMessage `json:"parts"`
+	Model     string          `json:"model,omitempty"`
+	Provider  string          `json:"provider,omitempty"`
+	Usage     *Usage          `json:"usage,omitempty"`
+	CreatedAt int64           `json:"created_at"`
+	UpdatedAt int64           `json:"updated_at"`
+}
+
+// MarshalJSON encodes the message using the same tagged part representation
+// that is stored in the database.
+func (m Message) MarshalJSON() ([]byte, error) {
+	parts, err := marshallParts(m.Parts)
+	if err != nil {
+		return nil, err
+	}
+	var usage *Usage
+	if m.Usage != (Usage{}) {
+		usage = &m.Usage
+	}
+	return json.Marshal(messageJSON{
+		ID:        m.ID,
+		SessionID: m.SessionID,
+		Role:      m.Role,
+		Parts:     parts,
+		Model:     m.Model,
+		Provider:  m.Provider,
+		Usage:     usage,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	})
+}
+
+// UnmarshalJSON decodes a message produced by MarshalJSON.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var raw messageJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	parts := []ContentPart{}
+	if len(raw.Parts) > 0 {
+		var err error
+		parts, err = unmarshallParts(raw.Parts)
+		if err != nil {
+			return err
+		}
+	}
+	var usage Usage
+	if raw.Usage != nil {
+		usage = *raw.Usage
+	}
+	*m = Message{
+		ID:        raw.ID,
+		SessionID: raw.SessionID,
+		Role:      raw.Role,
+		Parts:     parts,
+		Model:     raw.Model,
+		Provider:  raw.Provider,
+		Usage:     usage,
+		CreatedAt: raw.CreatedAt,
+		UpdatedAt: raw.UpdatedAt,
+	}
+	return nil
+}
+
 type partType string
 
 const (
@@ -0,0 +1,130 @@
+package permission
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/google/uuid"
+)
+
+// Grant is a permission the user chose to always allow in a project. It
+// matches later requests for the same tool, action and path and, for shell
+// commands, the same command.
+type Grant struct {
+	ID        string    `json:"id"`
+	ToolName  string    `json:"tool_name"`
+	Action    string    `json:"action"`
+	Path      string    `json:"path"`
+	Command   string    `json:"command,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ErrGrantNotFound is returned when no grant matches an ID or ID prefix.
+var ErrGrantNotFound = errors.New("permission grant not found")
+
+// GrantStore keeps "always allow" grants in the project database so they
+// survive restarts.
+type GrantStore struct {
+	q db.Querier
+}
+
+func NewGrantStore(q db.Querier) *GrantStore {
+	return &GrantStore{q: q}
+}
+
+// Add records a grant for the request. Granting the same scope twice is a
+// no-op.
+func (s *GrantStore) Add(ctx context.Context, req PermissionRequest) error {
+	err := s.q.CreatePermissionGrant(ctx, db.CreatePermissionGrantParams{
+		ID:       uuid.New().String(),
+		ToolName: req.ToolName,
+		Action:   req.Action,
+		Path:     req.Path,
+		Command:  req.Command,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save permission grant: %w", err)
+	}
+	return nil
+}
+
+// Allows reports whether a stored grant covers the request.
+func (s *GrantStore) Allows(ctx context.Context, req PermissionRequest) (bool, error) {
+	_, err := s.q.GetPermissionGrant(ctx, db.GetPermissionGrantParams{
+		ToolName: req.ToolName,
+		Action:   req.Action,
+		Path:     req.Path,
+		Command:  req.Command,
+	})
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, sql.ErrNoRows):
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to look up permission grant: %w", err)
+	}
+}
+
+// List returns all grants, oldest first.
+func (s *GrantStore) List(ctx context.Context) ([]Grant, error) {
+	rows, err := s.q.ListPermissionGrants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list permission grants: %w", err)
+	}
+	grants := make([]Grant, len(rows))
+	for i, row := range rows {
+		grants[i] = Grant{
+			ID:        row.ID,
+			ToolName:  row.ToolName,
+			Action:    row.Action,
+			Path:      row.Path,
+			Command:   row.Command,
+			CreatedAt: time.Unix(row.CreatedAt, 0),
+		}
+	}
+	return grants, nil
+}
+
+// Revoke deletes the grant with the given ID or unique ID prefix and returns
+// it.
+func (s *GrantStore) Revoke(ctx context.Context, id string) (Grant, error) {
+	grants, err := s.List(ctx)
+	if err != nil {
+		return Grant{}, err
+	}
+	var matches []Grant
+	for _, g := range grants {
+		if g.ID == id {
+			matches = []Grant{g}
+			break
+		}
+		if id != "" && strings.HasPrefix(g.ID, id) {
+			matches = append(matches, g)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Grant{}, fmt.Errorf("%w: %s", ErrGrantNotFound, id)
+	case 1:
+	default:
+		return Grant{}, fmt.Errorf("grant ID %q is ambiguous (%d matches)", id, len(matches))
+	}
+	if err := s.q.DeletePermissionGrant(ctx, matches[0].ID); err != nil {
+		return Grant{}, fmt.Errorf("failed to revoke permission grant: %w", err)
+	}
+	return matches[0], nil
+}
+
+// RevokeAll deletes every grant.
+func (s *GrantStore) RevokeAll(ctx context.Context) error {
+	if err := s.q.DeleteAllPermissionGrants(ctx); err != nil {
+		return fmt.Errorf("failed to revoke permission grants: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package permission
+
+import (
+	"context"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermissionService_GrantAlways(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	conn, err := db.Connect(ctx, t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	q := db.New(conn)
+
+	req := CreatePermissionRequest{
+		SessionID: "session1",
+		ToolName:  "bash",
+		Action:    "execute",
+		Path:      "/tmp",
+		Command:   "go test ./...",
+	}
+
+	service := NewPermissionService("/tmp", false, nil, nil, q)
+	events := service.Subscribe(t.Context())
+	var granted bool
+	done := make(chan struct{})
+	go func() {
+		granted = service.Request(req)
+		close(done)
+	}()
+	event := <-events
+	service.GrantAlways(event.Payload)
+	<-done
+	require.True(t, granted)
+
+	// A new service, as after a restart, remembers the grant.
+	restarted := NewPermissionService("/tmp", false, nil, nil, q)
+	require.True(t, restarted.Request(req))
+
+	grants, err := restarted.ListGrants(ctx)
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+	require.Equal(t, "bash", grants[0].ToolName)
+	require.Equal(t, "go test ./...", grants[0].Command)
+
+	// Granting the same scope again does not duplicate it.
+	require.NoError(t, NewGrantStore(q).Add(ctx, event.Payload))
+	grants, err = restarted.ListGrants(ctx)
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+
+	_, err = restarted.RevokeGrant(ctx, "nope")
+	require.ErrorIs(t, err, ErrGrantNotFound)
+
+	revoked, err := restarted.RevokeGrant(ctx, grants[0].ID[:8])
+	require.NoError(t, err)
+	require.Equal(t, grants[0].ID, revoked.ID)
+
+	grants, err = restarted.ListGrants(ctx)
+	require.NoError(t, err)
+	require.Empty(t, grants)
+}
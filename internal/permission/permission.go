@@ -3,12 +3,16 @@ package permission
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"slices"
 	"sync"
 
+	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/db"
 	"github.com/charmbracelet/crush/internal/pubsub"
 	"github.com/google/uuid"
 )
@@ -23,6 +27,10 @@ type CreatePermissionRequest struct {
 	Action      string `json:"action"`
 	Params      any    `json:"params"`
 	Path        string `json:"path"`
+	// FilePath is the file the tool acts on, matched by path rules.
+	FilePath string `json:"file_path,omitempty"`
+	// Command is the shell command to run, matched by command rules.
+	Command string `json:"command,omitempty"`
 }
 
 type PermissionNotification struct {
@@ -40,14 +48,23 @@ type PermissionRequest struct {
 	Action      string `json:"action"`
 	Params      any    `json:"params"`
 	Path        string `json:"path"`
+	Command     string `json:"command,omitempty"`
 }
 
 type Service interface {
 	pubsub.Suscriber[PermissionRequest]
 	GrantPersistent(permission PermissionRequest)
+	// GrantAlways grants the request and remembers it for the project, so
+	// matching requests are allowed without asking after a restart.
+	GrantAlways(permission PermissionRequest)
+	ListGrants(ctx context.Context) ([]Grant, error)
+	RevokeGrant(ctx context.Context, id string) (Grant, error)
 	Grant(permission PermissionRequest)
 	Deny(permission PermissionRequest)
 	Request(opts CreatePermissionRequest) bool
+	// Check returns a *RuleDeniedError when a permission rule denies the
+	// request. Tools call it before Request so the model learns why.
+	Check(opts CreatePermissionRequest) error
 	AutoApproveSession(sessionID string)
 	SetSkipRequests(skip bool)
 	SkipRequests() bool
@@ -66,6 +83,8 @@ type permissionService struct {
 	autoApproveSessionsMu sync.RWMutex
 	skip                  bool
 	allowedTools          []string
+	rules                 ruleSet
+	grants                *GrantStore
 
 	// used to make sure we only process one request at a time
 	requestMu     sync.Mutex
@@ -91,6 +110,31 @@ func (s *permissionService) GrantPersistent(permission PermissionRequest) {
 	}
 }
 
+func (s *permissionService) GrantAlways(permission PermissionRequest) {
+	if s.grants == nil {
+		s.GrantPersistent(permission)
+		return
+	}
+	if err := s.grants.Add(context.Background(), permission); err != nil {
+		slog.Error("Failed to save permission grant", "error", err)
+	}
+	s.Grant(permission)
+}
+
+func (s *permissionService) ListGrants(ctx context.Context) ([]Grant, error) {
+	if s.grants == nil {
+		return nil, nil
+	}
+	return s.grants.List(ctx)
+}
+
+func (s *permissionService) RevokeGrant(ctx context.Context, id string) (Grant, error) {
+	if s.grants == nil {
+		return Grant{}, fmt.Errorf("%w: %s", ErrGrantNotFound, id)
+	}
+	return s.grants.Revoke(ctx, id)
+}
+
 func (s *permissionService) Grant(permission PermissionRequest) {
 	s.notificationBroker.Publish(pubsub.CreatedEvent, PermissionNotification{
 		ToolCallID: permission.ToolCallID,
@@ -122,7 +166,24 @@ func (s *permissionService) Deny(permission PermissionRequest) {
 	}
 }
 
+func (s *permissionService) Check(opts CreatePermissionRequest) error {
+	if rule, ok := s.rules.evaluate(opts); ok && rule.Decision == config.PermissionDeny {
+		return &RuleDeniedError{Rule: rule}
+	}
+	return nil
+}
+
 func (s *permissionService) Request(opts CreatePermissionRequest) bool {
+	// Deny rules hold even when prompts are skipped.
+	rule, hasRule := s.rules.evaluate(opts)
+	if hasRule && rule.Decision == config.PermissionDeny {
+		s.notificationBroker.Publish(pubsub.CreatedEvent, PermissionNotification{
+			ToolCallID: opts.ToolCallID,
+			Denied:     true,
+		})
+		return false
+	}
+
 	if s.skip {
 		return true
 	}
@@ -134,9 +195,18 @@ func (s *permissionService) Request(opts CreatePermissionRequest) bool {
 	s.requestMu.Lock()
 	defer s.requestMu.Unlock()
 
+	if hasRule && rule.Decision == config.PermissionAllow {
+		s.notifyAutoGranted(opts.ToolCallID)
+		return true
+	}
+	// Ask rules prompt every time, regardless of the allowlist and earlier
+	// grants.
+	alwaysAsk := hasRule && rule.Decision == config.PermissionAsk
+
 	// Check if the tool/action combination is in the allowlist
 	commandKey := opts.ToolName + ":" + opts.Action
-	if slices.Contains(s.allowedTools, commandKey) || slices.Contains(s.allowedTools, opts.ToolName) {
+	if !alwaysAsk && (slices.Contains(s.allowedTools, commandKey) || slices.Contains(s.allowedTools, opts.ToolName)) {
+		s.notifyAutoGranted(opts.ToolCallID)
 		return true
 	}
 
@@ -145,6 +215,7 @@ func (s *permissionService) Request(opts CreatePermissionRequest) bool {
 	s.autoApproveSessionsMu.RUnlock()
 
 	if autoApprove {
+		s.notifyAutoGranted(opts.ToolCallID)
 		return true
 	}
 
@@ -170,25 +241,31 @@ func (s *permissionService) Request(opts CreatePermissionRequest) bool {
 		Description: opts.Description,
 		Action:      opts.Action,
 		Params:      opts.Params,
+		Command:     opts.Command,
 	}
 
-	s.sessionPermissionsMu.RLock()
-	for _, p := range s.sessionPermissions {
-		if p.ToolName == permission.ToolName && p.Action == permission.Action && p.SessionID == permission.SessionID && p.Path == permission.Path {
-			s.sessionPermissionsMu.RUnlock()
-			return true
+	if !alwaysAsk {
+		s.sessionPermissionsMu.RLock()
+		for _, p := range s.sessionPermissions {
+			if p.ToolName == permission.ToolName && p.Action == permission.Action && p.SessionID == permission.SessionID && p.Path == permission.Path {
+				s.sessionPermissionsMu.RUnlock()
+				s.notifyAutoGranted(opts.ToolCallID)
+				return true
+			}
 		}
-	}
-	s.sessionPermissionsMu.RUnlock()
-
-	s.sessionPermissionsMu.RLock()
-	for _, p := range s.sessionPermissions {
-		if p.ToolName == permission.ToolName && p.Action == permission.Action && p.SessionID == permission.SessionID && p.Path == permission.Path {
-			s.sessionPermissionsMu.RUnlock()
-			return true
+		s.sessionPermissionsMu.RUnlock()
+
+		if s.grants != nil {
+			allowed, err := s.grants.Allows(context.Background(), permission)
+			if err != nil {
+				slog.Error("Failed to check permission grants", "error", err)
+			}
+			if allowed {
+				s.notifyAutoGranted(opts.ToolCallID)
+				return true
+			}
 		}
 	}
-	s.sessionPermissionsMu.RUnlock()
 
 	s.activeRequest = &permission
 
@@ -202,6 +279,15 @@ func (s *permissionService) Request(opts CreatePermissionRequest) bool {
 	return <-respCh
 }
 
+// notifyAutoGranted tells subscribers that a request was approved without
+// prompting, e.g. through the allowlist or an earlier persistent grant.
+func (s *permissionService) notifyAutoGranted(toolCallID string) {
+	s.notificationBroker.Publish(pubsub.CreatedEvent, PermissionNotification{
+		ToolCallID: toolCallID,
+		Granted:    true,
+	})
+}
+
 func (s *permissionService) AutoApproveSession(sessionID string) {
 	s.autoApproveSessionsMu.Lock()
 	s.autoApproveSessions[sessionID] = true
@@ -220,7 +306,13 @@ func (s *permissionService) SkipRequests() bool {
 	return s.skip
 }
 
-func NewPermissionService(workingDir string, skip bool, allowedTools []string) Service {
+// NewPermissionService creates the permission service. Grants made with
+// GrantAlways are kept in q; with a nil q they last until the process exits.
+func NewPermissionService(workingDir string, skip bool, allowedTools []string, rules []config.PermissionRule, q db.Querier) Service {
+	var grants *GrantStore
+	if q != nil {
+		grants = NewGrantStore(q)
+	}
 	return &permissionService{
 		Broker:              pubsub.NewBroker[PermissionRequest](),
 		notificationBroker:  pubsub.NewBroker[PermissionNotification](),
@@ -229,6 +321,8 @@ func NewPermissionService(workingDir string, skip bool, allowedTools []string) S
 		autoApproveSessions: make(map[string]bool),
 		skip:                skip,
 		allowedTools:        allowedTools,
+		rules:               ruleSet{workingDir: workingDir, rules: rules},
+		grants:              grants,
 		pendingRequests:     csync.NewMap[string, chan bool](),
 	}
 }
@@ -0,0 +1,203 @@
+package permission
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/charmbracelet/crush/internal/config"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// RuleDeniedError is returned by Check when a permission rule denies a tool
+// call. Its message is meant to be shown to the model.
+type RuleDeniedError struct {
+	Rule config.PermissionRule
+}
+
+func (e *RuleDeniedError) Error() string {
+	var target string
+	switch {
+	case e.Rule.Command != "":
+		target = fmt.Sprintf(" for commands matching %q", e.Rule.Command)
+	case e.Rule.Path != "":
+		target = fmt.Sprintf(" for paths matching %q", e.Rule.Path)
+	}
+	tool := e.Rule.Tool
+	if tool == "" {
+		tool = "*"
+	}
+	msg := fmt.Sprintf("Permission denied by a rule in the user's configuration (tool %q%s)", tool, target)
+	if e.Rule.Reason != "" {
+		msg += ": " + e.Rule.Reason
+	}
+	return msg + ". Do not retry this call; choose a different approach or ask the user."
+}
+
+type ruleSet struct {
+	workingDir string
+	rules      []config.PermissionRule
+}
+
+func decisionRank(decision config.PermissionDecision) int {
+	switch decision {
+	case config.PermissionAllow:
+		return 1
+	case config.PermissionAsk:
+		return 2
+	case config.PermissionDeny:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// evaluate returns the rule that decides the request. Deny wins over ask and
+// ask over allow. Commands made of several simple commands are only allowed
+// when every one of them is allowed, but denied or asked for when any of
+// them is.
+func (r ruleSet) evaluate(opts CreatePermissionRequest) (config.PermissionRule, bool) {
+	if len(r.rules) == 0 {
+		return config.PermissionRule{}, false
+	}
+	segments := []string{""}
+	if opts.Command != "" {
+		segments = splitCommand(opts.Command)
+	}
+
+	var decided, allowed config.PermissionRule
+	var hasDecided, hasAllowed bool
+	allAllowed := true
+	for _, segment := range segments {
+		rule, ok := r.match(opts, segment)
+		if !ok {
+			allAllowed = false
+			continue
+		}
+		if rule.Decision == config.PermissionAllow {
+			allowed, hasAllowed = rule, true
+			continue
+		}
+		if !hasDecided || decisionRank(rule.Decision) > decisionRank(decided.Decision) {
+			decided, hasDecided = rule, true
+		}
+	}
+	if hasDecided {
+		return decided, true
+	}
+	if allAllowed && hasAllowed {
+		return allowed, true
+	}
+	return config.PermissionRule{}, false
+}
+
+// match returns the most restrictive rule matching the request, with
+// command being one of the simple commands of a bash call.
+func (r ruleSet) match(opts CreatePermissionRequest, command string) (config.PermissionRule, bool) {
+	var best config.PermissionRule
+	found := false
+	for _, rule := range r.rules {
+		if decisionRank(rule.Decision) == 0 || !r.matches(rule, opts, command) {
+			continue
+		}
+		if !found || decisionRank(rule.Decision) > decisionRank(best.Decision) {
+			best, found = rule, true
+		}
+	}
+	return best, found
+}
+
+func (r ruleSet) matches(rule config.PermissionRule, opts CreatePermissionRequest, command string) bool {
+	if rule.Tool != "" && rule.Tool != "*" && rule.Tool != opts.ToolName {
+		return false
+	}
+	if rule.Path != "" && (opts.FilePath == "" || !r.matchPath(rule.Path, opts.FilePath)) {
+		return false
+	}
+	if rule.Command != "" && (command == "" || !matchCommand(rule.Command, command)) {
+		return false
+	}
+	return true
+}
+
+// matchPath matches a file against a glob. Patterns without a slash match
+// the file name at any depth, relative patterns match the path relative to
+// the working directory.
+func (r ruleSet) matchPath(pattern, file string) bool {
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(r.workingDir, file)
+	}
+	if filepath.IsAbs(pattern) {
+		ok, _ := doublestar.Match(filepath.ToSlash(pattern), filepath.ToSlash(file))
+		return ok
+	}
+	pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+	if !strings.Contains(pattern, "/") {
+		ok, _ := doublestar.Match(pattern, filepath.Base(file))
+		return ok
+	}
+	rel, err := filepath.Rel(r.workingDir, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	ok, _ := doublestar.Match(pattern, filepath.ToSlash(rel))
+	return ok
+}
+
+// matchCommand matches a simple command against a pattern where * matches
+// anything. A trailing " *" also matches the bare command, so "go test *"
+// matches "go test" as well as "go test ./...".
+func matchCommand(pattern, command string) bool {
+	pattern = strings.Join(strings.Fields(pattern), " ")
+	command = strings.Join(strings.Fields(command), " ")
+
+	var suffix string
+	if prefix, ok := strings.CutSuffix(pattern, " *"); ok {
+		pattern = prefix
+		suffix = "( .*)?"
+	}
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	re, err := regexp.Compile("^" + strings.Join(parts, ".*") + suffix + "$")
+	if err != nil {
+		return false
+	}
+	return re.MatchString(command)
+}
+
+// splitCommand returns the simple commands of a shell command line,
+// including those inside pipelines, lists and substitutions. Variable
+// assignments before a command are dropped. Unparsable input is returned as
+// is.
+func splitCommand(command string) []string {
+	file, err := syntax.NewParser().Parse(strings.NewReader(command), "")
+	if err != nil {
+		return []string{command}
+	}
+	printer := syntax.NewPrinter()
+	var segments []string
+	syntax.Walk(file, func(node syntax.Node) bool {
+		call, ok := node.(*syntax.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		words := make([]string, 0, len(call.Args))
+		for _, arg := range call.Args {
+			var sb strings.Builder
+			if err := printer.Print(&sb, arg); err != nil {
+				return true
+			}
+			words = append(words, sb.String())
+		}
+		segments = append(segments, strings.Join(words, " "))
+		return true
+	})
+	if len(segments) == 0 {
+		return []string{command}
+	}
+	return segments
+}
@@ -0,0 +1,112 @@
+package permission
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleSetEvaluate(t *testing.T) {
+	t.Parallel()
+
+	rules := ruleSet{
+		workingDir: "/project",
+		rules: []config.PermissionRule{
+			{Tool: "edit", Path: "internal/**", Decision: config.PermissionAllow},
+			{Path: ".env*", Decision: config.PermissionDeny, Reason: "secrets"},
+			{Tool: "bash", Command: "go test *", Decision: config.PermissionAllow},
+			{Tool: "bash", Command: "ls", Decision: config.PermissionAllow},
+			{Tool: "bash", Command: "git push *", Decision: config.PermissionDeny},
+			{Tool: "bash", Command: "rm *", Decision: config.PermissionAsk},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		opts     CreatePermissionRequest
+		decision config.PermissionDecision
+	}{
+		{"relative path glob", CreatePermissionRequest{ToolName: "edit", FilePath: "/project/internal/app/app.go"}, config.PermissionAllow},
+		{"path outside glob", CreatePermissionRequest{ToolName: "edit", FilePath: "/project/main.go"}, ""},
+		{"other tool", CreatePermissionRequest{ToolName: "write", FilePath: "/project/internal/app/app.go"}, ""},
+		{"file name at any depth", CreatePermissionRequest{ToolName: "view", FilePath: "/project/config/.env.local"}, config.PermissionDeny},
+		{"deny wins over allow", CreatePermissionRequest{ToolName: "edit", FilePath: "/project/internal/.env"}, config.PermissionDeny},
+		{"command prefix", CreatePermissionRequest{ToolName: "bash", Command: "go test ./..."}, config.PermissionAllow},
+		{"bare command", CreatePermissionRequest{ToolName: "bash", Command: "go test"}, config.PermissionAllow},
+		{"command prefix needs word boundary", CreatePermissionRequest{ToolName: "bash", Command: "go testify"}, ""},
+		{"unmatched segment", CreatePermissionRequest{ToolName: "bash", Command: "ls && go test ./... | tee out"}, ""},
+		{"every segment allowed", CreatePermissionRequest{ToolName: "bash", Command: "ls; go test -v ./..."}, config.PermissionAllow},
+		{"denied segment", CreatePermissionRequest{ToolName: "bash", Command: "go test ./... && git push origin main"}, config.PermissionDeny},
+		{"denied substitution", CreatePermissionRequest{ToolName: "bash", Command: "echo $(git push --force)"}, config.PermissionDeny},
+		{"env assignment dropped", CreatePermissionRequest{ToolName: "bash", Command: "CGO_ENABLED=0 go test ./..."}, config.PermissionAllow},
+		{"ask", CreatePermissionRequest{ToolName: "bash", Command: "rm -rf build"}, config.PermissionAsk},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rule, ok := rules.evaluate(tt.opts)
+			if tt.decision == "" {
+				require.False(t, ok, "unexpected rule %+v", rule)
+				return
+			}
+			require.True(t, ok)
+			require.Equal(t, tt.decision, rule.Decision)
+		})
+	}
+}
+
+func TestPermissionService_Rules(t *testing.T) {
+	t.Parallel()
+
+	rules := []config.PermissionRule{
+		{Tool: "bash", Command: "go test *", Decision: config.PermissionAllow},
+		{Tool: "bash", Command: "git push *", Decision: config.PermissionDeny, Reason: "pushing is done by CI"},
+		{Tool: "bash", Command: "rm *", Decision: config.PermissionAsk},
+	}
+
+	t.Run("deny explains the rule", func(t *testing.T) {
+		t.Parallel()
+		service := NewPermissionService("/tmp", false, nil, rules, nil)
+		err := service.Check(CreatePermissionRequest{ToolName: "bash", Command: "git push"})
+		var denied *RuleDeniedError
+		require.True(t, errors.As(err, &denied))
+		require.Contains(t, err.Error(), `"git push *"`)
+		require.Contains(t, err.Error(), "pushing is done by CI")
+
+		require.NoError(t, service.Check(CreatePermissionRequest{ToolName: "bash", Command: "git status"}))
+	})
+
+	t.Run("deny holds in skip mode", func(t *testing.T) {
+		t.Parallel()
+		service := NewPermissionService("/tmp", true, nil, rules, nil)
+		require.False(t, service.Request(CreatePermissionRequest{ToolName: "bash", Command: "git push origin"}))
+		require.True(t, service.Request(CreatePermissionRequest{ToolName: "bash", Command: "git status"}))
+	})
+
+	t.Run("allow skips the prompt", func(t *testing.T) {
+		t.Parallel()
+		service := NewPermissionService("/tmp", false, nil, rules, nil)
+		require.True(t, service.Request(CreatePermissionRequest{ToolName: "bash", Command: "go test ./...", Path: "/tmp"}))
+	})
+
+	t.Run("ask bypasses the allowlist", func(t *testing.T) {
+		t.Parallel()
+		service := NewPermissionService("/tmp", false, []string{"bash"}, rules, nil)
+		events := service.Subscribe(t.Context())
+
+		var granted bool
+		var wg sync.WaitGroup
+		wg.Go(func() {
+			granted = service.Request(CreatePermissionRequest{ToolName: "bash", Command: "rm -rf build", Path: "/tmp"})
+		})
+		event := <-events
+		service.Deny(event.Payload)
+		wg.Wait()
+		require.False(t, granted)
+
+		require.True(t, service.Request(CreatePermissionRequest{ToolName: "bash", Command: "ls", Path: "/tmp"}))
+	})
+}
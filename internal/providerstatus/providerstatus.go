@@ -77,7 +77,7 @@ func CheckHealthURL(ctx context.Context, client *http.Client, prov config.Provid
 
 func applyHealthHeaders(req *http.Request, prov config.ProviderConfig) {
 	switch prov.Type {
-	case catwalk.TypeOpenAI, catwalk.TypeAzure:
+	case catwalk.TypeOpenAI, config.TypeOpenAIResponses, catwalk.TypeAzure:
 		if prov.APIKey != "" {
 			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", prov.APIKey))
 		}
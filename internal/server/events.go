@@ -0,0 +1,189 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/llm/agent"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/session"
+)
+
+const keepAliveInterval = 15 * time.Second
+
+// EventKind identifies the resource an event refers to.
+type EventKind string
+
+const (
+	EventKindSession                EventKind = "session"
+	EventKindMessage                EventKind = "message"
+	EventKindPermissionRequest      EventKind = "permission_request"
+	EventKindPermissionNotification EventKind = "permission_notification"
+	EventKindFile                   EventKind = "file"
+	EventKindAgent                  EventKind = "agent"
+	EventKindLSP                    EventKind = "lsp"
+	EventKindMCP                    EventKind = "mcp"
+	EventKindProviderStatus         EventKind = "provider_status"
+)
+
+// Event is the envelope sent to SSE clients.
+type Event struct {
+	Kind      EventKind        `json:"kind"`
+	Action    pubsub.EventType `json:"action"`
+	SessionID string           `json:"session_id,omitempty"`
+	Payload   any              `json:"payload"`
+}
+
+type agentEventPayload struct {
+	Type      agent.AgentEventType `json:"type"`
+	Message   *message.Message     `json:"message,omitempty"`
+	Error     string               `json:"error,omitempty"`
+	Progress  string               `json:"progress,omitempty"`
+	Done      bool                 `json:"done"`
+	SessionID string               `json:"session_id,omitempty"`
+}
+
+type lspEventPayload struct {
+	Type            app.LSPEventType `json:"type"`
+	Name            string           `json:"name"`
+	State           string           `json:"state"`
+	Error           string           `json:"error,omitempty"`
+	DiagnosticCount int              `json:"diagnostic_count"`
+}
+
+type mcpEventPayload struct {
+	Type      agent.MCPEventType `json:"type"`
+	Name      string             `json:"name"`
+	State     string             `json:"state"`
+	Error     string             `json:"error,omitempty"`
+	ToolCount int                `json:"tool_count"`
+}
+
+// newEvent converts an application event into its wire representation. It
+// returns false for events that are not exposed over the API.
+func newEvent(msg tea.Msg) (Event, bool) {
+	switch ev := msg.(type) {
+	case pubsub.Event[session.Session]:
+		return Event{Kind: EventKindSession, Action: ev.Type, SessionID: ev.Payload.ID, Payload: ev.Payload}, true
+	case pubsub.Event[message.Message]:
+		return Event{Kind: EventKindMessage, Action: ev.Type, SessionID: ev.Payload.SessionID, Payload: ev.Payload}, true
+	case pubsub.Event[permission.PermissionRequest]:
+		return Event{Kind: EventKindPermissionRequest, Action: ev.Type, SessionID: ev.Payload.SessionID, Payload: ev.Payload}, true
+	case pubsub.Event[permission.PermissionNotification]:
+		return Event{Kind: EventKindPermissionNotification, Action: ev.Type, Payload: ev.Payload}, true
+	case pubsub.Event[history.File]:
+		return Event{Kind: EventKindFile, Action: ev.Type, SessionID: ev.Payload.SessionID, Payload: fileEventPayload(ev.Payload)}, true
+	case pubsub.Event[agent.AgentEvent]:
+		payload := agentEventPayload{
+			Type:      ev.Payload.Type,
+			Progress:  ev.Payload.Progress,
+			Done:      ev.Payload.Done,
+			SessionID: ev.Payload.SessionID,
+		}
+		if ev.Payload.Error != nil {
+			payload.Error = ev.Payload.Error.Error()
+		}
+		sessionID := ev.Payload.SessionID
+		if ev.Payload.Message.ID != "" {
+			payload.Message = &ev.Payload.Message
+			sessionID = ev.Payload.Message.SessionID
+		}
+		return Event{Kind: EventKindAgent, Action: ev.Type, SessionID: sessionID, Payload: payload}, true
+	case pubsub.Event[app.LSPEvent]:
+		payload := lspEventPayload{
+			Type:            ev.Payload.Type,
+			Name:            ev.Payload.Name,
+			State:           ev.Payload.State.String(),
+			DiagnosticCount: ev.Payload.DiagnosticCount,
+		}
+		if ev.Payload.Error != nil {
+			payload.Error = ev.Payload.Error.Error()
+		}
+		return Event{Kind: EventKindLSP, Action: ev.Type, Payload: payload}, true
+	case pubsub.Event[agent.MCPEvent]:
+		payload := mcpEventPayload{
+			Type:      ev.Payload.Type,
+			Name:      ev.Payload.Name,
+			State:     ev.Payload.State.String(),
+			ToolCount: ev.Payload.ToolCount,
+		}
+		if ev.Payload.Error != nil {
+			payload.Error = ev.Payload.Error.Error()
+		}
+		return Event{Kind: EventKindMCP, Action: ev.Type, Payload: payload}, true
+	case pubsub.Event[app.ProviderStatus]:
+		return Event{Kind: EventKindProviderStatus, Action: ev.Type, Payload: ev.Payload}, true
+	}
+	return Event{}, false
+}
+
+// fileEventPayload omits file contents, which can be large; clients can
+// fetch them on demand.
+func fileEventPayload(f history.File) map[string]any {
+	return map[string]any{
+		"id":         f.ID,
+		"session_id": f.SessionID,
+		"path":       f.Path,
+		"version":    f.Version,
+		"created_at": f.CreatedAt,
+	}
+}
+
+// handleEvents streams application events as Server-Sent Events. Clients
+// may pass ?session_id= to only receive events for a single session; events
+// that are not tied to a session are always delivered.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming unsupported"))
+		return
+	}
+	sessionID := r.URL.Query().Get("session_id")
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx := r.Context()
+	sub := s.events.Subscribe(ctx)
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case ev, ok := <-sub:
+			if !ok {
+				return
+			}
+			if sessionID != "" && ev.Payload.SessionID != "" && ev.Payload.SessionID != sessionID {
+				continue
+			}
+			data, err := json.Marshal(ev.Payload)
+			if err != nil {
+				slog.Error("Failed to encode event", "kind", ev.Payload.Kind, "error", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Payload.Kind, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/llm/agent"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/permission"
+)
+
+const maxRequestBodySize = 10 << 20
+
+type createSessionRequest struct {
+	Title string `json:"title"`
+}
+
+type promptRequest struct {
+	Prompt string `json:"prompt"`
+}
+
+type promptResponse struct {
+	SessionID string `json:"session_id"`
+	Queued    bool   `json:"queued"`
+}
+
+type grantRequest struct {
+	// Persistent allows matching requests for the rest of the session.
+	Persistent bool `json:"persistent"`
+	// Always allows matching requests in this project from now on.
+	Always bool `json:"always"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":       true,
+		"provider": s.app.ProviderStatus(),
+	})
+}
+
+func (s *Server) handleListSessions(w http.ResponseWriter, r *http.Request) {
+	sessions, err := s.app.Sessions.List(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sessions)
+}
+
+func (s *Server) handleCreateSession(w http.ResponseWriter, r *http.Request) {
+	var req createSessionRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = "New Session"
+	}
+	sess, err := s.app.Sessions.Create(r.Context(), title)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, sess)
+}
+
+func (s *Server) handleGetSession(w http.ResponseWriter, r *http.Request) {
+	sess, err := s.app.Sessions.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeLookupError(w, "session", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sess)
+}
+
+func (s *Server) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if s.app.CoderAgent != nil && s.app.CoderAgent.IsSessionBusy(id) {
+		writeError(w, http.StatusConflict, agent.ErrSessionBusy)
+		return
+	}
+	if err := s.app.Sessions.Delete(r.Context(), id); err != nil {
+		writeLookupError(w, "session", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleListMessages(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := s.app.Sessions.Get(r.Context(), id); err != nil {
+		writeLookupError(w, "session", err)
+		return
+	}
+	msgs, err := s.app.Messages.List(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if msgs == nil {
+		msgs = []message.Message{}
+	}
+	writeJSON(w, http.StatusOK, msgs)
+}
+
+func (s *Server) handlePrompt(w http.ResponseWriter, r *http.Request) {
+	if s.app.CoderAgent == nil {
+		writeError(w, http.StatusServiceUnavailable, errors.New("coder agent is not configured"))
+		return
+	}
+	id := r.PathValue("id")
+	if _, err := s.app.Sessions.Get(r.Context(), id); err != nil {
+		writeLookupError(w, "session", err)
+		return
+	}
+	var req promptRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if strings.TrimSpace(req.Prompt) == "" {
+		writeError(w, http.StatusBadRequest, errors.New("prompt is required"))
+		return
+	}
+
+	// The run outlives the HTTP request, so it is bound to the app context;
+	// progress is reported through the event stream.
+	done, err := s.app.CoderAgent.Run(s.app.Context(), id, req.Prompt)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if done != nil {
+		go func() {
+			result := <-done
+			if result.Error != nil {
+				slog.Debug("Prompt finished with error", "session_id", id, "error", result.Error)
+			}
+		}()
+	}
+	writeJSON(w, http.StatusAccepted, promptResponse{
+		SessionID: id,
+		Queued:    done == nil,
+	})
+}
+
+func (s *Server) handleCancel(w http.ResponseWriter, r *http.Request) {
+	if s.app.CoderAgent == nil {
+		writeError(w, http.StatusServiceUnavailable, errors.New("coder agent is not configured"))
+		return
+	}
+	s.app.CoderAgent.Cancel(r.PathValue("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleListPermissions(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.URL.Query().Get("session_id")
+	requests := []permission.PermissionRequest{}
+	for req := range s.pending.Seq() {
+		if sessionID != "" && req.SessionID != sessionID {
+			continue
+		}
+		requests = append(requests, req)
+	}
+	slices.SortFunc(requests, func(a, b permission.PermissionRequest) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	writeJSON(w, http.StatusOK, requests)
+}
+
+func (s *Server) handleGrantPermission(w http.ResponseWriter, r *http.Request) {
+	req, ok := s.pending.Take(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("permission request not found"))
+		return
+	}
+	var body grantRequest
+	if err := decodeJSON(w, r, &body); err != nil {
+		s.pending.Set(req.ID, req)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	switch {
+	case body.Always:
+		s.app.Permissions.GrantAlways(req)
+	case body.Persistent:
+		s.app.Permissions.GrantPersistent(req)
+	default:
+		s.app.Permissions.Grant(req)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleDenyPermission(w http.ResponseWriter, r *http.Request) {
+	req, ok := s.pending.Take(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("permission request not found"))
+		return
+	}
+	s.app.Permissions.Deny(req)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeJSON decodes an optional JSON body into v. An empty body leaves v
+// untouched.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	if r.Body == nil || r.ContentLength == 0 {
+		return nil
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeLookupError(w http.ResponseWriter, what string, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%s not found", what))
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err)
+}
@@ -0,0 +1,222 @@
+// Package server exposes the application services over a local HTTP/JSON
+// API so that editors and dashboards can drive Crush without the TUI.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/csync"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+// Options configures the HTTP server.
+type Options struct {
+	// Addr is the host:port the server listens on.
+	Addr string
+	// Token, when set, must be sent by clients as a bearer token. It is
+	// required when Addr isn't a loopback address.
+	Token string
+}
+
+// Server serves the Crush HTTP API.
+type Server struct {
+	app    *app.App
+	opts   Options
+	events *pubsub.Broker[Event]
+
+	// Permission requests that are waiting for a decision, keyed by ID.
+	pending *csync.Map[string, permission.PermissionRequest]
+}
+
+// New creates a server for the given application instance.
+func New(app *app.App, opts Options) *Server {
+	return &Server{
+		app:     app,
+		opts:    opts,
+		events:  pubsub.NewBroker[Event](),
+		pending: csync.NewMap[string, permission.PermissionRequest](),
+	}
+}
+
+// Handler returns the HTTP handler with all API routes registered.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/health", s.handleHealth)
+	mux.HandleFunc("GET /v1/events", s.handleEvents)
+
+	mux.HandleFunc("GET /v1/sessions", s.handleListSessions)
+	mux.HandleFunc("POST /v1/sessions", s.handleCreateSession)
+	mux.HandleFunc("GET /v1/sessions/{id}", s.handleGetSession)
+	mux.HandleFunc("DELETE /v1/sessions/{id}", s.handleDeleteSession)
+	mux.HandleFunc("GET /v1/sessions/{id}/messages", s.handleListMessages)
+	mux.HandleFunc("POST /v1/sessions/{id}/prompt", s.handlePrompt)
+	mux.HandleFunc("POST /v1/sessions/{id}/cancel", s.handleCancel)
+
+	mux.HandleFunc("GET /v1/permissions", s.handleListPermissions)
+	mux.HandleFunc("POST /v1/permissions/{id}/grant", s.handleGrantPermission)
+	mux.HandleFunc("POST /v1/permissions/{id}/deny", s.handleDenyPermission)
+
+	return s.checkOrigin(s.authenticate(requireJSON(mux)))
+}
+
+// ListenAndServe starts the server and blocks until ctx is cancelled or the
+// listener fails. It refuses to listen on other than a loopback address
+// without a token.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	if s.opts.Token == "" && !s.loopback() {
+		return fmt.Errorf("refusing to listen on %s without a token", s.opts.Addr)
+	}
+	ln, err := net.Listen("tcp", s.opts.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.opts.Addr, err)
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve accepts connections on ln until ctx is cancelled.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	go s.app.SubscribeHeadless(s.dispatch)
+	defer s.events.Shutdown()
+
+	httpServer := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info("HTTP server listening", "addr", ln.Addr().String())
+		errCh <- httpServer.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		// Close SSE streams first so Shutdown doesn't wait on them.
+		s.events.Shutdown()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("failed to shutdown HTTP server: %w", err)
+		}
+		return nil
+	}
+}
+
+// dispatch receives application events, keeps track of pending permission
+// requests and fans the events out to SSE subscribers.
+func (s *Server) dispatch(msg tea.Msg) {
+	switch ev := msg.(type) {
+	case pubsub.Event[permission.PermissionRequest]:
+		s.pending.Set(ev.Payload.ID, ev.Payload)
+	case pubsub.Event[permission.PermissionNotification]:
+		if ev.Payload.Granted || ev.Payload.Denied {
+			for id, req := range s.pending.Seq2() {
+				if req.ToolCallID == ev.Payload.ToolCallID {
+					s.pending.Del(id)
+				}
+			}
+		}
+	}
+
+	event, ok := newEvent(msg)
+	if !ok {
+		return
+	}
+	s.events.Publish(pubsub.CreatedEvent, event)
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	if s.opts.Token == "" {
+		return next
+	}
+	expected := []byte("Bearer " + s.opts.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(strings.TrimSpace(r.Header.Get("Authorization")))
+		if subtle.ConstantTimeCompare(got, expected) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// checkOrigin rejects requests that browsers send on behalf of other sites.
+// When listening on a loopback address, the Host must be a loopback name too,
+// so that other sites can't reach the server through DNS rebinding.
+func (s *Server) checkOrigin(next http.Handler) http.Handler {
+	loopback := s.loopback()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if loopback && !IsLoopback(hostname(r.Host)) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("host %q is not allowed", r.Host))
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || u.Host != r.Host {
+				writeError(w, http.StatusForbidden, fmt.Errorf("origin %q is not allowed", origin))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireJSON rejects POST requests that aren't sent as JSON, which browsers
+// can't send to other sites without asking first.
+func requireJSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if mediaType != "application/json" {
+				writeError(w, http.StatusUnsupportedMediaType, errors.New("content type must be application/json"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// loopback reports whether the server listens on a loopback address.
+func (s *Server) loopback() bool {
+	return IsLoopback(hostname(s.opts.Addr))
+}
+
+// IsLoopback reports whether host, a host name or an IP address, refers to
+// this machine only.
+func IsLoopback(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// hostname returns the host of a host:port address, which may have no port.
+func hostname(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return strings.Trim(addr, "[]")
+}
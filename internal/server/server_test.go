@@ -0,0 +1,165 @@
+package server
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T, token string) *Server {
+	t.Helper()
+	a := &app.App{
+		Permissions: permission.NewPermissionService(t.TempDir(), false, nil, nil, nil),
+	}
+	return New(a, Options{Addr: "127.0.0.1:7878", Token: token})
+}
+
+func TestAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer(t, "secret")
+	handler := srv.Handler()
+
+	req := httptest.NewRequest(http.MethodGet, "http://127.0.0.1:7878/v1/permissions", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "http://127.0.0.1:7878/v1/permissions", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestCheckOrigin(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t, "").Handler()
+	for _, tt := range []struct {
+		name   string
+		url    string
+		header http.Header
+		want   int
+	}{
+		{"loopback", "http://127.0.0.1:7878/v1/permissions", nil, http.StatusOK},
+		{"localhost", "http://localhost:7878/v1/permissions", nil, http.StatusOK},
+		{"ipv6 loopback", "http://[::1]:7878/v1/permissions", nil, http.StatusOK},
+		{"foreign host", "http://attacker.example:7878/v1/permissions", nil, http.StatusForbidden},
+		{"same origin", "http://localhost:7878/v1/permissions", http.Header{"Origin": {"http://localhost:7878"}}, http.StatusOK},
+		{"foreign origin", "http://localhost:7878/v1/permissions", http.Header{"Origin": {"https://attacker.example"}}, http.StatusForbidden},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			maps.Copy(req.Header, tt.header)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			require.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
+
+func TestRequireJSON(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestServer(t, "").Handler()
+	req := httptest.NewRequest(http.MethodPost, "http://127.0.0.1:7878/v1/permissions/perm-1/deny", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "text/plain")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestListenRequiresToken(t *testing.T) {
+	t.Parallel()
+
+	srv := New(&app.App{}, Options{Addr: "0.0.0.0:0"})
+	require.ErrorContains(t, srv.ListenAndServe(t.Context()), "without a token")
+}
+
+func TestPendingPermissions(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer(t, "")
+	handler := srv.Handler()
+
+	srv.dispatch(pubsub.Event[permission.PermissionRequest]{
+		Type: pubsub.CreatedEvent,
+		Payload: permission.PermissionRequest{
+			ID:         "perm-1",
+			SessionID:  "session-1",
+			ToolCallID: "call-1",
+			ToolName:   "bash",
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "http://127.0.0.1:7878/v1/permissions?session_id=session-1", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var pending []permission.PermissionRequest
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &pending))
+	require.Len(t, pending, 1)
+	require.Equal(t, "perm-1", pending[0].ID)
+
+	req = httptest.NewRequest(http.MethodPost, "http://127.0.0.1:7878/v1/permissions/perm-1/deny", nil)
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "http://127.0.0.1:7878/v1/permissions/perm-1/deny", nil)
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestNewEvent(t *testing.T) {
+	t.Parallel()
+
+	msg := message.Message{
+		ID:        "msg-1",
+		SessionID: "session-1",
+		Role:      message.Assistant,
+		Parts: []message.ContentPart{
+			message.TextContent{Text: "hello"},
+			message.ToolCall{ID: "call-1", Name: "view", Input: `{"file_path":"main.go"}`},
+		},
+	}
+	ev, ok := newEvent(pubsub.Event[message.Message]{Type: pubsub.UpdatedEvent, Payload: msg})
+	require.True(t, ok)
+	require.Equal(t, EventKindMessage, ev.Kind)
+	require.Equal(t, "session-1", ev.SessionID)
+
+	data, err := json.Marshal(ev)
+	require.NoError(t, err)
+
+	var decoded struct {
+		Kind    EventKind       `json:"kind"`
+		Payload message.Message `json:"payload"`
+	}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, msg.ID, decoded.Payload.ID)
+	require.Equal(t, "hello", decoded.Payload.Content().Text)
+	require.Len(t, decoded.Payload.ToolCalls(), 1)
+
+	_, ok = newEvent("unrelated")
+	require.False(t, ok)
+}
@@ -0,0 +1,120 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/google/uuid"
+)
+
+func (s *service) Fork(ctx context.Context, sessionID, atMessageID string) (Session, error) {
+	parent, err := s.Get(ctx, sessionID)
+	if err != nil {
+		return Session{}, err
+	}
+	msgs, err := s.q.ListMessagesBySession(ctx, sessionID)
+	if err != nil {
+		return Session{}, err
+	}
+
+	end := -1
+	for i, msg := range msgs {
+		if msg.ID == atMessageID {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return Session{}, fmt.Errorf("message %s not found in session %s", atMessageID, sessionID)
+	}
+	// Keep the results of the fork point's tool calls, otherwise the forked
+	// conversation would end with unanswered tool calls.
+	for end+1 < len(msgs) && msgs[end+1].Role == string(message.Tool) {
+		end++
+	}
+	msgs = msgs[:end+1]
+
+	dbSession, err := s.q.CreateSession(ctx, db.CreateSessionParams{
+		ID:                  uuid.New().String(),
+		ParentSessionID:     sql.NullString{String: parent.ID, Valid: true},
+		Title:               parent.Title + " (fork)",
+		ForkedFromMessageID: sql.NullString{String: atMessageID, Valid: true},
+	})
+	if err != nil {
+		return Session{}, err
+	}
+	fork := s.fromDBItem(dbSession)
+
+	if err := s.copyForkContent(ctx, parent, fork, msgs); err != nil {
+		// Messages and files are removed along with the session.
+		_ = s.q.DeleteSession(ctx, fork.ID)
+		return Session{}, fmt.Errorf("failed to fork session %s: %w", sessionID, err)
+	}
+
+	fork, err = s.Get(ctx, fork.ID)
+	if err != nil {
+		return Session{}, err
+	}
+	s.Publish(pubsub.CreatedEvent, fork)
+	return fork, nil
+}
+
+// copyForkContent copies msgs and the file versions recorded while they were
+// produced from parent into fork.
+func (s *service) copyForkContent(ctx context.Context, parent, fork Session, msgs []db.Message) error {
+	messageIDs := make(map[string]string, len(msgs))
+	for _, msg := range msgs {
+		id := uuid.New().String()
+		if err := s.q.CopyMessage(ctx, db.CopyMessageParams{
+			ID:        id,
+			SessionID: fork.ID,
+			SourceID:  msg.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to copy message %s: %w", msg.ID, err)
+		}
+		messageIDs[msg.ID] = id
+	}
+
+	files, err := s.q.ListFilesBySession(ctx, parent.ID)
+	if err != nil {
+		return err
+	}
+	cutoff := msgs[len(msgs)-1].CreatedAt
+	for _, file := range files {
+		// Versions recorded for a message are kept along with it. Older
+		// versions don't know their message and go by time instead.
+		messageID, copied := messageIDs[file.MessageID.String]
+		if file.MessageID.Valid && !copied || !file.MessageID.Valid && file.CreatedAt > cutoff {
+			continue
+		}
+		if err := s.q.CopyFile(ctx, db.CopyFileParams{
+			ID:        uuid.New().String(),
+			SessionID: fork.ID,
+			MessageID: sql.NullString{String: messageID, Valid: copied},
+			SourceID:  file.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to copy file %s: %w", file.Path, err)
+		}
+	}
+
+	if summaryID, ok := messageIDs[parent.SummaryMessageID]; ok {
+		_, err := s.q.UpdateSession(ctx, db.UpdateSessionParams{
+			ID:               fork.ID,
+			Title:            fork.Title,
+			SummaryMessageID: sql.NullString{String: summaryID, Valid: true},
+			Mode:             string(fork.Mode),
+			KeepFromMessageID: sql.NullString{
+				String: messageIDs[parent.KeepFromMessageID],
+				Valid:  messageIDs[parent.KeepFromMessageID] != "",
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFork(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	conn, err := db.Connect(ctx, t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	q := db.New(conn)
+	sessions := NewService(q)
+	messages := message.NewService(q)
+
+	parent, err := sessions.Create(ctx, "Parent")
+	require.NoError(t, err)
+
+	create := func(role message.MessageRole, parts ...message.ContentPart) message.Message {
+		msg, err := messages.Create(ctx, parent.ID, message.CreateMessageParams{Role: role, Parts: parts})
+		require.NoError(t, err)
+		return msg
+	}
+	create(message.User, message.TextContent{Text: "edit main.go"})
+	assistant := create(message.Assistant, message.ToolCall{ID: "call-1", Name: "edit", Finished: true})
+	create(message.Tool, message.ToolResult{ToolCallID: "call-1", Content: "done"})
+	create(message.User, message.TextContent{Text: "now undo it"})
+	undo := create(message.Assistant, message.ToolCall{ID: "call-2", Name: "edit", Finished: true})
+
+	_, err = q.CreateFile(ctx, db.CreateFileParams{
+		ID:        "f1",
+		SessionID: parent.ID,
+		Path:      "main.go",
+		Content:   "v0",
+		MessageID: sql.NullString{String: assistant.ID, Valid: true},
+	})
+	require.NoError(t, err)
+	_, err = q.CreateFile(ctx, db.CreateFileParams{
+		ID:        "f2",
+		SessionID: parent.ID,
+		Path:      "main.go",
+		Content:   "v1",
+		Version:   1,
+		MessageID: sql.NullString{String: undo.ID, Valid: true},
+	})
+	require.NoError(t, err)
+	// Versions without a message go by time; this one was written after the
+	// fork point.
+	_, err = q.CreateFile(ctx, db.CreateFileParams{ID: "f3", SessionID: parent.ID, Path: "main.go", Content: "v2", Version: 2})
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, "UPDATE files SET created_at = created_at + 60 WHERE id = 'f3'")
+	require.NoError(t, err)
+
+	fork, err := sessions.Fork(ctx, parent.ID, assistant.ID)
+	require.NoError(t, err)
+	require.True(t, fork.IsFork())
+	require.Equal(t, parent.ID, fork.ParentSessionID)
+	require.Equal(t, assistant.ID, fork.ForkedFromMessageID)
+	require.Equal(t, "Parent (fork)", fork.Title)
+	// The tool result answering the fork point is carried over.
+	require.EqualValues(t, 3, fork.MessageCount)
+
+	forked, err := messages.List(ctx, fork.ID)
+	require.NoError(t, err)
+	require.Len(t, forked, 3)
+	require.Equal(t, "edit main.go", forked[0].Content().Text)
+	require.NotEqual(t, assistant.ID, forked[1].ID)
+	require.Equal(t, "call-1", forked[2].ToolResults()[0].ToolCallID)
+
+	files, err := q.ListFilesBySession(ctx, fork.ID)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, "v0", files[0].Content)
+	require.Equal(t, forked[1].ID, files[0].MessageID.String)
+
+	// Forks are listed alongside top-level sessions.
+	listed, err := sessions.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+
+	_, err = sessions.Fork(ctx, parent.ID, "missing")
+	require.Error(t, err)
+}
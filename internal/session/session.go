@@ -9,17 +9,41 @@ import (
 	"github.com/google/uuid"
 )
 
+// Mode tells which tools and prompt the agent uses in a session.
+type Mode string
+
+const (
+	// ModeNormal lets the agent use all of its tools.
+	ModeNormal Mode = "normal"
+	// ModePlan restricts the agent to read-only tools so it can work out a
+	// plan for the user to approve before any change is made.
+	ModePlan Mode = "plan"
+)
+
 type Session struct {
-	ID               string
-	ParentSessionID  string
-	Title            string
-	MessageCount     int64
-	PromptTokens     int64
-	CompletionTokens int64
-	SummaryMessageID string
-	Cost             float64
-	CreatedAt        int64
-	UpdatedAt        int64
+	ID               string `json:"id"`
+	ParentSessionID  string `json:"parent_session_id,omitempty"`
+	Title            string `json:"title"`
+	MessageCount     int64  `json:"message_count"`
+	PromptTokens     int64  `json:"prompt_tokens"`
+	CompletionTokens int64  `json:"completion_tokens"`
+	SummaryMessageID string `json:"summary_message_id,omitempty"`
+	// KeepFromMessageID is the first message before the summary that is
+	// still sent to the model verbatim. It is set when a session is
+	// compacted and empty when the summary replaces all earlier messages.
+	KeepFromMessageID string `json:"keep_from_message_id,omitempty"`
+	// ForkedFromMessageID is the message in the parent session this session
+	// was forked from. It is empty for sessions that are not forks.
+	ForkedFromMessageID string  `json:"forked_from_message_id,omitempty"`
+	Cost                float64 `json:"cost"`
+	Mode                Mode    `json:"mode"`
+	CreatedAt           int64   `json:"created_at"`
+	UpdatedAt           int64   `json:"updated_at"`
+}
+
+// IsFork reports whether the session was forked from another session.
+func (s Session) IsFork() bool {
+	return s.ForkedFromMessageID != ""
 }
 
 type Service interface {
@@ -31,6 +55,9 @@ type Service interface {
 	List(ctx context.Context) ([]Session, error)
 	Save(ctx context.Context, session Session) (Session, error)
 	Delete(ctx context.Context, id string) error
+	// Fork creates a new session containing the messages and file history
+	// of sessionID up to and including atMessageID.
+	Fork(ctx context.Context, sessionID, atMessageID string) (Session, error)
 }
 
 type service struct {
@@ -111,6 +138,11 @@ func (s *service) Save(ctx context.Context, session Session) (Session, error) {
 			Valid:  session.SummaryMessageID != "",
 		},
 		Cost: session.Cost,
+		Mode: string(session.Mode),
+		KeepFromMessageID: sql.NullString{
+			String: session.KeepFromMessageID,
+			Valid:  session.KeepFromMessageID != "",
+		},
 	})
 	if err != nil {
 		return Session{}, err
@@ -134,16 +166,19 @@ func (s *service) List(ctx context.Context) ([]Session, error) {
 
 func (s service) fromDBItem(item db.Session) Session {
 	return Session{
-		ID:               item.ID,
-		ParentSessionID:  item.ParentSessionID.String,
-		Title:            item.Title,
-		MessageCount:     item.MessageCount,
-		PromptTokens:     item.PromptTokens,
-		CompletionTokens: item.CompletionTokens,
-		SummaryMessageID: item.SummaryMessageID.String,
-		Cost:             item.Cost,
-		CreatedAt:        item.CreatedAt,
-		UpdatedAt:        item.UpdatedAt,
+		ID:                  item.ID,
+		ParentSessionID:     item.ParentSessionID.String,
+		Title:               item.Title,
+		MessageCount:        item.MessageCount,
+		PromptTokens:        item.PromptTokens,
+		CompletionTokens:    item.CompletionTokens,
+		SummaryMessageID:    item.SummaryMessageID.String,
+		KeepFromMessageID:   item.KeepFromMessageID.String,
+		ForkedFromMessageID: item.ForkedFromMessageID.String,
+		Cost:                item.Cost,
+		Mode:                Mode(item.Mode),
+		CreatedAt:           item.CreatedAt,
+		UpdatedAt:           item.UpdatedAt,
 	}
 }
 
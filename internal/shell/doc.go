@@ -28,3 +28,11 @@ package shell
 //	shell.SetWorkingDir("/tmp")
 //	cwd := shell.GetWorkingDir()
 //	env := shell.GetEnv()
+//
+// 5. Restricting what programs may write to on Linux:
+//
+//	shell := shell.NewShell(&shell.Options{
+//	    WorkingDir: "/path/to/project",
+//	    Sandbox: &shell.Sandbox{WritableDirs: []string{"/path/to/project"}},
+//	})
+//	shell.Exec(ctx, "touch /etc/passwd") // Fails with a permission error
@@ -0,0 +1,153 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+
+	"mvdan.cc/sh/v3/expand"
+	"mvdan.cc/sh/v3/interp"
+)
+
+// sandboxArg is the argument the binary is run again with to start a program
+// in the sandbox. See runSandboxed.
+const sandboxArg = "__crush_sandbox"
+
+// writableDevices are the files outside of the writable directories that
+// sandboxed programs may still write to.
+var writableDevices = []string{"/dev/null", "/dev/zero", "/dev/tty"}
+
+// Sandbox restricts what the programs a shell runs may do. Programs may read
+// any file, but only write to the temporary directories and WritableDirs.
+//
+// Sandboxing is only supported on Linux, where it uses Landlock for the file
+// system and a network namespace to block the network.
+type Sandbox struct {
+	// WritableDirs are the directories, besides the temporary directories,
+	// that programs may write to.
+	WritableDirs []string `json:"writable_dirs"`
+	// BlockNetwork cuts programs off from the network.
+	BlockNetwork bool `json:"block_network"`
+}
+
+// writableDirs returns the directories programs may write to.
+func (sb *Sandbox) writableDirs() []string {
+	dirs := slices.Clone(sb.WritableDirs)
+	for _, dir := range []string{os.TempDir(), "/tmp", "/var/tmp", "/dev/shm"} {
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// AllowsWrite reports whether programs may write to path, which must be
+// absolute. Symbolic links are resolved first, so that a link in a writable
+// directory can't be used to write somewhere else.
+func (sb *Sandbox) AllowsWrite(path string) bool {
+	path = resolvePath(filepath.Clean(path))
+	if slices.Contains(writableDevices, path) {
+		return true
+	}
+	for _, dir := range sb.writableDirs() {
+		dir = resolvePath(filepath.Clean(dir))
+		if path == dir || strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath resolves the symbolic links in path. When path doesn't exist,
+// the links in its closest parent that does are resolved.
+func resolvePath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path
+	}
+	return filepath.Join(resolvePath(parent), filepath.Base(path))
+}
+
+// Command changes cmd, which has not been started yet, so that it runs in the
+// sandbox. It fails when sandboxing is not supported.
+func (sb *Sandbox) Command(cmd *exec.Cmd) error {
+	if cmd.Err != nil {
+		return cmd.Err
+	}
+	return sb.command(cmd)
+}
+
+// openHandler denies the interpreter's redirections to files that programs
+// may not write to.
+func (sb *Sandbox) openHandler() interp.OpenHandlerFunc {
+	open := interp.DefaultOpenHandler()
+	return func(ctx context.Context, path string, flag int, perm os.FileMode) (io.ReadWriteCloser, error) {
+		if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_APPEND|os.O_TRUNC) != 0 {
+			abs := path
+			if !filepath.IsAbs(abs) {
+				abs = filepath.Join(interp.HandlerCtx(ctx).Dir, abs)
+			}
+			if !sb.AllowsWrite(abs) {
+				return nil, &os.PathError{Op: "open", Path: path, Err: errors.New("not writable in the sandbox")}
+			}
+		}
+		return open(ctx, path, flag, perm)
+	}
+}
+
+// execHandler runs programs in the sandbox. It replaces the interpreter's
+// default handler, which it otherwise behaves like.
+func (sb *Sandbox) execHandler() interp.ExecHandlerFunc {
+	return func(ctx context.Context, args []string) error {
+		hc := interp.HandlerCtx(ctx)
+		path, err := interp.LookPathDir(hc.Dir, hc.Env, args[0])
+		if err != nil {
+			fmt.Fprintln(hc.Stderr, err)
+			return interp.ExitStatus(127)
+		}
+
+		var env []string
+		for name, vr := range hc.Env.Each {
+			if vr.Exported && vr.Kind == expand.String {
+				env = append(env, name+"="+vr.Str)
+			}
+		}
+		cmd := exec.CommandContext(ctx, path, args[1:]...)
+		cmd.Args[0] = args[0]
+		cmd.Env = env
+		cmd.Dir = hc.Dir
+		cmd.Stdin = hc.Stdin
+		cmd.Stdout = hc.Stdout
+		cmd.Stderr = hc.Stderr
+		if err := sb.command(cmd); err != nil {
+			fmt.Fprintf(hc.Stderr, "sandbox: %v\n", err)
+			return interp.ExitStatus(126)
+		}
+
+		err = cmd.Run()
+		var exitErr *exec.ExitError
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return ctx.Err()
+		case errors.As(err, &exitErr):
+			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+				return interp.ExitStatus(128 + int(status.Signal()))
+			}
+			return interp.ExitStatus(exitErr.ExitCode())
+		case err != nil:
+			fmt.Fprintln(hc.Stderr, err)
+			return interp.ExitStatus(127)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,170 @@
+//go:build linux
+
+package shell
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Sandboxed programs are started by running the binary again with
+// sandboxArg, the sandbox and the program to run. It restricts itself with
+// Landlock, which applies to the thread that asks for it, and then replaces
+// itself with the program from that same thread, so the program inherits the
+// restrictions.
+func init() {
+	if len(os.Args) > 1 && os.Args[1] == sandboxArg {
+		runtime.LockOSThread()
+		err := runSandboxed(os.Args[2:])
+		fmt.Fprintf(os.Stderr, "sandbox: %v\n", err)
+		os.Exit(126)
+	}
+}
+
+// landlockWriteAccess are the Landlock file system rights that are denied
+// outside of the writable directories, for each version of the Landlock ABI.
+var landlockWriteAccess = []uint64{
+	1: unix.LANDLOCK_ACCESS_FS_WRITE_FILE |
+		unix.LANDLOCK_ACCESS_FS_REMOVE_DIR |
+		unix.LANDLOCK_ACCESS_FS_REMOVE_FILE |
+		unix.LANDLOCK_ACCESS_FS_MAKE_CHAR |
+		unix.LANDLOCK_ACCESS_FS_MAKE_DIR |
+		unix.LANDLOCK_ACCESS_FS_MAKE_REG |
+		unix.LANDLOCK_ACCESS_FS_MAKE_SOCK |
+		unix.LANDLOCK_ACCESS_FS_MAKE_FIFO |
+		unix.LANDLOCK_ACCESS_FS_MAKE_BLOCK |
+		unix.LANDLOCK_ACCESS_FS_MAKE_SYM,
+	2: unix.LANDLOCK_ACCESS_FS_REFER,
+	3: unix.LANDLOCK_ACCESS_FS_TRUNCATE,
+}
+
+// landlockFileAccess are the Landlock rights that apply to files rather than
+// directories.
+const landlockFileAccess = unix.LANDLOCK_ACCESS_FS_WRITE_FILE | unix.LANDLOCK_ACCESS_FS_TRUNCATE
+
+// landlockABI returns the version of the Landlock ABI the kernel supports.
+var landlockABI = sync.OnceValues(func() (int, error) {
+	abi, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, 0, 0, unix.LANDLOCK_CREATE_RULESET_VERSION)
+	if errno != 0 {
+		return 0, fmt.Errorf("landlock is not available: %w", errno)
+	}
+	return int(abi), nil
+})
+
+func (sb *Sandbox) command(cmd *exec.Cmd) error {
+	if _, err := landlockABI(); err != nil {
+		return err
+	}
+	policy, err := json.Marshal(Sandbox{WritableDirs: sb.writableDirs()})
+	if err != nil {
+		return err
+	}
+
+	cmd.Args = append([]string{os.Args[0], sandboxArg, string(policy), cmd.Path}, cmd.Args...)
+	cmd.Path = "/proc/self/exe"
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	// Programs may start others; stop them all with the command.
+	cmd.SysProcAttr.Setpgid = true
+	if cmd.Cancel != nil {
+		cmd.Cancel = func() error {
+			return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		}
+	}
+	if sb.BlockNetwork {
+		// A new network namespace only has a loopback interface, which is
+		// down. Creating one needs a user namespace, in which the user keeps
+		// their IDs.
+		cmd.SysProcAttr.Cloneflags |= syscall.CLONE_NEWUSER | syscall.CLONE_NEWNET
+		cmd.SysProcAttr.UidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}}
+		cmd.SysProcAttr.GidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}}
+	}
+	return nil
+}
+
+// runSandboxed restricts writes to the directories of the sandbox in args[0]
+// and runs the program at args[1] with the arguments args[2:]. It only
+// returns on errors.
+func runSandboxed(args []string) error {
+	if len(args) < 3 {
+		return errors.New("missing program")
+	}
+	var sb Sandbox
+	if err := json.Unmarshal([]byte(args[0]), &sb); err != nil {
+		return err
+	}
+	if err := restrictWrites(sb.WritableDirs); err != nil {
+		return err
+	}
+	return syscall.Exec(args[1], args[2:], os.Environ())
+}
+
+// restrictWrites denies the current thread, and the programs it executes,
+// writes outside of dirs and writableDevices.
+func restrictWrites(dirs []string) error {
+	abi, err := landlockABI()
+	if err != nil {
+		return err
+	}
+	var access uint64
+	for _, a := range landlockWriteAccess[:min(abi+1, len(landlockWriteAccess))] {
+		access |= a
+	}
+
+	attr := unix.LandlockRulesetAttr{Access_fs: access}
+	ruleset, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr), 0)
+	if errno != 0 {
+		return fmt.Errorf("creating landlock ruleset: %w", errno)
+	}
+	defer unix.Close(int(ruleset))
+
+	for _, path := range slices.Concat(dirs, writableDevices) {
+		if err := addLandlockRule(ruleset, path, access); err != nil {
+			return fmt.Errorf("allowing writes to %s: %w", path, err)
+		}
+	}
+
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("setting no_new_privs: %w", err)
+	}
+	if _, _, errno := unix.Syscall(unix.SYS_LANDLOCK_RESTRICT_SELF, ruleset, 0, 0); errno != 0 {
+		return fmt.Errorf("enforcing landlock ruleset: %w", errno)
+	}
+	return nil
+}
+
+// addLandlockRule allows access beneath path. Paths that don't exist are
+// skipped.
+func addLandlockRule(ruleset uintptr, path string, access uint64) error {
+	fd, err := unix.Open(path, unix.O_PATH|unix.O_CLOEXEC, 0)
+	if errors.Is(err, unix.ENOENT) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	var st unix.Stat_t
+	if err := unix.Fstat(fd, &st); err != nil {
+		return err
+	}
+	if st.Mode&unix.S_IFMT != unix.S_IFDIR {
+		access &= landlockFileAccess
+	}
+	attr := unix.LandlockPathBeneathAttr{Allowed_access: access, Parent_fd: int32(fd)}
+	if _, _, errno := unix.Syscall6(unix.SYS_LANDLOCK_ADD_RULE, ruleset, unix.LANDLOCK_RULE_PATH_BENEATH, uintptr(unsafe.Pointer(&attr)), 0, 0, 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
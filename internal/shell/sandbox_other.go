@@ -0,0 +1,12 @@
+//go:build !linux
+
+package shell
+
+import (
+	"errors"
+	"os/exec"
+)
+
+func (sb *Sandbox) command(*exec.Cmd) error {
+	return errors.New("sandboxing commands is only supported on Linux")
+}
@@ -0,0 +1,102 @@
+package shell
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// outsideDir returns a directory the sandbox doesn't make writable, which
+// can't be in the temporary directories.
+func outsideDir(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp(".", "sandbox-test-")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	abs, err := filepath.Abs(dir)
+	require.NoError(t, err)
+	return abs
+}
+
+func TestSandboxAllowsWrite(t *testing.T) {
+	t.Parallel()
+
+	work := t.TempDir()
+	outside := outsideDir(t)
+	require.NoError(t, os.Symlink(outside, filepath.Join(work, "escape")))
+	sb := &Sandbox{WritableDirs: []string{work}}
+
+	require.True(t, sb.AllowsWrite(work))
+	require.True(t, sb.AllowsWrite(filepath.Join(work, "new", "file.txt")))
+	require.True(t, sb.AllowsWrite(filepath.Join(os.TempDir(), "file.txt")))
+	require.True(t, sb.AllowsWrite("/dev/null"))
+	require.False(t, sb.AllowsWrite(filepath.Join(outside, "file.txt")))
+	require.False(t, sb.AllowsWrite(filepath.Join(work, "escape", "file.txt")))
+	require.False(t, (&Sandbox{WritableDirs: []string{outside}}).AllowsWrite(outside+"x"))
+}
+
+func TestSandboxExec(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("sandboxing is only supported on Linux")
+	}
+	sb := &Sandbox{}
+	if err := sb.Command(exec.Command("true")); err != nil {
+		t.Skipf("sandboxing is not available: %v", err)
+	}
+
+	work := t.TempDir()
+	outside := outsideDir(t)
+	shell := NewShell(&Options{WorkingDir: work, Sandbox: &Sandbox{WritableDirs: []string{work}}})
+
+	t.Run("programs write to the working directory", func(t *testing.T) {
+		_, stderr, err := shell.Exec(t.Context(), "touch inside.txt && mkdir dir && echo ok > /dev/null")
+		require.NoError(t, err, stderr)
+		require.FileExists(t, filepath.Join(work, "inside.txt"))
+	})
+
+	t.Run("programs can't write elsewhere", func(t *testing.T) {
+		_, _, err := shell.Exec(t.Context(), "touch "+filepath.Join(outside, "outside.txt"))
+		require.Equal(t, 1, ExitCode(err))
+		require.NoFileExists(t, filepath.Join(outside, "outside.txt"))
+	})
+
+	t.Run("redirections can't write elsewhere", func(t *testing.T) {
+		_, stderr, err := shell.Exec(t.Context(), "echo hi > "+filepath.Join(outside, "outside.txt"))
+		require.Error(t, err)
+		require.Contains(t, stderr, "not writable in the sandbox")
+		require.NoFileExists(t, filepath.Join(outside, "outside.txt"))
+	})
+
+	t.Run("exit codes and output", func(t *testing.T) {
+		stdout, _, err := shell.Exec(t.Context(), "sh -c 'echo out; exit 3'")
+		require.Equal(t, 3, ExitCode(err))
+		require.Equal(t, "out\n", stdout)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+		defer cancel()
+		_, _, err := shell.Exec(ctx, "sleep 10")
+		require.True(t, IsInterrupt(err))
+	})
+
+	t.Run("network", func(t *testing.T) {
+		shell := NewShell(&Options{WorkingDir: work, Sandbox: &Sandbox{BlockNetwork: true}})
+		stdout, stderr, err := shell.Exec(t.Context(), "cat /proc/net/dev")
+		if ExitCode(err) == 127 && strings.Contains(stderr, "operation not permitted") {
+			t.Skip("user namespaces are not available")
+		}
+		require.NoError(t, err, stderr)
+		// Only the loopback interface is left, after the two header lines.
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		require.Len(t, lines, 3)
+		require.Contains(t, lines[2], "lo:")
+	})
+}
@@ -51,6 +51,7 @@ type Shell struct {
 	mu         sync.Mutex
 	logger     Logger
 	blockFuncs []BlockFunc
+	sandbox    *Sandbox
 }
 
 // Options for creating a new shell
@@ -59,6 +60,8 @@ type Options struct {
 	Env        []string
 	Logger     Logger
 	BlockFuncs []BlockFunc
+	// Sandbox, when set, restricts what the programs the shell runs may do.
+	Sandbox *Sandbox
 }
 
 // NewShell creates a new shell instance with the given options
@@ -87,6 +90,7 @@ func NewShell(opts *Options) *Shell {
 		env:        env,
 		logger:     logger,
 		blockFuncs: opts.BlockFuncs,
+		sandbox:    opts.Sandbox,
 	}
 }
 
@@ -152,6 +156,22 @@ func (s *Shell) SetBlockFuncs(blockFuncs []BlockFunc) {
 	s.blockFuncs = blockFuncs
 }
 
+// SetSandbox sets the sandbox programs are run in, or turns sandboxing off
+// when sandbox is nil.
+func (s *Shell) SetSandbox(sandbox *Sandbox) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sandbox = sandbox
+}
+
+// Sandbox returns the sandbox programs are run in, or nil when sandboxing is
+// off.
+func (s *Shell) Sandbox() *Sandbox {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sandbox
+}
+
 // CommandsBlocker creates a BlockFunc that blocks exact command matches
 func CommandsBlocker(cmds []string) BlockFunc {
 	bannedSet := make(map[string]struct{})
@@ -231,13 +251,25 @@ func (s *Shell) execPOSIX(ctx context.Context, command string) (string, string,
 	}
 
 	var stdout, stderr bytes.Buffer
-	runner, err := interp.New(
+	opts := []interp.RunnerOption{
 		interp.StdIO(nil, &stdout, &stderr),
 		interp.Interactive(false),
 		interp.Env(expand.ListEnviron(s.env...)),
 		interp.Dir(s.cwd),
-		interp.ExecHandlers(s.blockHandler(), coreutils.ExecHandler),
-	)
+	}
+	if s.sandbox != nil {
+		// The core utils run in this process, out of the sandbox's reach, so
+		// the system's are used instead.
+		opts = append(opts,
+			interp.ExecHandlers(s.blockHandler(), func(interp.ExecHandlerFunc) interp.ExecHandlerFunc {
+				return s.sandbox.execHandler()
+			}),
+			interp.OpenHandler(s.sandbox.openHandler()),
+		)
+	} else {
+		opts = append(opts, interp.ExecHandlers(s.blockHandler(), coreutils.ExecHandler))
+	}
+	runner, err := interp.New(opts...)
 	if err != nil {
 		return "", "", fmt.Errorf("could not run command: %w", err)
 	}
@@ -0,0 +1,122 @@
+package todo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/google/uuid"
+)
+
+// Status is how far along a task is.
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusInProgress Status = "in_progress"
+	StatusCompleted  Status = "completed"
+)
+
+// Valid reports whether s is a known status.
+func (s Status) Valid() bool {
+	switch s {
+	case StatusPending, StatusInProgress, StatusCompleted:
+		return true
+	}
+	return false
+}
+
+// Todo is a task on the task list of a session.
+type Todo struct {
+	Content string `json:"content"`
+	Status  Status `json:"status"`
+}
+
+// List is the task list of a session, published whenever it changes.
+type List struct {
+	SessionID string
+	Todos     []Todo
+}
+
+type Service interface {
+	pubsub.Suscriber[List]
+	// Get returns the task list of a session, in order.
+	Get(ctx context.Context, sessionID string) ([]Todo, error)
+	// Set replaces the task list of a session.
+	Set(ctx context.Context, sessionID string, todos []Todo) error
+}
+
+type service struct {
+	*pubsub.Broker[List]
+	db *sql.DB
+	q  *db.Queries
+}
+
+func NewService(q *db.Queries, db *sql.DB) Service {
+	return &service{
+		Broker: pubsub.NewBroker[List](),
+		q:      q,
+		db:     db,
+	}
+}
+
+func (s *service) Get(ctx context.Context, sessionID string) ([]Todo, error) {
+	dbTodos, err := s.q.ListTodosBySession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	todos := make([]Todo, len(dbTodos))
+	for i, dbTodo := range dbTodos {
+		todos[i] = Todo{Content: dbTodo.Content, Status: Status(dbTodo.Status)}
+	}
+	return todos, nil
+}
+
+func (s *service) Set(ctx context.Context, sessionID string, todos []Todo) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	qtx := s.q.WithTx(tx)
+	if err := qtx.DeleteSessionTodos(ctx, sessionID); err != nil {
+		return err
+	}
+	for i, todo := range todos {
+		if err := qtx.CreateTodo(ctx, db.CreateTodoParams{
+			ID:        uuid.New().String(),
+			SessionID: sessionID,
+			Position:  int64(i),
+			Content:   todo.Content,
+			Status:    string(todo.Status),
+		}); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.Publish(pubsub.UpdatedEvent, List{SessionID: sessionID, Todos: todos})
+	return nil
+}
+
+// Format renders todos as a Markdown checklist.
+func Format(todos []Todo) string {
+	var b strings.Builder
+	for _, todo := range todos {
+		switch todo.Status {
+		case StatusCompleted:
+			fmt.Fprintf(&b, "- [x] %s\n", todo.Content)
+		case StatusInProgress:
+			fmt.Fprintf(&b, "- [ ] %s (in progress)\n", todo.Content)
+		default:
+			fmt.Fprintf(&b, "- [ ] %s\n", todo.Content)
+		}
+	}
+	return b.String()
+}
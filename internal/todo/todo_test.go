@@ -0,0 +1,46 @@
+package todo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/db"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSet(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	conn, err := db.Connect(ctx, t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	q := db.New(conn)
+	sess, err := session.NewService(q).Create(ctx, "Todos")
+	require.NoError(t, err)
+	todos := NewService(q, conn)
+
+	events := todos.Subscribe(t.Context())
+	list := []Todo{
+		{Content: "Add the table", Status: StatusCompleted},
+		{Content: "Add the tool", Status: StatusInProgress},
+		{Content: "Render the list", Status: StatusPending},
+	}
+	require.NoError(t, todos.Set(ctx, sess.ID, list))
+	event := <-events
+	require.Equal(t, sess.ID, event.Payload.SessionID)
+	require.Equal(t, list, event.Payload.Todos)
+
+	got, err := todos.Get(ctx, sess.ID)
+	require.NoError(t, err)
+	require.Equal(t, list, got)
+
+	// Setting the list again replaces it.
+	require.NoError(t, todos.Set(ctx, sess.ID, list[1:]))
+	got, err = todos.Get(ctx, sess.ID)
+	require.NoError(t, err)
+	require.Equal(t, list[1:], got)
+
+	require.Equal(t, "- [ ] Add the tool (in progress)\n- [ ] Render the list\n", Format(got))
+}
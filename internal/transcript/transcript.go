@@ -0,0 +1,195 @@
+// Package transcript renders a session and its messages into shareable
+// formats such as Markdown and JSON.
+package transcript
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+)
+
+// Format is a transcript output format.
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatJSON     Format = "json"
+)
+
+// ParseFormat validates a format name. "md" is accepted as an alias for
+// Markdown.
+func ParseFormat(value string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "md", string(FormatMarkdown):
+		return FormatMarkdown, nil
+	case string(FormatJSON):
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("invalid format %q (expected markdown or json)", value)
+	}
+}
+
+// Transcript is the JSON representation of an exported session.
+type Transcript struct {
+	Session  session.Session   `json:"session"`
+	Messages []message.Message `json:"messages"`
+}
+
+// Write renders the session in the given format.
+func Write(w io.Writer, format Format, sess session.Session, msgs []message.Message) error {
+	switch format {
+	case FormatJSON:
+		return WriteJSON(w, sess, msgs)
+	case FormatMarkdown:
+		return WriteMarkdown(w, sess, msgs)
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// WriteJSON renders the session and its messages as indented JSON.
+func WriteJSON(w io.Writer, sess session.Session, msgs []message.Message) error {
+	if msgs == nil {
+		msgs = []message.Message{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(Transcript{Session: sess, Messages: msgs})
+}
+
+// WriteMarkdown renders the session as a Markdown document including
+// reasoning, tool calls and tool results.
+func WriteMarkdown(w io.Writer, sess session.Session, msgs []message.Message) error {
+	var b bytes.Buffer
+
+	title := sess.Title
+	if title == "" {
+		title = "Untitled session"
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "- **Session:** `%s`\n", sess.ID)
+	if sess.IsFork() {
+		fmt.Fprintf(&b, "- **Forked from:** `%s` at message `%s`\n", sess.ParentSessionID, sess.ForkedFromMessageID)
+	}
+	if sess.CreatedAt > 0 {
+		fmt.Fprintf(&b, "- **Created:** %s\n", formatTime(sess.CreatedAt))
+	}
+	fmt.Fprintf(&b, "- **Messages:** %d\n", len(msgs))
+	fmt.Fprintf(&b, "- **Tokens:** %d prompt, %d completion\n", sess.PromptTokens, sess.CompletionTokens)
+	fmt.Fprintf(&b, "- **Cost:** $%.4f\n", sess.Cost)
+
+	toolNames := make(map[string]string)
+	for _, msg := range msgs {
+		for _, tc := range msg.ToolCalls() {
+			toolNames[tc.ID] = tc.Name
+		}
+	}
+
+	for _, msg := range msgs {
+		b.WriteString("\n---\n\n")
+		writeMessage(&b, sess, msg, toolNames)
+	}
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func writeMessage(b *bytes.Buffer, sess session.Session, msg message.Message, toolNames map[string]string) {
+	switch {
+	case msg.ID != "" && msg.ID == sess.SummaryMessageID:
+		b.WriteString("## Summary\n\n")
+	case msg.Role == message.User:
+		b.WriteString("## User\n\n")
+	case msg.Role == message.Assistant:
+		b.WriteString("## Assistant")
+		if msg.Model != "" {
+			fmt.Fprintf(b, " (`%s`)", msg.Model)
+		}
+		b.WriteString("\n\n")
+	case msg.Role == message.Tool:
+		b.WriteString("## Tool results\n\n")
+	default:
+		fmt.Fprintf(b, "## %s\n\n", msg.Role)
+	}
+
+	if reasoning := msg.ReasoningContent().Thinking; strings.TrimSpace(reasoning) != "" {
+		b.WriteString("<details>\n<summary>Reasoning</summary>\n\n")
+		b.WriteString(strings.TrimSpace(reasoning))
+		b.WriteString("\n\n</details>\n\n")
+	}
+
+	if text := strings.TrimSpace(msg.Content().Text); text != "" {
+		b.WriteString(text)
+		b.WriteString("\n\n")
+	}
+
+	for _, bin := range msg.BinaryContent() {
+		fmt.Fprintf(b, "_Attachment: `%s` (%s)_\n\n", bin.Path, bin.MIMEType)
+	}
+
+	for _, tc := range msg.ToolCalls() {
+		fmt.Fprintf(b, "### Tool call: `%s`\n\n", tc.Name)
+		writeCodeBlock(b, "json", prettyJSON(tc.Input))
+	}
+
+	for _, tr := range msg.ToolResults() {
+		name := tr.Name
+		if name == "" {
+			name = toolNames[tr.ToolCallID]
+		}
+		if name == "" {
+			name = tr.ToolCallID
+		}
+		fmt.Fprintf(b, "### Tool result: `%s`", name)
+		if tr.IsError {
+			b.WriteString(" (error)")
+		}
+		b.WriteString("\n\n")
+		writeCodeBlock(b, "", tr.Content)
+	}
+
+	if finish := msg.FinishPart(); finish != nil && msg.Role == message.Assistant {
+		switch finish.Reason {
+		case message.FinishReasonError, message.FinishReasonCanceled, message.FinishReasonPermissionDenied:
+			fmt.Fprintf(b, "> _Finished: %s", finish.Reason)
+			if finish.Message != "" {
+				fmt.Fprintf(b, " (%s)", finish.Message)
+			}
+			b.WriteString("_\n\n")
+		}
+	}
+}
+
+// writeCodeBlock writes content in a fenced code block, using a fence that
+// is longer than any backtick run inside the content.
+func writeCodeBlock(b *bytes.Buffer, lang, content string) {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	b.WriteString(fence)
+	b.WriteString(lang)
+	b.WriteString("\n")
+	b.WriteString(strings.TrimRight(content, "\n"))
+	b.WriteString("\n")
+	b.WriteString(fence)
+	b.WriteString("\n\n")
+}
+
+func prettyJSON(s string) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(s), "", "  "); err != nil {
+		return s
+	}
+	return buf.String()
+}
+
+func formatTime(unix int64) string {
+	return time.Unix(unix, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,87 @@
+package transcript
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/charmbracelet/crush/internal/session"
+	"github.com/stretchr/testify/require"
+)
+
+func testTranscript() (session.Session, []message.Message) {
+	sess := session.Session{ID: "s1", Title: "Fix the build", PromptTokens: 10, CompletionTokens: 5}
+	msgs := []message.Message{
+		{
+			ID:    "m1",
+			Role:  message.User,
+			Parts: []message.ContentPart{message.TextContent{Text: "Why does the build fail?"}},
+		},
+		{
+			ID:    "m2",
+			Role:  message.Assistant,
+			Model: "gpt-4o",
+			Parts: []message.ContentPart{
+				message.ReasoningContent{Thinking: "Look at go.mod first."},
+				message.TextContent{Text: "Let me check."},
+				message.ToolCall{ID: "c1", Name: "view", Input: `{"file_path":"go.mod"}`, Finished: true},
+			},
+		},
+		{
+			ID:    "m3",
+			Role:  message.Tool,
+			Parts: []message.ContentPart{message.ToolResult{ToolCallID: "c1", Content: "```\nmodule x\n```"}},
+		},
+	}
+	return sess, msgs
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	t.Parallel()
+
+	sess, msgs := testTranscript()
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatMarkdown, sess, msgs))
+	out := buf.String()
+
+	require.True(t, strings.HasPrefix(out, "# Fix the build\n"))
+	require.Contains(t, out, "## User\n\nWhy does the build fail?")
+	require.Contains(t, out, "## Assistant (`gpt-4o`)")
+	require.Contains(t, out, "<summary>Reasoning</summary>\n\nLook at go.mod first.")
+	require.Contains(t, out, "### Tool call: `view`\n\n```json\n{\n  \"file_path\": \"go.mod\"\n}\n```")
+	// The result name is resolved from the call, and the fence is widened
+	// so backticks in the content do not terminate it.
+	require.Contains(t, out, "### Tool result: `view`\n\n````\n```\nmodule x\n```\n````")
+}
+
+func TestWriteJSON(t *testing.T) {
+	t.Parallel()
+
+	sess, msgs := testTranscript()
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatJSON, sess, msgs))
+
+	var decoded Transcript
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Equal(t, sess, decoded.Session)
+	require.Len(t, decoded.Messages, 3)
+	require.Equal(t, "Look at go.mod first.", decoded.Messages[1].ReasoningContent().Thinking)
+	require.Equal(t, "c1", decoded.Messages[2].ToolResults()[0].ToolCallID)
+}
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+
+	f, err := ParseFormat("md")
+	require.NoError(t, err)
+	require.Equal(t, FormatMarkdown, f)
+
+	f, err = ParseFormat("JSON")
+	require.NoError(t, err)
+	require.Equal(t, FormatJSON, f)
+
+	_, err = ParseFormat("html")
+	require.Error(t, err)
+}
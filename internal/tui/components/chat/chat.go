@@ -65,7 +65,10 @@ type messageListCmp struct {
 	previousSelected string // Last selected item index for restoring focus
 
 	lastUserMessageTime int64
-	defaultListKeyMap   list.KeyMap
+	// turnCost holds the cost of each assistant message since the last user
+	// message.
+	turnCost          map[string]float64
+	defaultListKeyMap list.KeyMap
 
 	// Click tracking for double/triple click detection
 	lastClickTime time.Time
@@ -112,6 +115,10 @@ func (m *messageListCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 	switch msg := msg.(type) {
 	case tea.KeyPressMsg:
+		if m.listCmp.IsFocused() && key.Matches(msg, messages.ForkKey) {
+			cmds = append(cmds, m.forkFromSelected())
+			return m, tea.Batch(cmds...)
+		}
 		if m.listCmp.IsFocused() && m.listCmp.HasSelection() {
 			switch {
 			case key.Matches(msg, messages.CopyKey):
@@ -369,6 +376,7 @@ func (m *messageListCmp) handleNewMessage(msg message.Message) tea.Cmd {
 // handleNewUserMessage adds a new user message to the list and updates the timestamp.
 func (m *messageListCmp) handleNewUserMessage(msg message.Message) tea.Cmd {
 	m.lastUserMessageTime = msg.CreatedAt
+	m.turnCost = nil
 	return m.listCmp.AppendItem(messages.NewMessageCmp(msg))
 }
 
@@ -447,6 +455,7 @@ func (m *messageListCmp) updateAssistantMessageContent(msg message.Message, assi
 		return nil
 	}
 
+	m.recordTurnCost(msg)
 	shouldShowMessage := m.shouldShowAssistantMessage(msg)
 	hasToolCallsOnly := len(msg.ToolCalls()) > 0 && msg.Content().Text == ""
 
@@ -464,6 +473,7 @@ func (m *messageListCmp) updateAssistantMessageContent(msg message.Message, assi
 				messages.NewAssistantSection(
 					msg,
 					time.Unix(m.lastUserMessageTime, 0),
+					m.currentTurnCost(),
 				),
 			)
 		}
@@ -541,6 +551,7 @@ func (m *messageListCmp) SetSession(session session.Session) tea.Cmd {
 	}
 
 	m.session = session
+	m.turnCost = nil
 	sessionMessages, err := m.app.Messages.List(context.Background(), session.ID)
 	if err != nil {
 		return util.ReportError(err)
@@ -581,11 +592,13 @@ func (m *messageListCmp) convertMessagesToUI(sessionMessages []message.Message,
 		switch msg.Role {
 		case message.User:
 			m.lastUserMessageTime = msg.CreatedAt
+			m.turnCost = nil
 			uiMessages = append(uiMessages, messages.NewMessageCmp(msg))
 		case message.Assistant:
+			m.recordTurnCost(msg)
 			uiMessages = append(uiMessages, m.convertAssistantMessage(msg, toolResultMap)...)
 			if msg.FinishPart() != nil && msg.FinishPart().Reason == message.FinishReasonEndTurn {
-				uiMessages = append(uiMessages, messages.NewAssistantSection(msg, time.Unix(m.lastUserMessageTime, 0)))
+				uiMessages = append(uiMessages, messages.NewAssistantSection(msg, time.Unix(m.lastUserMessageTime, 0), m.currentTurnCost()))
 			}
 		}
 	}
@@ -593,6 +606,28 @@ func (m *messageListCmp) convertMessagesToUI(sessionMessages []message.Message,
 	return uiMessages
 }
 
+// recordTurnCost remembers the cost of an assistant message of the current
+// turn.
+func (m *messageListCmp) recordTurnCost(msg message.Message) {
+	if msg.Usage.Cost == 0 {
+		return
+	}
+	if m.turnCost == nil {
+		m.turnCost = make(map[string]float64)
+	}
+	m.turnCost[msg.ID] = msg.Usage.Cost
+}
+
+// currentTurnCost returns the cost of all model calls since the last user
+// message.
+func (m *messageListCmp) currentTurnCost() float64 {
+	var total float64
+	for _, cost := range m.turnCost {
+		total += cost
+	}
+	return total
+}
+
 // convertAssistantMessage converts an assistant message and its tool calls to UI components.
 func (m *messageListCmp) convertAssistantMessage(msg message.Message, toolResultMap map[string]message.ToolResult) []list.Item {
 	var uiMessages []list.Item
@@ -773,6 +808,39 @@ func (m *messageListCmp) CopySelectedText(clear bool) tea.Cmd {
 	)
 }
 
+// forkFromSelected forks the current session at the selected message and
+// switches to the new session.
+func (m *messageListCmp) forkFromSelected() tea.Cmd {
+	if m.session.ID == "" {
+		return nil
+	}
+	if m.app.CoderAgent != nil && m.app.CoderAgent.IsSessionBusy(m.session.ID) {
+		return util.ReportWarn("Agent is busy, please wait before forking the session...")
+	}
+
+	var messageID string
+	if selected := m.listCmp.SelectedItem(); selected != nil {
+		switch item := (*selected).(type) {
+		case messages.MessageCmp:
+			messageID = item.GetMessage().ID
+		case messages.ToolCallCmp:
+			messageID = item.ParentMessageID()
+		}
+	}
+	if messageID == "" {
+		return util.ReportWarn("Select a message to fork from")
+	}
+
+	fork, err := m.app.Sessions.Fork(context.Background(), m.session.ID, messageID)
+	if err != nil {
+		return util.ReportError(err)
+	}
+	return tea.Sequence(
+		util.CmdHandler(SessionSelectedMsg(fork)),
+		util.ReportInfo("Forked session: "+fork.Title),
+	)
+}
+
 // abs returns the absolute value of an integer.
 func abs(x int) int {
 	if x < 0 {
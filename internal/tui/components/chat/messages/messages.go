@@ -31,6 +31,9 @@ var CopyKey = key.NewBinding(key.WithKeys("c", "y", "C", "Y"), key.WithHelp("c/y
 // ClearSelectionKey is the key binding for clearing the current selection in the chat interface.
 var ClearSelectionKey = key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "clear selection"))
 
+// ForkKey is the key binding for forking the session from the selected message.
+var ForkKey = key.NewBinding(key.WithKeys("F"), key.WithHelp("F", "fork from here"))
+
 // MessageCmp defines the interface for message components in the chat interface.
 // It combines standard UI model interfaces with message-specific functionality.
 type MessageCmp interface {
@@ -190,8 +193,11 @@ func (m *messageCmp) renderAssistantMessage() string {
 		content = ""
 	} else if finished && content == "" && finishedData.Reason == message.FinishReasonCanceled {
 		content = "*Canceled*"
-	} else if finished && content == "" && finishedData.Reason == message.FinishReasonError {
+	} else if finished && content == "" && (finishedData.Reason == message.FinishReasonError || finishedData.Reason == message.FinishReasonBudgetExceeded) {
 		errTag := t.S().Base.Padding(0, 1).Background(t.Red).Foreground(t.White).Render("ERROR")
+		if finishedData.Reason == message.FinishReasonBudgetExceeded {
+			errTag = t.S().Base.Padding(0, 1).Background(t.Warning).Foreground(t.White).Render("BUDGET")
+		}
 		truncated := ansi.Truncate(finishedData.Message, m.textWidth()-2-lipgloss.Width(errTag), "...")
 		title := fmt.Sprintf("%s %s", errTag, t.S().Base.Foreground(t.FgHalfMuted).Render(truncated))
 		details := t.S().Base.Foreground(t.FgSubtle).Width(m.textWidth() - 2).Render(finishedData.Details)
@@ -363,6 +369,7 @@ type assistantSectionModel struct {
 	id                  string
 	message             message.Message
 	lastUserMessageTime time.Time
+	cost                float64
 }
 
 // ID implements AssistantSection.
@@ -370,12 +377,15 @@ func (m *assistantSectionModel) ID() string {
 	return m.id
 }
 
-func NewAssistantSection(message message.Message, lastUserMessageTime time.Time) AssistantSection {
+// NewAssistantSection creates the footer shown after an assistant turn. cost
+// is the cost of all model calls of the turn.
+func NewAssistantSection(message message.Message, lastUserMessageTime time.Time, cost float64) AssistantSection {
 	return &assistantSectionModel{
 		width:               0,
 		id:                  uuid.NewString(),
 		message:             message,
 		lastUserMessageTime: lastUserMessageTime,
+		cost:                cost,
 	}
 }
 
@@ -392,7 +402,11 @@ func (m *assistantSectionModel) View() string {
 	finishData := m.message.FinishPart()
 	finishTime := time.Unix(finishData.Time, 0)
 	duration := finishTime.Sub(m.lastUserMessageTime)
-	infoMsg := t.S().Subtle.Render(duration.String())
+	info := duration.String()
+	if m.cost > 0 {
+		info += fmt.Sprintf(" · $%.4f", m.cost)
+	}
+	infoMsg := t.S().Subtle.Render(info)
 	icon := t.S().Subtle.Render(styles.ModelIcon)
 	model := config.Get().GetModel(m.message.Provider, m.message.Model)
 	if model == nil {
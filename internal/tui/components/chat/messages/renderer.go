@@ -10,6 +10,7 @@ import (
 	"github.com/charmbracelet/crush/internal/fsext"
 	"github.com/charmbracelet/crush/internal/llm/agent"
 	"github.com/charmbracelet/crush/internal/llm/tools"
+	"github.com/charmbracelet/crush/internal/todo"
 	"github.com/charmbracelet/crush/internal/tui/components/core"
 	"github.com/charmbracelet/crush/internal/tui/highlight"
 	"github.com/charmbracelet/crush/internal/tui/styles"
@@ -174,6 +175,16 @@ func init() {
 	registry.register(tools.LSToolName, func() renderer { return lsRenderer{} })
 	registry.register(tools.SourcegraphToolName, func() renderer { return sourcegraphRenderer{} })
 	registry.register(tools.DiagnosticsToolName, func() renderer { return diagnosticsRenderer{} })
+	registry.register(tools.DefinitionToolName, func() renderer { return navigationRenderer{} })
+	registry.register(tools.ReferencesToolName, func() renderer { return navigationRenderer{} })
+	registry.register(tools.HoverToolName, func() renderer { return navigationRenderer{} })
+	registry.register(tools.SymbolsToolName, func() renderer { return symbolsRenderer{} })
+	registry.register(tools.RefactorToolName, func() renderer { return refactorRenderer{} })
+	registry.register(tools.TodosToolName, func() renderer { return todosRenderer{} })
+	registry.register(tools.JobsToolName, func() renderer { return jobRenderer{} })
+	registry.register(tools.JobOutputToolName, func() renderer { return jobRenderer{} })
+	registry.register(tools.JobWaitToolName, func() renderer { return jobRenderer{} })
+	registry.register(tools.JobStopToolName, func() renderer { return jobRenderer{} })
 	registry.register(agent.AgentToolName, func() renderer { return agentRenderer{} })
 }
 
@@ -589,6 +600,183 @@ func (dr diagnosticsRenderer) Render(v *toolCallCmp) string {
 	})
 }
 
+// -----------------------------------------------------------------------------
+//  Navigation renderers
+// -----------------------------------------------------------------------------
+
+// navigationRenderer handles the LSP tools that look up a symbol in a file
+type navigationRenderer struct {
+	baseRenderer
+}
+
+// Render displays the symbol with the file and line it was looked up at
+func (nr navigationRenderer) Render(v *toolCallCmp) string {
+	var params tools.DefinitionParams
+	var args []string
+	if err := nr.unmarshalParams(v.call.Input, &params); err == nil {
+		args = newParamBuilder().
+			addMain(params.Symbol).
+			addKeyValue("file", fmt.Sprintf("%s:%d", fsext.PrettyPath(params.FilePath), params.Line)).
+			addFlag("implementation", params.Implementation).
+			build()
+	}
+
+	return nr.renderWithParams(v, prettifyToolName(v.call.Name), args, func() string {
+		return renderPlainContent(v, v.result.Content)
+	})
+}
+
+// symbolsRenderer handles file outlines and project symbol searches
+type symbolsRenderer struct {
+	baseRenderer
+}
+
+// Render displays the file or the query the symbols were listed for
+func (sr symbolsRenderer) Render(v *toolCallCmp) string {
+	var params tools.SymbolsParams
+	var args []string
+	if err := sr.unmarshalParams(v.call.Input, &params); err == nil {
+		main := params.Query
+		if params.FilePath != "" {
+			main = fsext.PrettyPath(params.FilePath)
+		}
+		args = newParamBuilder().addMain(main).build()
+	}
+
+	return sr.renderWithParams(v, "Symbols", args, func() string {
+		return renderPlainContent(v, v.result.Content)
+	})
+}
+
+// refactorRenderer handles LSP renames and code actions with a diff of every
+// changed file
+type refactorRenderer struct {
+	baseRenderer
+}
+
+// Render displays the refactoring and the diffs of the files it changed
+func (rr refactorRenderer) Render(v *toolCallCmp) string {
+	t := styles.CurrentTheme()
+	var params tools.RefactorParams
+	var args []string
+	if err := rr.unmarshalParams(v.call.Input, &params); err == nil {
+		main := params.Action
+		if params.Operation == "rename" {
+			main = params.Symbol
+		}
+		args = newParamBuilder().
+			addMain(main).
+			addKeyValue("new name", params.NewName).
+			addKeyValue("file", fmt.Sprintf("%s:%d", fsext.PrettyPath(params.FilePath), params.Line)).
+			build()
+	}
+
+	return rr.renderWithParams(v, "Refactor", args, func() string {
+		var meta tools.RefactorResponseMetadata
+		if err := rr.unmarshalParams(v.result.Metadata, &meta); err != nil || len(meta.Files) == 0 {
+			return renderPlainContent(v, v.result.Content)
+		}
+
+		diffs := make([]string, len(meta.Files))
+		for i, file := range meta.Files {
+			formatter := core.DiffFormatter().
+				Before(fsext.PrettyPath(file.FilePath), file.OldContent).
+				After(fsext.PrettyPath(file.FilePath), file.NewContent).
+				Width(v.textWidth() - 2) // -2 for padding
+			if v.textWidth() > 120 {
+				formatter = formatter.Split()
+			}
+			diffs[i] = formatter.String()
+		}
+		// add a message to the bottom if the content was truncated
+		formatted := strings.Join(diffs, "\n")
+		if lipgloss.Height(formatted) > responseContextHeight {
+			contentLines := strings.Split(formatted, "\n")
+			truncateMessage := t.S().Muted.
+				Background(t.BgBaseLighter).
+				PaddingLeft(2).
+				Width(v.textWidth() - 2).
+				Render(fmt.Sprintf("… (%d lines)", len(contentLines)-responseContextHeight))
+			formatted = strings.Join(contentLines[:responseContextHeight], "\n") + "\n" + truncateMessage
+		}
+		return formatted
+	})
+}
+
+// -----------------------------------------------------------------------------
+//  Job renderer
+// -----------------------------------------------------------------------------
+
+// jobRenderer handles the tools that list, read, wait for and stop background
+// jobs
+type jobRenderer struct {
+	baseRenderer
+}
+
+// Render displays the job ID with the pattern or number of lines asked for
+func (jr jobRenderer) Render(v *toolCallCmp) string {
+	var args []string
+	switch v.call.Name {
+	case tools.JobOutputToolName:
+		var params tools.JobOutputParams
+		if err := jr.unmarshalParams(v.call.Input, &params); err == nil {
+			args = newParamBuilder().
+				addMain(fmt.Sprintf("#%d", params.JobID)).
+				addKeyValue("pattern", params.Pattern).
+				addKeyValue("lines", formatNonZero(params.Lines)).
+				build()
+		}
+	case tools.JobWaitToolName:
+		var params tools.JobWaitParams
+		if err := jr.unmarshalParams(v.call.Input, &params); err == nil {
+			args = newParamBuilder().
+				addMain(fmt.Sprintf("#%d", params.JobID)).
+				addKeyValue("pattern", params.Pattern).
+				addKeyValue("timeout", formatTimeout(params.Timeout)).
+				build()
+		}
+	case tools.JobStopToolName:
+		var params tools.JobStopParams
+		if err := jr.unmarshalParams(v.call.Input, &params); err == nil {
+			args = newParamBuilder().addMain(fmt.Sprintf("#%d", params.JobID)).build()
+		}
+	}
+
+	return jr.renderWithParams(v, prettifyToolName(v.call.Name), args, func() string {
+		return renderPlainContent(v, v.result.Content)
+	})
+}
+
+// -----------------------------------------------------------------------------
+//  Todos renderer
+// -----------------------------------------------------------------------------
+
+// todosRenderer handles updates of the session task list
+type todosRenderer struct {
+	baseRenderer
+}
+
+// Render displays how many tasks are done and the updated task list
+func (tr todosRenderer) Render(v *toolCallCmp) string {
+	var params tools.TodosParams
+	var args []string
+	if err := tr.unmarshalParams(v.call.Input, &params); err == nil {
+		completed := 0
+		for _, item := range params.Todos {
+			if item.Status == todo.StatusCompleted {
+				completed++
+			}
+		}
+		args = newParamBuilder().
+			addMain(fmt.Sprintf("%d/%d done", completed, len(params.Todos))).
+			build()
+	}
+
+	return tr.renderWithParams(v, "Todos", args, func() string {
+		return renderPlainContent(v, todo.Format(params.Todos))
+	})
+}
+
 // -----------------------------------------------------------------------------
 //  Task renderer
 // -----------------------------------------------------------------------------
@@ -886,6 +1074,26 @@ func prettifyToolName(name string) string {
 		return "View"
 	case tools.WriteToolName:
 		return "Write"
+	case tools.TodosToolName:
+		return "Todos"
+	case tools.JobsToolName:
+		return "Jobs"
+	case tools.JobOutputToolName:
+		return "Job Output"
+	case tools.JobWaitToolName:
+		return "Job Wait"
+	case tools.JobStopToolName:
+		return "Job Stop"
+	case tools.DefinitionToolName:
+		return "Definition"
+	case tools.ReferencesToolName:
+		return "References"
+	case tools.HoverToolName:
+		return "Hover"
+	case tools.SymbolsToolName:
+		return "Symbols"
+	case tools.RefactorToolName:
+		return "Refactor"
 	default:
 		return name
 	}
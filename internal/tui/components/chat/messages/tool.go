@@ -405,13 +405,17 @@ func (m *toolCallCmp) formatResultForCopy() string {
 		return m.formatEditResultForCopy()
 	case tools.MultiEditToolName:
 		return m.formatMultiEditResultForCopy()
+	case tools.RefactorToolName:
+		return m.formatRefactorResultForCopy()
 	case tools.WriteToolName:
 		return m.formatWriteResultForCopy()
 	case tools.FetchToolName:
 		return m.formatFetchResultForCopy()
 	case agent.AgentToolName:
 		return m.formatAgentResultForCopy()
-	case tools.DownloadToolName, tools.GrepToolName, tools.GlobToolName, tools.LSToolName, tools.SourcegraphToolName, tools.DiagnosticsToolName:
+	case tools.DownloadToolName, tools.GrepToolName, tools.GlobToolName, tools.LSToolName, tools.SourcegraphToolName, tools.DiagnosticsToolName,
+		tools.DefinitionToolName, tools.ReferencesToolName, tools.HoverToolName, tools.SymbolsToolName,
+		tools.JobsToolName, tools.JobOutputToolName, tools.JobWaitToolName, tools.JobStopToolName:
 		return fmt.Sprintf("```\n%s\n```", m.result.Content)
 	default:
 		return m.result.Content
@@ -556,6 +560,28 @@ func (m *toolCallCmp) formatMultiEditResultForCopy() string {
 	return result.String()
 }
 
+func (m *toolCallCmp) formatRefactorResultForCopy() string {
+	var meta tools.RefactorResponseMetadata
+	if m.result.Metadata == "" {
+		return m.result.Content
+	}
+
+	if json.Unmarshal([]byte(m.result.Metadata), &meta) != nil {
+		return m.result.Content
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Changes: +%d -%d\n", meta.Additions, meta.Removals))
+	result.WriteString("```diff\n")
+	for _, file := range meta.Files {
+		diffContent, _, _ := diff.GenerateDiff(file.OldContent, file.NewContent, fsext.PrettyPath(file.FilePath))
+		result.WriteString(diffContent)
+	}
+	result.WriteString("\n```")
+
+	return result.String()
+}
+
 func (m *toolCallCmp) formatWriteResultForCopy() string {
 	var params tools.WriteParams
 	if json.Unmarshal([]byte(m.call.Input), &params) != nil {
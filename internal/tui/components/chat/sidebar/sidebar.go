@@ -14,9 +14,12 @@ import (
 	"github.com/charmbracelet/crush/internal/fsext"
 	"github.com/charmbracelet/crush/internal/history"
 	"github.com/charmbracelet/crush/internal/home"
+	"github.com/charmbracelet/crush/internal/job"
 	"github.com/charmbracelet/crush/internal/lsp"
+	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/pubsub"
 	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/todo"
 	"github.com/charmbracelet/crush/internal/tui/components/chat"
 	"github.com/charmbracelet/crush/internal/tui/components/core"
 	"github.com/charmbracelet/crush/internal/tui/components/core/layout"
@@ -28,6 +31,7 @@ import (
 	"github.com/charmbracelet/crush/internal/tui/util"
 	"github.com/charmbracelet/crush/internal/version"
 	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
@@ -57,6 +61,25 @@ type SessionFilesMsg struct {
 	Files []SessionFile
 }
 
+// TurnUsageMsg carries the usage of the model calls of the latest turn of a
+// session.
+type TurnUsageMsg struct {
+	SessionID string
+	Usage     map[string]message.Usage
+}
+
+// TodosMsg carries the task list of a session.
+type TodosMsg struct {
+	SessionID string
+	Todos     []todo.Todo
+}
+
+// JobsMsg carries the background jobs of a session.
+type JobsMsg struct {
+	SessionID string
+	Jobs      []job.Job
+}
+
 type Sidebar interface {
 	util.Model
 	layout.Sizeable
@@ -72,15 +95,27 @@ type sidebarCmp struct {
 	lspClients    map[string]*lsp.Client
 	compactMode   bool
 	history       history.Service
+	messages      message.Service
+	todos         todo.Service
+	jobs          job.Service
 	files         *csync.Map[string, SessionFile]
+	todoList      []todo.Todo
+	jobList       []job.Job
+	// turnUsage is the usage of each assistant message since the last user
+	// message.
+	turnUsage map[string]message.Usage
 }
 
-func New(history history.Service, lspClients map[string]*lsp.Client, compact bool) Sidebar {
+func New(history history.Service, messages message.Service, todos todo.Service, jobs job.Service, lspClients map[string]*lsp.Client, compact bool) Sidebar {
 	return &sidebarCmp{
 		lspClients:  lspClients,
 		history:     history,
+		messages:    messages,
+		todos:       todos,
+		jobs:        jobs,
 		compactMode: compact,
 		files:       csync.NewMap[string, SessionFile](),
+		turnUsage:   make(map[string]message.Usage),
 	}
 }
 
@@ -96,9 +131,39 @@ func (m *sidebarCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.files.Set(file.FilePath, file)
 		}
 		return m, nil
+	case TurnUsageMsg:
+		if msg.SessionID == m.session.ID {
+			m.turnUsage = msg.Usage
+		}
+		return m, nil
+	case TodosMsg:
+		if msg.SessionID == m.session.ID {
+			m.todoList = msg.Todos
+		}
+		return m, nil
+	case pubsub.Event[todo.List]:
+		if msg.Payload.SessionID == m.session.ID {
+			m.todoList = msg.Payload.Todos
+		}
+		return m, nil
+	case JobsMsg:
+		if msg.SessionID == m.session.ID {
+			m.jobList = msg.Jobs
+		}
+		return m, nil
+	case pubsub.Event[job.Job]:
+		if msg.Payload.SessionID == m.session.ID {
+			m.handleJobEvent(msg.Payload)
+		}
+		return m, nil
 
 	case chat.SessionClearedMsg:
 		m.session = session.Session{}
+		m.turnUsage = make(map[string]message.Usage)
+		m.todoList = nil
+		m.jobList = nil
+	case pubsub.Event[message.Message]:
+		m.handleMessageEvent(msg)
 	case pubsub.Event[history.File]:
 		return m, m.handleFileHistoryEvent(msg)
 	case pubsub.Event[session.Session]:
@@ -159,6 +224,12 @@ func (m *sidebarCmp) View() string {
 		}
 	} else {
 		// Vertical layout (default)
+		if m.session.ID != "" && len(m.todoList) > 0 {
+			parts = append(parts, "", m.todosBlock())
+		}
+		if m.session.ID != "" && len(m.jobList) > 0 {
+			parts = append(parts, "", m.jobsBlock())
+		}
 		if m.session.ID != "" {
 			parts = append(parts, "", m.filesBlock())
 		}
@@ -220,6 +291,57 @@ func (m *sidebarCmp) handleFileHistoryEvent(event pubsub.Event[history.File]) te
 	}
 }
 
+func (m *sidebarCmp) handleMessageEvent(event pubsub.Event[message.Message]) {
+	msg := event.Payload
+	if msg.SessionID != m.session.ID {
+		return
+	}
+	switch {
+	case msg.Role == message.User && event.Type == pubsub.CreatedEvent:
+		m.turnUsage = make(map[string]message.Usage)
+	case msg.Role == message.Assistant && msg.Usage.PromptTokens() > 0:
+		m.turnUsage[msg.ID] = msg.Usage
+	}
+}
+
+func (m *sidebarCmp) loadTurnUsage() tea.Msg {
+	msgs, err := m.messages.List(context.Background(), m.session.ID)
+	if err != nil {
+		return util.InfoMsg{
+			Type: util.InfoTypeError,
+			Msg:  err.Error(),
+		}
+	}
+
+	usage := make(map[string]message.Usage)
+	for _, msg := range msgs {
+		switch msg.Role {
+		case message.User:
+			usage = make(map[string]message.Usage)
+		case message.Assistant:
+			if msg.Usage.PromptTokens() > 0 {
+				usage[msg.ID] = msg.Usage
+			}
+		}
+	}
+	return TurnUsageMsg{SessionID: m.session.ID, Usage: usage}
+}
+
+// cacheHitRatio returns the share of the prompt tokens of the latest turn
+// that were read from the cache, and false if no model call has reported its
+// usage yet.
+func (m *sidebarCmp) cacheHitRatio() (float64, bool) {
+	var prompt, cached int64
+	for _, usage := range m.turnUsage {
+		prompt += usage.PromptTokens()
+		cached += usage.CacheReadTokens
+	}
+	if prompt == 0 {
+		return 0, false
+	}
+	return float64(cached) / float64(prompt), true
+}
+
 func (m *sidebarCmp) loadSessionFiles() tea.Msg {
 	files, err := m.history.ListBySession(context.Background(), m.session.ID)
 	if err != nil {
@@ -320,6 +442,14 @@ func (m *sidebarCmp) calculateAvailableHeight() int {
 
 	usedHeight += 6 // 3 sections × 2 lines each (header + empty line)
 
+	if m.session.ID != "" && len(m.todoList) > 0 {
+		usedHeight += 2 + len(m.todoList) // Task list section
+	}
+
+	if m.session.ID != "" && len(m.jobList) > 0 {
+		usedHeight += 2 + len(m.jobList) // Jobs section
+	}
+
 	// Base padding
 	usedHeight += 2 // Top and bottom padding
 
@@ -477,6 +607,109 @@ func (m *sidebarCmp) filesBlock() string {
 	}, true)
 }
 
+// todosBlock renders the task list of the session.
+func (m *sidebarCmp) todosBlock() string {
+	t := styles.CurrentTheme()
+	maxWidth := m.getMaxWidth()
+
+	completed := 0
+	for _, item := range m.todoList {
+		if item.Status == todo.StatusCompleted {
+			completed++
+		}
+	}
+	lines := []string{
+		core.Section(fmt.Sprintf("Tasks %d/%d", completed, len(m.todoList)), maxWidth),
+		"",
+	}
+	for _, item := range m.todoList {
+		var icon, content string
+		text := ansi.Truncate(item.Content, maxWidth-2, "…")
+		switch item.Status {
+		case todo.StatusCompleted:
+			icon = t.S().Base.Foreground(t.Green).Render(styles.CheckIcon)
+			content = t.S().Subtle.Strikethrough(true).Render(text)
+		case todo.StatusInProgress:
+			icon = t.S().Base.Foreground(t.Primary).Render(styles.ToolPending)
+			content = t.S().Text.Render(text)
+		default:
+			icon = t.S().Muted.Render(styles.ToolPending)
+			content = t.S().Muted.Render(text)
+		}
+		lines = append(lines, icon+" "+content)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// loadTodos loads the task list of the session.
+func (m *sidebarCmp) loadTodos() tea.Msg {
+	todos, err := m.todos.Get(context.Background(), m.session.ID)
+	if err != nil {
+		return util.InfoMsg{
+			Type: util.InfoTypeError,
+			Msg:  err.Error(),
+		}
+	}
+	return TodosMsg{SessionID: m.session.ID, Todos: todos}
+}
+
+// handleJobEvent adds a job that was started to the list of jobs, or updates
+// one that ended.
+func (m *sidebarCmp) handleJobEvent(j job.Job) {
+	i := slices.IndexFunc(m.jobList, func(existing job.Job) bool { return existing.ID == j.ID })
+	if i == -1 {
+		m.jobList = append(m.jobList, j)
+		return
+	}
+	m.jobList[i] = j
+}
+
+// jobsBlock renders the background jobs of the session.
+func (m *sidebarCmp) jobsBlock() string {
+	t := styles.CurrentTheme()
+	maxWidth := m.getMaxWidth()
+
+	running := 0
+	for _, j := range m.jobList {
+		if j.Status == job.StatusRunning {
+			running++
+		}
+	}
+	lines := []string{
+		core.Section(fmt.Sprintf("Jobs %d running", running), maxWidth),
+		"",
+	}
+	for _, j := range m.jobList {
+		var icon, status string
+		switch {
+		case j.Status == job.StatusRunning:
+			icon = t.S().Base.Foreground(t.Primary).Render(styles.ToolPending)
+		case j.Status == job.StatusStopped:
+			icon = t.S().Muted.Render(styles.ToolPending)
+			status = "stopped"
+		case j.ExitCode == 0:
+			icon = t.S().Base.Foreground(t.Green).Render(styles.CheckIcon)
+			status = "exit 0"
+		default:
+			icon = t.S().Base.Foreground(t.Red).Render(styles.ErrorIcon)
+			status = fmt.Sprintf("exit %d", j.ExitCode)
+		}
+		id := t.S().Subtle.Render(fmt.Sprintf("#%d", j.ID))
+		if status != "" {
+			status = " " + t.S().Subtle.Render(status)
+		}
+		command := strings.ReplaceAll(j.Command, "\n", " ")
+		command = ansi.Truncate(command, maxWidth-4-lipgloss.Width(id)-lipgloss.Width(status), "…")
+		lines = append(lines, icon+" "+id+" "+t.S().Text.Render(command)+status)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// loadJobs loads the background jobs of the session.
+func (m *sidebarCmp) loadJobs() tea.Msg {
+	return JobsMsg{SessionID: m.session.ID, Jobs: m.jobs.List(m.session.ID)}
+}
+
 func (m *sidebarCmp) lspBlock() string {
 	// Limit the number of LSPs shown
 	_, maxLSPs, _ := m.getDynamicLimits()
@@ -563,7 +796,7 @@ func (s *sidebarCmp) currentModelBlock() string {
 	if model.CanReason {
 		reasoningInfoStyle := t.S().Subtle.PaddingLeft(2)
 		switch modelProvider.Type {
-		case catwalk.TypeOpenAI:
+		case catwalk.TypeOpenAI, config.TypeOpenAIResponses:
 			reasoningEffort := model.DefaultReasoningEffort
 			if selectedModel.ReasoningEffort != "" {
 				reasoningEffort = selectedModel.ReasoningEffort
@@ -588,6 +821,9 @@ func (s *sidebarCmp) currentModelBlock() string {
 				s.session.Cost,
 			),
 		)
+		if ratio, ok := s.cacheHitRatio(); ok {
+			parts = append(parts, t.S().Subtle.PaddingLeft(2).Render(fmt.Sprintf("%d%% cached last turn", int(ratio*100))))
+		}
 	}
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -598,7 +834,10 @@ func (s *sidebarCmp) currentModelBlock() string {
 // SetSession implements Sidebar.
 func (m *sidebarCmp) SetSession(session session.Session) tea.Cmd {
 	m.session = session
-	return m.loadSessionFiles
+	m.turnUsage = make(map[string]message.Usage)
+	m.todoList = nil
+	m.jobList = nil
+	return tea.Batch(m.loadSessionFiles, m.loadTurnUsage, m.loadTodos, m.loadJobs)
 }
 
 // SetCompactMode sets the compact mode for the sidebar.
@@ -0,0 +1,148 @@
+package budget
+
+import (
+	"context"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+
+	"github.com/charmbracelet/crush/internal/llm/agent"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+const BudgetDialogID dialogs.DialogID = "budget"
+
+// continuePrompt is sent to the agent when the user extends the budget.
+const continuePrompt = "Continue where you left off."
+
+// BudgetDialog interface for the budget exceeded dialog
+type BudgetDialog interface {
+	dialogs.DialogModel
+}
+
+type budgetDialogCmp struct {
+	wWidth, wHeight int
+	width, height   int
+	selected        int
+	keyMap          KeyMap
+	sessionID       string
+	reason          string
+	agent           agent.Service
+}
+
+// NewBudgetDialogCmp creates a dialog asking whether to extend the budget of
+// a session after the agent stopped with err.
+func NewBudgetDialogCmp(agent agent.Service, sessionID string, err error) BudgetDialog {
+	return &budgetDialogCmp{
+		sessionID: sessionID,
+		reason:    err.Error(),
+		keyMap:    DefaultKeyMap(),
+		agent:     agent,
+	}
+}
+
+func (b *budgetDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (b *budgetDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		b.wWidth = msg.Width
+		b.wHeight = msg.Height
+		b.width = min(90, b.wWidth)
+		b.height = min(15, b.wHeight)
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, b.keyMap.ChangeSelection):
+			b.selected = (b.selected + 1) % 2
+		case key.Matches(msg, b.keyMap.Select):
+			if b.selected == 0 {
+				return b, b.extend()
+			}
+			return b, util.CmdHandler(dialogs.CloseDialogMsg{})
+		case key.Matches(msg, b.keyMap.Y):
+			return b, b.extend()
+		case key.Matches(msg, b.keyMap.N), key.Matches(msg, b.keyMap.Close):
+			return b, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+	}
+	return b, nil
+}
+
+func (b *budgetDialogCmp) extend() tea.Cmd {
+	return tea.Sequence(
+		util.CmdHandler(dialogs.CloseDialogMsg{}),
+		func() tea.Msg {
+			b.agent.ExtendBudget(b.sessionID)
+			if _, err := b.agent.Run(context.Background(), b.sessionID, continuePrompt); err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+			}
+			return nil
+		},
+	)
+}
+
+func (b *budgetDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	baseStyle := t.S().Base
+
+	// Drop the generic "budget exceeded: " prefix, the title already says so.
+	reason := b.reason
+	if _, detail, ok := strings.Cut(reason, ": "); ok {
+		reason = detail
+	}
+	explanation := t.S().Text.
+		Width(b.width - 4).
+		Render("The agent stopped because " + reason + ".")
+	question := t.S().Text.
+		Width(b.width - 4).
+		Render("Extend the budget and let the agent continue?")
+
+	buttons := core.SelectableButtons([]core.ButtonOpts{
+		{
+			Text:           "Yes",
+			UnderlineIndex: 0, // "Y"
+			Selected:       b.selected == 0,
+		},
+		{
+			Text:           "No",
+			UnderlineIndex: 0, // "N"
+			Selected:       b.selected == 1,
+		},
+	}, "  ")
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Top,
+		core.Title("Budget Exceeded", b.width-4),
+		"",
+		explanation,
+		"",
+		question,
+		"",
+		baseStyle.AlignHorizontal(lipgloss.Right).Width(b.width-4).Render(buttons),
+		"",
+	)
+	return baseStyle.
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Width(b.width).
+		Render(content)
+}
+
+func (b *budgetDialogCmp) Position() (int, int) {
+	row := (b.wHeight / 2) - (b.height / 2)
+	col := (b.wWidth / 2) - (b.width / 2)
+	return row, col
+}
+
+// ID implements BudgetDialog.
+func (b *budgetDialogCmp) ID() dialogs.DialogID {
+	return BudgetDialogID
+}
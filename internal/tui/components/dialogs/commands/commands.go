@@ -62,18 +62,23 @@ type commandDialogCmp struct {
 }
 
 type (
-	SwitchSessionsMsg      struct{}
-	NewSessionsMsg         struct{}
-	SwitchModelMsg         struct{}
-	QuitMsg                struct{}
-	OpenFilePickerMsg      struct{}
-	ToggleHelpMsg          struct{}
-	ToggleCompactModeMsg   struct{}
-	ToggleThinkingMsg      struct{}
-	OpenReasoningDialogMsg struct{}
-	OpenExternalEditorMsg  struct{}
-	ToggleYoloModeMsg      struct{}
-	CompactMsg             struct {
+	SwitchSessionsMsg         struct{}
+	NewSessionsMsg            struct{}
+	SwitchModelMsg            struct{}
+	QuitMsg                   struct{}
+	OpenFilePickerMsg         struct{}
+	ToggleHelpMsg             struct{}
+	ToggleCompactModeMsg      struct{}
+	ToggleThinkingMsg         struct{}
+	OpenReasoningDialogMsg    struct{}
+	OpenExternalEditorMsg     struct{}
+	ToggleYoloModeMsg         struct{}
+	TogglePlanModeMsg         struct{}
+	ManagePermissionGrantsMsg struct{}
+	CompactMsg                struct {
+		SessionID string
+	}
+	UndoMsg struct {
 		SessionID string
 	}
 )
@@ -301,6 +306,16 @@ func (c *commandDialogCmp) defaultCommands() []Command {
 				})
 			},
 		})
+		commands = append(commands, Command{
+			ID:          "undo",
+			Title:       "Undo Last Turn",
+			Description: "Revert file changes made since your last message",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(UndoMsg{
+					SessionID: c.sessionID,
+				})
+			},
+		})
 	}
 
 	// Add reasoning toggle for models that support it
@@ -328,7 +343,7 @@ func (c *commandDialogCmp) defaultCommands() []Command {
 			}
 
 			// OpenAI models: reasoning effort dialog
-			if providerCfg.Type == catwalk.TypeOpenAI && model.HasReasoningEffort {
+			if (providerCfg.Type == catwalk.TypeOpenAI || providerCfg.Type == config.TypeOpenAIResponses) && model.HasReasoningEffort {
 				commands = append(commands, Command{
 					ID:          "select_reasoning_effort",
 					Title:       "Select Reasoning Effort",
@@ -359,6 +374,14 @@ func (c *commandDialogCmp) defaultCommands() []Command {
 			return util.CmdHandler(dialogs.OpenDialogMsg{Model: lspignore.New()})
 		},
 	})
+	commands = append(commands, Command{
+		ID:          "permission_grants",
+		Title:       "Manage Permission Grants",
+		Description: "Review and revoke permissions you always allow",
+		Handler: func(cmd Command) tea.Cmd {
+			return util.CmdHandler(ManagePermissionGrantsMsg{})
+		},
+	})
 	commands = append(commands, Command{
 		ID:          "provider_doctor",
 		Title:       "Diagnose Providers",
@@ -405,6 +428,14 @@ func (c *commandDialogCmp) defaultCommands() []Command {
 				return util.CmdHandler(ToggleYoloModeMsg{})
 			},
 		},
+		{
+			ID:          "toggle_plan_mode",
+			Title:       "Toggle Plan Mode",
+			Description: "Plan with read-only tools and approve the plan before any edit",
+			Handler: func(cmd Command) tea.Cmd {
+				return util.CmdHandler(TogglePlanModeMsg{})
+			},
+		},
 		{
 			ID:          "toggle_help",
 			Title:       "Toggle Help",
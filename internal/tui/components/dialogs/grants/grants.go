@@ -0,0 +1,152 @@
+package grants
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/help"
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/permission"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+const GrantsDialogID dialogs.DialogID = "grants"
+
+// GrantsDialog lists the "always allow" permission grants of the project
+// and revokes them.
+type GrantsDialog interface {
+	dialogs.DialogModel
+}
+
+type grantsDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+	permissions     permission.Service
+	grants          []permission.Grant
+	selected        int
+	keyMap          KeyMap
+	help            help.Model
+}
+
+// NewGrantsDialogCmp creates a dialog listing grants.
+func NewGrantsDialogCmp(permissions permission.Service, grants []permission.Grant) GrantsDialog {
+	t := styles.CurrentTheme()
+	help := help.New()
+	help.Styles = t.S().Help
+	return &grantsDialogCmp{
+		permissions: permissions,
+		grants:      grants,
+		keyMap:      DefaultKeyMap(),
+		help:        help,
+	}
+}
+
+func (g *grantsDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (g *grantsDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		g.wWidth = msg.Width
+		g.wHeight = msg.Height
+		g.width = min(100, int(float64(g.wWidth)*0.8))
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, g.keyMap.Next):
+			if len(g.grants) > 0 {
+				g.selected = (g.selected + 1) % len(g.grants)
+			}
+		case key.Matches(msg, g.keyMap.Previous):
+			if len(g.grants) > 0 {
+				g.selected = (g.selected - 1 + len(g.grants)) % len(g.grants)
+			}
+		case key.Matches(msg, g.keyMap.Revoke):
+			return g, g.revoke()
+		case key.Matches(msg, g.keyMap.Close):
+			return g, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+	}
+	return g, nil
+}
+
+func (g *grantsDialogCmp) revoke() tea.Cmd {
+	if len(g.grants) == 0 {
+		return nil
+	}
+	grant := g.grants[g.selected]
+	if _, err := g.permissions.RevokeGrant(context.Background(), grant.ID); err != nil {
+		return util.ReportError(err)
+	}
+	g.grants = slices.Delete(g.grants, g.selected, g.selected+1)
+	g.selected = min(g.selected, max(0, len(g.grants)-1))
+	return util.ReportInfo(fmt.Sprintf("Revoked %s", describe(grant)))
+}
+
+// describe summarizes the scope of a grant on one line.
+func describe(grant permission.Grant) string {
+	scope := fmt.Sprintf("%s · %s", grant.ToolName, grant.Action)
+	if grant.Command != "" {
+		return fmt.Sprintf("%s: %s", scope, strings.Join(strings.Fields(grant.Command), " "))
+	}
+	return fmt.Sprintf("%s (%s)", scope, fsext.PrettyPath(grant.Path))
+}
+
+func (g *grantsDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	baseStyle := t.S().Base
+	contentWidth := g.width - 4
+
+	var body string
+	if len(g.grants) == 0 {
+		body = t.S().Muted.Render("No permissions are always allowed in this project.")
+	} else {
+		lines := make([]string, len(g.grants))
+		for i, grant := range g.grants {
+			line := ansi.Truncate(describe(grant), contentWidth-2, "…")
+			if i == g.selected {
+				lines[i] = t.S().Text.Bold(true).Render("> " + line)
+			} else {
+				lines[i] = t.S().Muted.Render("  " + line)
+			}
+		}
+		body = strings.Join(lines, "\n")
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		core.Title("Permission Grants", contentWidth),
+		"",
+		body,
+		"",
+		g.help.View(g.keyMap),
+	)
+	return baseStyle.
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Width(g.width).
+		Render(content)
+}
+
+func (g *grantsDialogCmp) Position() (int, int) {
+	height := len(g.grants) + 6
+	row := (g.wHeight / 2) - (height / 2)
+	col := (g.wWidth / 2) - (g.width / 2)
+	return max(0, row), max(0, col)
+}
+
+// ID implements GrantsDialog.
+func (g *grantsDialogCmp) ID() dialogs.DialogID {
+	return GrantsDialogID
+}
@@ -25,6 +25,7 @@ type PermissionAction string
 const (
 	PermissionAllow           PermissionAction = "allow"
 	PermissionAllowForSession PermissionAction = "allow_session"
+	PermissionAllowAlways     PermissionAction = "allow_always"
 	PermissionDeny            PermissionAction = "deny"
 
 	PermissionsDialogID dialogs.DialogID = "permissions"
@@ -49,7 +50,7 @@ type permissionDialogCmp struct {
 	height          int
 	permission      permission.PermissionRequest
 	contentViewPort viewport.Model
-	selectedOption  int // 0: Allow, 1: Allow for session, 2: Deny
+	selectedOption  int // 0: Allow, 1: Allow for session, 2: Always allow, 3: Deny
 
 	// Diff view state
 	defaultDiffSplitMode bool  // true for split, false for unified
@@ -91,7 +92,7 @@ func (p *permissionDialogCmp) Init() tea.Cmd {
 }
 
 func (p *permissionDialogCmp) supportsDiffView() bool {
-	return p.permission.ToolName == tools.EditToolName || p.permission.ToolName == tools.WriteToolName || p.permission.ToolName == tools.MultiEditToolName
+	return p.permission.ToolName == tools.EditToolName || p.permission.ToolName == tools.WriteToolName || p.permission.ToolName == tools.MultiEditToolName || p.permission.ToolName == tools.RefactorToolName
 }
 
 func (p *permissionDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -107,10 +108,10 @@ func (p *permissionDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyPressMsg:
 		switch {
 		case key.Matches(msg, p.keyMap.Right) || key.Matches(msg, p.keyMap.Tab):
-			p.selectedOption = (p.selectedOption + 1) % 3
+			p.selectedOption = (p.selectedOption + 1) % 4
 			return p, nil
 		case key.Matches(msg, p.keyMap.Left):
-			p.selectedOption = (p.selectedOption + 2) % 3
+			p.selectedOption = (p.selectedOption + 3) % 4
 		case key.Matches(msg, p.keyMap.Select):
 			return p, p.selectCurrentOption()
 		case key.Matches(msg, p.keyMap.Allow):
@@ -123,6 +124,11 @@ func (p *permissionDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				util.CmdHandler(dialogs.CloseDialogMsg{}),
 				util.CmdHandler(PermissionResponseMsg{Action: PermissionAllowForSession, Permission: p.permission}),
 			)
+		case key.Matches(msg, p.keyMap.AllowAlways):
+			return p, tea.Batch(
+				util.CmdHandler(dialogs.CloseDialogMsg{}),
+				util.CmdHandler(PermissionResponseMsg{Action: PermissionAllowAlways, Permission: p.permission}),
+			)
 		case key.Matches(msg, p.keyMap.Deny):
 			return p, tea.Batch(
 				util.CmdHandler(dialogs.CloseDialogMsg{}),
@@ -227,6 +233,8 @@ func (p *permissionDialogCmp) selectCurrentOption() tea.Cmd {
 	case 1:
 		action = PermissionAllowForSession
 	case 2:
+		action = PermissionAllowAlways
+	case 3:
 		action = PermissionDeny
 	}
 
@@ -251,10 +259,15 @@ func (p *permissionDialogCmp) renderButtons() string {
 			UnderlineIndex: 10, // "S" in "Session"
 			Selected:       p.selectedOption == 1,
 		},
+		{
+			Text:           "Always Allow",
+			UnderlineIndex: 2, // "w" in "Always"
+			Selected:       p.selectedOption == 2,
+		},
 		{
 			Text:           "Deny",
 			UnderlineIndex: 0, // "D"
-			Selected:       p.selectedOption == 2,
+			Selected:       p.selectedOption == 3,
 		},
 	}
 
@@ -293,7 +306,7 @@ func (p *permissionDialogCmp) renderHeader() string {
 
 	if summary := p.summaryLine(); summary != "" {
 		text := t.S().Text.Width(p.width).Render(ansi.Truncate(summary, p.width, "…"))
-		shortcuts := t.S().Muted.Width(p.width).Render(ansi.Truncate("Shortcuts: a allow  •  s allow session  •  d deny", p.width, "…"))
+		shortcuts := t.S().Muted.Width(p.width).Render(ansi.Truncate("Shortcuts: a allow  •  s allow session  •  w always allow  •  d deny", p.width, "…"))
 		headerParts = append(headerParts,
 			text,
 			baseStyle.Render(strings.Repeat(" ", p.width)),
@@ -398,6 +411,24 @@ func (p *permissionDialogCmp) renderHeader() string {
 			),
 			baseStyle.Render(strings.Repeat(" ", p.width)),
 		)
+	case tools.RefactorToolName:
+		params := p.permission.Params.(tools.RefactorPermissionsParams)
+		files := make([]string, len(params.Files))
+		for i, file := range params.Files {
+			files[i] = fsext.PrettyPath(file.FilePath)
+		}
+		filesKey := t.S().Muted.Render("Files")
+		filesValue := t.S().Text.
+			Width(p.width - lipgloss.Width(filesKey)).
+			Render(fmt.Sprintf(" %s", strings.Join(files, ", ")))
+		headerParts = append(headerParts,
+			lipgloss.JoinHorizontal(
+				lipgloss.Left,
+				filesKey,
+				filesValue,
+			),
+			baseStyle.Render(strings.Repeat(" ", p.width)),
+		)
 	case tools.FetchToolName:
 		headerParts = append(headerParts, t.S().Muted.Width(p.width).Bold(true).Render("URL"))
 	case tools.ViewToolName:
@@ -452,6 +483,8 @@ func (p *permissionDialogCmp) getOrGenerateContent() string {
 		content = p.generateWriteContent()
 	case tools.MultiEditToolName:
 		content = p.generateMultiEditContent()
+	case tools.RefactorToolName:
+		content = p.generateRefactorContent()
 	case tools.FetchToolName:
 		content = p.generateFetchContent()
 	case tools.ViewToolName:
@@ -584,6 +617,36 @@ func (p *permissionDialogCmp) generateMultiEditContent() string {
 	return ""
 }
 
+func (p *permissionDialogCmp) generateRefactorContent() string {
+	if pr, ok := p.permission.Params.(tools.RefactorPermissionsParams); ok {
+		// Every file gets its own diff; they are stacked and scrolled together.
+		diffs := make([]string, len(pr.Files))
+		for i, file := range pr.Files {
+			formatter := core.DiffFormatter().
+				Before(fsext.PrettyPath(file.FilePath), file.OldContent).
+				After(fsext.PrettyPath(file.FilePath), file.NewContent).
+				Width(p.contentViewPort.Width()).
+				XOffset(p.diffXOffset)
+			if p.useDiffSplitMode() {
+				formatter = formatter.Split()
+			} else {
+				formatter = formatter.Unified()
+			}
+			diffs[i] = formatter.String()
+		}
+
+		lines := strings.Split(strings.Join(diffs, "\n"), "\n")
+		height := p.contentViewPort.Height()
+		p.diffYOffset = min(p.diffYOffset, max(0, len(lines)-height))
+		lines = lines[p.diffYOffset:]
+		if height > 0 && len(lines) > height {
+			lines = lines[:height]
+		}
+		return strings.Join(lines, "\n")
+	}
+	return ""
+}
+
 func (p *permissionDialogCmp) generateFetchContent() string {
 	t := styles.CurrentTheme()
 	baseStyle := t.S().Base.Background(t.BgSubtle)
@@ -771,6 +834,9 @@ func (p *permissionDialogCmp) SetSize() tea.Cmd {
 	case tools.MultiEditToolName:
 		p.width = int(float64(p.wWidth) * 0.8)
 		p.height = int(float64(p.wHeight) * 0.8)
+	case tools.RefactorToolName:
+		p.width = int(float64(p.wWidth) * 0.8)
+		p.height = int(float64(p.wHeight) * 0.8)
 	case tools.FetchToolName:
 		p.width = int(float64(p.wWidth) * 0.8)
 		p.height = int(float64(p.wHeight) * 0.3)
@@ -0,0 +1,82 @@
+package plan
+
+import (
+	"github.com/charmbracelet/bubbles/v2/key"
+)
+
+// KeyMap defines the key bindings for the plan dialog.
+type KeyMap struct {
+	Next,
+	Previous,
+	Toggle,
+	Edit,
+	Approve,
+	Close key.Binding
+}
+
+// DefaultKeyMap returns the default key bindings for the plan dialog.
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Next: key.NewBinding(
+			key.WithKeys("down", "j", "tab"),
+			key.WithHelp("↓", "next step"),
+		),
+		Previous: key.NewBinding(
+			key.WithKeys("up", "k", "shift+tab"),
+			key.WithHelp("↑", "previous step"),
+		),
+		Toggle: key.NewBinding(
+			key.WithKeys("space", "x"),
+			key.WithHelp("space", "keep/drop step"),
+		),
+		Edit: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "edit step"),
+		),
+		Approve: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "approve"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "keep planning"),
+		),
+	}
+}
+
+// KeyBindings implements layout.KeyMapProvider
+func (k KeyMap) KeyBindings() []key.Binding {
+	return []key.Binding{
+		k.Next,
+		k.Previous,
+		k.Toggle,
+		k.Edit,
+		k.Approve,
+		k.Close,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	m := [][]key.Binding{}
+	slice := k.KeyBindings()
+	for i := 0; i < len(slice); i += 4 {
+		end := min(i+4, len(slice))
+		m = append(m, slice[i:end])
+	}
+	return m
+}
+
+// ShortHelp implements help.KeyMap.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{
+		key.NewBinding(
+			key.WithKeys("down", "up"),
+			key.WithHelp("↑↓", "choose"),
+		),
+		k.Toggle,
+		k.Edit,
+		k.Approve,
+		k.Close,
+	}
+}
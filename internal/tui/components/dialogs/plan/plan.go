@@ -0,0 +1,203 @@
+package plan
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/help"
+	"github.com/charmbracelet/bubbles/v2/key"
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+const PlanDialogID dialogs.DialogID = "plan"
+
+// ApprovedMsg is sent when the user approves the plan of a session.
+type ApprovedMsg struct {
+	SessionID string
+	Steps     []Step
+}
+
+// PlanDialog lets the user review the plan the agent came up with in plan
+// mode: steps can be edited or dropped before the plan is approved.
+type PlanDialog interface {
+	dialogs.DialogModel
+}
+
+type planDialogCmp struct {
+	wWidth, wHeight int
+	width           int
+	sessionID       string
+	steps           []Step
+	selected        int
+	editing         bool
+	input           textinput.Model
+	keyMap          KeyMap
+	help            help.Model
+}
+
+// NewPlanDialogCmp creates a dialog to review the steps of the plan of a
+// session.
+func NewPlanDialogCmp(sessionID string, steps []Step) PlanDialog {
+	t := styles.CurrentTheme()
+	help := help.New()
+	help.Styles = t.S().Help
+	input := textinput.New()
+	input.Prompt = ""
+	input.SetStyles(t.S().TextInput)
+	return &planDialogCmp{
+		sessionID: sessionID,
+		steps:     steps,
+		input:     input,
+		keyMap:    DefaultKeyMap(),
+		help:      help,
+	}
+}
+
+func (p *planDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (p *planDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.wWidth = msg.Width
+		p.wHeight = msg.Height
+		p.width = min(100, int(float64(p.wWidth)*0.8))
+		p.input.SetWidth(p.width - 10)
+	case tea.KeyPressMsg:
+		if p.editing {
+			return p, p.updateEditing(msg)
+		}
+		switch {
+		case key.Matches(msg, p.keyMap.Next):
+			p.selected = (p.selected + 1) % len(p.steps)
+		case key.Matches(msg, p.keyMap.Previous):
+			p.selected = (p.selected - 1 + len(p.steps)) % len(p.steps)
+		case key.Matches(msg, p.keyMap.Toggle):
+			p.steps[p.selected].Keep = !p.steps[p.selected].Keep
+		case key.Matches(msg, p.keyMap.Edit):
+			p.editing = true
+			p.input.SetValue(p.steps[p.selected].Text)
+			p.input.CursorEnd()
+			return p, p.input.Focus()
+		case key.Matches(msg, p.keyMap.Approve):
+			return p, p.approve()
+		case key.Matches(msg, p.keyMap.Close):
+			return p, tea.Sequence(
+				util.CmdHandler(dialogs.CloseDialogMsg{}),
+				util.ReportInfo("Still in plan mode: reply to refine the plan"),
+			)
+		}
+	case tea.PasteMsg:
+		if p.editing {
+			var cmd tea.Cmd
+			p.input, cmd = p.input.Update(msg)
+			return p, cmd
+		}
+	}
+	return p, nil
+}
+
+// updateEditing handles a key press while the selected step is edited.
+func (p *planDialogCmp) updateEditing(msg tea.KeyPressMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, p.keyMap.Approve):
+		if text := strings.TrimSpace(p.input.Value()); text != "" {
+			p.steps[p.selected].Text = text
+		}
+		p.editing = false
+		p.input.Blur()
+		return nil
+	case key.Matches(msg, p.keyMap.Close):
+		p.editing = false
+		p.input.Blur()
+		return nil
+	}
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return cmd
+}
+
+func (p *planDialogCmp) approve() tea.Cmd {
+	kept := 0
+	for _, step := range p.steps {
+		if step.Keep {
+			kept++
+		}
+	}
+	if kept == 0 {
+		return util.ReportWarn("Keep at least one step to approve the plan")
+	}
+	return tea.Sequence(
+		util.CmdHandler(dialogs.CloseDialogMsg{}),
+		util.CmdHandler(ApprovedMsg{SessionID: p.sessionID, Steps: p.steps}),
+	)
+}
+
+func (p *planDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	baseStyle := t.S().Base
+	contentWidth := p.width - 4
+
+	lines := make([]string, len(p.steps))
+	for i, step := range p.steps {
+		check := "[ ] "
+		if step.Keep {
+			check = "[x] "
+		}
+		cursor := "  "
+		if i == p.selected {
+			cursor = "> "
+		}
+		prefix := cursor + check
+		textWidth := contentWidth - lipgloss.Width(prefix)
+
+		var text string
+		switch {
+		case i == p.selected && p.editing:
+			text = p.input.View()
+		case i == p.selected:
+			text = t.S().Text.Bold(true).Width(textWidth).Render(step.Text)
+		case step.Keep:
+			text = t.S().Text.Width(textWidth).Render(step.Text)
+		default:
+			text = t.S().Subtle.Strikethrough(true).Width(textWidth).Render(step.Text)
+		}
+		lines[i] = lipgloss.JoinHorizontal(lipgloss.Top, t.S().Muted.Render(prefix), text)
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		core.Title("Review Plan", contentWidth),
+		"",
+		t.S().Muted.Render("Approving switches the session to normal mode and starts working on the kept steps."),
+		"",
+		strings.Join(lines, "\n"),
+		"",
+		p.help.View(p.keyMap),
+	)
+	return baseStyle.
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Width(p.width).
+		Render(content)
+}
+
+func (p *planDialogCmp) Position() (int, int) {
+	height := lipgloss.Height(p.View())
+	row := (p.wHeight / 2) - (height / 2)
+	col := (p.wWidth / 2) - (p.width / 2)
+	return max(0, row), max(0, col)
+}
+
+// ID implements PlanDialog.
+func (p *planDialogCmp) ID() dialogs.DialogID {
+	return PlanDialogID
+}
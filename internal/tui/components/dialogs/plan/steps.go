@@ -0,0 +1,38 @@
+package plan
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Step is a step of a plan and whether the user kept it.
+type Step struct {
+	Text string
+	Keep bool
+}
+
+var checklistItemRe = regexp.MustCompile(`^\s*[-*]\s+\[[ xX]\]\s+(.+)$`)
+
+// ParseSteps returns the Markdown checklist items in text as plan steps.
+func ParseSteps(text string) []Step {
+	var steps []Step
+	for line := range strings.SplitSeq(text, "\n") {
+		if m := checklistItemRe.FindStringSubmatch(line); m != nil {
+			steps = append(steps, Step{Text: strings.TrimSpace(m[1]), Keep: true})
+		}
+	}
+	return steps
+}
+
+// Prompt returns the message that asks the agent to carry out the steps the
+// user kept.
+func Prompt(steps []Step) string {
+	var b strings.Builder
+	b.WriteString("The plan is approved. Carry out the steps below in order, then report what was done.\n\n")
+	for _, step := range steps {
+		if step.Keep {
+			b.WriteString("- [ ] " + step.Text + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,37 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSteps(t *testing.T) {
+	t.Parallel()
+
+	text := "The config is loaded in `load.go`.\n\n" +
+		"- [ ] Add a `Mode` field to `Session`\n" +
+		"  * [x] Persist it in `Save`  \n" +
+		"- not a step\n" +
+		"- [ ]\n" +
+		"1. [ ] numbered lists are not checklists\n" +
+		"- [ ] Run the tests"
+	require.Equal(t, []Step{
+		{Text: "Add a `Mode` field to `Session`", Keep: true},
+		{Text: "Persist it in `Save`", Keep: true},
+		{Text: "Run the tests", Keep: true},
+	}, ParseSteps(text))
+	require.Empty(t, ParseSteps("What should the command be called?"))
+}
+
+func TestPrompt(t *testing.T) {
+	t.Parallel()
+
+	prompt := Prompt([]Step{
+		{Text: "Add the field", Keep: true},
+		{Text: "Drop the table", Keep: false},
+		{Text: "Run the tests", Keep: true},
+	})
+	require.Contains(t, prompt, "- [ ] Add the field\n- [ ] Run the tests\n")
+	require.NotContains(t, prompt, "Drop the table")
+}
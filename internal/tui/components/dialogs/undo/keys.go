@@ -0,0 +1,87 @@
+package undo
+
+import (
+	"github.com/charmbracelet/bubbles/v2/key"
+)
+
+// KeyMap defines the key bindings for the undo dialog.
+type KeyMap struct {
+	NextFile,
+	PreviousFile,
+	ScrollUp,
+	ScrollDown,
+	Y,
+	N,
+	Close key.Binding
+}
+
+// DefaultKeyMap returns the default key bindings for the undo dialog.
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		NextFile: key.NewBinding(
+			key.WithKeys("tab", "right", "l"),
+			key.WithHelp("tab", "next file"),
+		),
+		PreviousFile: key.NewBinding(
+			key.WithKeys("shift+tab", "left", "h"),
+			key.WithHelp("shift+tab", "previous file"),
+		),
+		ScrollUp: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑", "scroll up"),
+		),
+		ScrollDown: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓", "scroll down"),
+		),
+		Y: key.NewBinding(
+			key.WithKeys("y", "enter"),
+			key.WithHelp("y/enter", "undo"),
+		),
+		N: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "keep changes"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel"),
+		),
+	}
+}
+
+// KeyBindings implements layout.KeyMapProvider
+func (k KeyMap) KeyBindings() []key.Binding {
+	return []key.Binding{
+		k.NextFile,
+		k.PreviousFile,
+		k.ScrollUp,
+		k.ScrollDown,
+		k.Y,
+		k.N,
+		k.Close,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	m := [][]key.Binding{}
+	slice := k.KeyBindings()
+	for i := 0; i < len(slice); i += 4 {
+		end := min(i+4, len(slice))
+		m = append(m, slice[i:end])
+	}
+	return m
+}
+
+// ShortHelp implements help.KeyMap.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{
+		k.NextFile,
+		key.NewBinding(
+			key.WithKeys("down", "up"),
+			key.WithHelp("↑↓", "scroll"),
+		),
+		k.Y,
+		k.Close,
+	}
+}
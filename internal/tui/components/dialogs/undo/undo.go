@@ -0,0 +1,184 @@
+package undo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/help"
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/charmbracelet/crush/internal/app"
+	"github.com/charmbracelet/crush/internal/fsext"
+	"github.com/charmbracelet/crush/internal/tui/components/core"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/styles"
+	"github.com/charmbracelet/crush/internal/tui/util"
+)
+
+const UndoDialogID dialogs.DialogID = "undo"
+
+// UndoDialog previews and restores the file changes of a checkpoint.
+type UndoDialog interface {
+	dialogs.DialogModel
+}
+
+type undoDialogCmp struct {
+	wWidth, wHeight int
+	width, height   int
+	app             *app.App
+	checkpoint      app.Checkpoint
+	selectedFile    int
+	diffYOffset     int
+	keyMap          KeyMap
+	help            help.Model
+}
+
+// NewUndoDialogCmp creates a dialog that reverts the files changed since cp.
+func NewUndoDialogCmp(app *app.App, cp app.Checkpoint) UndoDialog {
+	t := styles.CurrentTheme()
+	help := help.New()
+	help.Styles = t.S().Help
+	return &undoDialogCmp{
+		app:        app,
+		checkpoint: cp,
+		keyMap:     DefaultKeyMap(),
+		help:       help,
+	}
+}
+
+func (u *undoDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (u *undoDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		u.wWidth = msg.Width
+		u.wHeight = msg.Height
+		u.width = min(120, int(float64(u.wWidth)*0.8))
+		u.height = int(float64(u.wHeight) * 0.8)
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, u.keyMap.NextFile):
+			u.selectedFile = (u.selectedFile + 1) % len(u.checkpoint.Files)
+			u.diffYOffset = 0
+		case key.Matches(msg, u.keyMap.PreviousFile):
+			u.selectedFile = (u.selectedFile - 1 + len(u.checkpoint.Files)) % len(u.checkpoint.Files)
+			u.diffYOffset = 0
+		case key.Matches(msg, u.keyMap.ScrollDown):
+			u.diffYOffset++
+		case key.Matches(msg, u.keyMap.ScrollUp):
+			u.diffYOffset = max(0, u.diffYOffset-1)
+		case key.Matches(msg, u.keyMap.Y):
+			return u, tea.Sequence(
+				util.CmdHandler(dialogs.CloseDialogMsg{}),
+				u.restore(),
+			)
+		case key.Matches(msg, u.keyMap.N), key.Matches(msg, u.keyMap.Close):
+			return u, util.CmdHandler(dialogs.CloseDialogMsg{})
+		}
+	case tea.MouseWheelMsg:
+		switch msg.Button {
+		case tea.MouseWheelDown:
+			u.diffYOffset++
+		case tea.MouseWheelUp:
+			u.diffYOffset = max(0, u.diffYOffset-1)
+		}
+	}
+	return u, nil
+}
+
+func (u *undoDialogCmp) restore() tea.Cmd {
+	cp := u.checkpoint
+	return func() tea.Msg {
+		if err := u.app.RestoreCheckpoint(context.Background(), cp); err != nil {
+			return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+		}
+		return util.InfoMsg{
+			Type: util.InfoTypeInfo,
+			Msg:  fmt.Sprintf("Restored %d file(s)", len(cp.Files)),
+		}
+	}
+}
+
+func (u *undoDialogCmp) View() string {
+	t := styles.CurrentTheme()
+	baseStyle := t.S().Base
+	contentWidth := u.width - 4
+
+	prompt := strings.TrimSpace(u.checkpoint.Message.Content().Text)
+	if i := strings.IndexByte(prompt, '\n'); i >= 0 {
+		prompt = prompt[:i]
+	}
+	header := t.S().Text.Width(contentWidth).Render(
+		ansi.Truncate(fmt.Sprintf("Revert %d file(s) to before: %q", len(u.checkpoint.Files), prompt), contentWidth, "…"),
+	)
+
+	files := make([]string, len(u.checkpoint.Files))
+	for i, f := range u.checkpoint.Files {
+		action := "restore"
+		if f.Delete {
+			action = "delete"
+		}
+		line := fmt.Sprintf("%s (%s)", fsext.PrettyPath(f.Path), action)
+		if i == u.selectedFile {
+			files[i] = t.S().Text.Bold(true).Render("> " + line)
+		} else {
+			files[i] = t.S().Muted.Render("  " + line)
+		}
+	}
+	fileList := strings.Join(files, "\n")
+
+	const chrome = 10 // title, header, buttons, help and spacing
+	diffHeight := max(5, u.height-chrome-lipgloss.Height(fileList))
+	selected := u.checkpoint.Files[u.selectedFile]
+	path := fsext.PrettyPath(selected.Path)
+	diff := core.DiffFormatter().
+		Before(path, selected.Current).
+		After(path, selected.Content).
+		Width(contentWidth).
+		Height(diffHeight).
+		YOffset(u.diffYOffset).
+		Unified().
+		String()
+
+	buttons := core.SelectableButtons([]core.ButtonOpts{
+		{Text: "Undo", UnderlineIndex: -1, Selected: true},
+		{Text: "Keep", UnderlineIndex: -1},
+	}, "  ")
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		core.Title("Undo Changes", contentWidth),
+		"",
+		header,
+		"",
+		fileList,
+		"",
+		diff,
+		"",
+		baseStyle.AlignHorizontal(lipgloss.Right).Width(contentWidth).Render(buttons),
+		u.help.View(u.keyMap),
+	)
+	return baseStyle.
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.BorderFocus).
+		Width(u.width).
+		Render(content)
+}
+
+func (u *undoDialogCmp) Position() (int, int) {
+	row := (u.wHeight / 2) - (u.height / 2)
+	col := (u.wWidth / 2) - (u.width / 2)
+	return max(0, row), max(0, col)
+}
+
+// ID implements UndoDialog.
+func (u *undoDialogCmp) ID() dialogs.DialogID {
+	return UndoDialogID
+}
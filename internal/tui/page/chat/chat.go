@@ -13,10 +13,12 @@ import (
 	"github.com/charmbracelet/crush/internal/app"
 	"github.com/charmbracelet/crush/internal/config"
 	"github.com/charmbracelet/crush/internal/history"
+	"github.com/charmbracelet/crush/internal/job"
 	"github.com/charmbracelet/crush/internal/message"
 	"github.com/charmbracelet/crush/internal/permission"
 	"github.com/charmbracelet/crush/internal/pubsub"
 	"github.com/charmbracelet/crush/internal/session"
+	"github.com/charmbracelet/crush/internal/todo"
 	"github.com/charmbracelet/crush/internal/tui/components/anim"
 	"github.com/charmbracelet/crush/internal/tui/components/chat"
 	"github.com/charmbracelet/crush/internal/tui/components/chat/editor"
@@ -31,6 +33,7 @@ import (
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/commands"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/filepicker"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/models"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/plan"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/reasoning"
 	"github.com/charmbracelet/crush/internal/tui/page"
 	"github.com/charmbracelet/crush/internal/tui/styles"
@@ -125,7 +128,7 @@ func New(app *app.App) ChatPage {
 		app:     app,
 		keyMap:  DefaultKeyMap(),
 		header:  hdr,
-		sidebar: sidebar.New(app.History, app.LSPClients, false),
+		sidebar: sidebar.New(app.History, app.Messages, app.Todos, app.Jobs, app.LSPClients, false),
 		chat:    chat.New(app),
 		editor: editor.New(editor.Dependencies{
 			Agent:       app.CoderAgent,
@@ -278,6 +281,9 @@ func (p *chatPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		u, cmd = p.sidebar.Update(msg)
 		p.sidebar = u.(sidebar.Sidebar)
 		cmds = append(cmds, cmd)
+		u, cmd = p.editor.Update(msg)
+		p.editor = u.(editor.Editor)
+		cmds = append(cmds, cmd)
 		return p, tea.Batch(cmds...)
 	case chat.SessionClearedMsg:
 		u, cmd := p.header.Update(msg)
@@ -308,6 +314,11 @@ func (p *chatPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case pubsub.Event[message.Message],
 		anim.StepMsg,
 		spinner.TickMsg:
+		if msg, ok := msg.(pubsub.Event[message.Message]); ok {
+			u, cmd := p.sidebar.Update(msg)
+			p.sidebar = u.(sidebar.Sidebar)
+			cmds = append(cmds, cmd)
+		}
 		if p.focusedPane == PanelTypeSplash {
 			u, cmd := p.splash.Update(msg)
 			p.splash = u.(splash.Splash)
@@ -319,12 +330,16 @@ func (p *chatPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		return p, tea.Batch(cmds...)
+	case commands.TogglePlanModeMsg:
+		return p, p.togglePlanMode()
+	case plan.ApprovedMsg:
+		return p, p.approvePlan(msg)
 	case commands.ToggleYoloModeMsg:
 		// update the editor style
 		u, cmd := p.editor.Update(msg)
 		p.editor = u.(editor.Editor)
 		return p, cmd
-	case pubsub.Event[history.File], sidebar.SessionFilesMsg:
+	case pubsub.Event[history.File], pubsub.Event[todo.List], pubsub.Event[job.Job], sidebar.SessionFilesMsg, sidebar.TurnUsageMsg, sidebar.TodosMsg, sidebar.JobsMsg:
 		u, cmd := p.sidebar.Update(msg)
 		p.sidebar = u.(sidebar.Sidebar)
 		cmds = append(cmds, cmd)
@@ -569,7 +584,7 @@ func (p *chatPage) openReasoningDialog() tea.Cmd {
 		providerCfg := cfg.GetProviderForModel(agentCfg.Model)
 
 		if providerCfg != nil && model != nil &&
-			providerCfg.Type == catwalk.TypeOpenAI && model.HasReasoningEffort {
+			(providerCfg.Type == catwalk.TypeOpenAI || providerCfg.Type == config.TypeOpenAIResponses) && model.HasReasoningEffort {
 			// Return the OpenDialogMsg directly so it bubbles up to the main TUI
 			return dialogs.OpenDialogMsg{
 				Model: reasoning.NewReasoningDialog(),
@@ -788,6 +803,66 @@ func (p *chatPage) sendMessage(text string, attachments []message.Attachment) te
 	return tea.Batch(cmds...)
 }
 
+// togglePlanMode switches the current session between plan and normal mode,
+// starting a session if there is none yet.
+func (p *chatPage) togglePlanMode() tea.Cmd {
+	ctx := context.Background()
+	sess := p.session
+	if sess.ID == "" {
+		newSession, err := p.app.Sessions.Create(ctx, "New Session")
+		if err != nil {
+			return util.ReportError(err)
+		}
+		sess = newSession
+	} else {
+		if p.app.CoderAgent != nil && p.app.CoderAgent.IsSessionBusy(sess.ID) {
+			return util.ReportWarn("Agent is busy, please wait...")
+		}
+		current, err := p.app.Sessions.Get(ctx, sess.ID)
+		if err != nil {
+			return util.ReportError(err)
+		}
+		sess = current
+	}
+
+	info := "Plan mode enabled: the agent will only read files until you approve a plan"
+	if sess.Mode == session.ModePlan {
+		sess.Mode = session.ModeNormal
+		info = "Plan mode disabled"
+	} else {
+		sess.Mode = session.ModePlan
+	}
+	sess, err := p.app.Sessions.Save(ctx, sess)
+	if err != nil {
+		return util.ReportError(err)
+	}
+	return tea.Batch(
+		util.CmdHandler(chat.SessionSelectedMsg(sess)),
+		util.ReportInfo(info),
+	)
+}
+
+// approvePlan switches the session of an approved plan back to normal mode
+// and has the agent carry out the plan.
+func (p *chatPage) approvePlan(msg plan.ApprovedMsg) tea.Cmd {
+	ctx := context.Background()
+	sess, err := p.app.Sessions.Get(ctx, msg.SessionID)
+	if err != nil {
+		return util.ReportError(err)
+	}
+	sess.Mode = session.ModeNormal
+	if _, err := p.app.Sessions.Save(ctx, sess); err != nil {
+		return util.ReportError(err)
+	}
+	if p.app.CoderAgent == nil {
+		return util.ReportError(fmt.Errorf("coder agent is not initialized"))
+	}
+	if _, err := p.app.CoderAgent.Run(ctx, sess.ID, plan.Prompt(msg.Steps)); err != nil {
+		return util.ReportError(err)
+	}
+	return tea.Batch(p.chat.GoToBottom(), util.ReportInfo("Plan approved"))
+}
+
 func (p *chatPage) Bindings() []key.Binding {
 	bindings := []key.Binding{
 		p.keyMap.NewSession,
@@ -1031,6 +1106,7 @@ func (p *chatPage) Help() help.KeyMap {
 				[]key.Binding{
 					messages.CopyKey,
 					messages.ClearSelectionKey,
+					messages.ForkKey,
 				},
 			)
 		case PanelTypeEditor:
@@ -87,11 +87,15 @@ func (fakeHistory) Subscribe(ctx context.Context) <-chan pubsub.Event[history.Fi
 	return ch
 }
 
-func (fakeHistory) Create(context.Context, string, string, string) (history.File, error) {
+func (fakeHistory) Create(context.Context, string, string, string, string) (history.File, error) {
 	return history.File{}, nil
 }
 
-func (fakeHistory) CreateVersion(context.Context, string, string, string) (history.File, error) {
+func (fakeHistory) CreateNew(context.Context, string, string, string) (history.File, error) {
+	return history.File{}, nil
+}
+
+func (fakeHistory) CreateVersion(context.Context, string, string, string, string) (history.File, error) {
 	return history.File{}, nil
 }
 func (fakeHistory) Get(context.Context, string) (history.File, error) { return history.File{}, nil }
@@ -109,6 +113,12 @@ func (fakeHistory) ListLatestSessionFiles(context.Context, string) ([]history.Fi
 func (fakeHistory) Delete(context.Context, string) error             { return nil }
 func (fakeHistory) DeleteSessionFiles(context.Context, string) error { return nil }
 
+func (fakeHistory) RestorePlan(context.Context, string, []string) ([]history.FileRestore, error) {
+	return nil, nil
+}
+
+func (fakeHistory) Restore(context.Context, string, []history.FileRestore) error { return nil }
+
 func minimalApp(cfg *config.Config) *app.App {
 	return &app.App{
 		LSPClients: make(map[string]*lspclient.Client),
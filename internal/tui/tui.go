@@ -2,6 +2,7 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"github.com/charmbracelet/crush/internal/llm/agent"
 	"github.com/charmbracelet/crush/internal/permission"
 	"github.com/charmbracelet/crush/internal/pubsub"
+	"github.com/charmbracelet/crush/internal/session"
 	cmpChat "github.com/charmbracelet/crush/internal/tui/components/chat"
 	"github.com/charmbracelet/crush/internal/tui/components/chat/splash"
 	"github.com/charmbracelet/crush/internal/tui/components/completions"
@@ -20,13 +22,17 @@ import (
 	"github.com/charmbracelet/crush/internal/tui/components/core/layout"
 	"github.com/charmbracelet/crush/internal/tui/components/core/status"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/budget"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/commands"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/compact"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/filepicker"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/grants"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/models"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/permissions"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/plan"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/quit"
 	"github.com/charmbracelet/crush/internal/tui/components/dialogs/sessions"
+	"github.com/charmbracelet/crush/internal/tui/components/dialogs/undo"
 	"github.com/charmbracelet/crush/internal/tui/page"
 	"github.com/charmbracelet/crush/internal/tui/page/chat"
 	"github.com/charmbracelet/crush/internal/tui/styles"
@@ -77,6 +83,9 @@ type appModel struct {
 
 	// Chat Page Specific
 	selectedSessionID string // The ID of the currently selected session
+
+	// Session to open on startup, if any.
+	initialSession *session.Session
 }
 
 // Init initializes the application model and returns initial commands.
@@ -96,6 +105,10 @@ func (a appModel) Init() tea.Cmd {
 
 	cmds = append(cmds, tea.EnableMouseAllMotion)
 
+	if a.initialSession != nil {
+		cmds = append(cmds, util.CmdHandler(cmpChat.SessionSelectedMsg(*a.initialSession)))
+	}
+
 	return tea.Batch(cmds...)
 }
 
@@ -186,6 +199,27 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, util.CmdHandler(dialogs.OpenDialogMsg{
 			Model: compact.NewCompactDialogCmp(a.app.CoderAgent, msg.SessionID, true),
 		})
+	// Undo
+	case commands.UndoMsg:
+		return a, func() tea.Msg {
+			cp, err := a.app.UndoCheckpoint(context.Background(), msg.SessionID)
+			if errors.Is(err, app.ErrNothingToUndo) {
+				return util.InfoMsg{Type: util.InfoTypeInfo, Msg: "Nothing to undo"}
+			}
+			if err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+			}
+			return dialogs.OpenDialogMsg{Model: undo.NewUndoDialogCmp(a.app, cp)}
+		}
+	// Permission grants
+	case commands.ManagePermissionGrantsMsg:
+		return a, func() tea.Msg {
+			list, err := a.app.Permissions.ListGrants(context.Background())
+			if err != nil {
+				return util.InfoMsg{Type: util.InfoTypeError, Msg: err.Error()}
+			}
+			return dialogs.OpenDialogMsg{Model: grants.NewGrantsDialogCmp(a.app.Permissions, list)}
+		}
 	case commands.QuitMsg:
 		return a, util.CmdHandler(dialogs.OpenDialogMsg{
 			Model: quit.NewQuitDialog(),
@@ -271,6 +305,8 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.app.Permissions.Grant(msg.Permission)
 		case permissions.PermissionAllowForSession:
 			a.app.Permissions.GrantPersistent(msg.Permission)
+		case permissions.PermissionAllowAlways:
+			a.app.Permissions.GrantAlways(msg.Permission)
 		case permissions.PermissionDeny:
 			a.app.Permissions.Deny(msg.Permission)
 		}
@@ -289,17 +325,27 @@ func (a *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, dialogCmd)
 		}
 
-		// Handle auto-compact logic
-		if payload.Done && payload.Type == agent.AgentEventTypeResponse && a.selectedSessionID != "" {
-			// Get current session to check token usage
-			session, err := a.app.Sessions.Get(context.Background(), a.selectedSessionID)
-			if err == nil {
-				model := a.app.CoderAgent.Model()
-				contextWindow := model.ContextWindow
-				tokens := session.CompletionTokens + session.PromptTokens
-				if (tokens >= int64(float64(contextWindow)*0.95)) && !config.Get().Options.DisableAutoSummarize { // Show compact confirmation dialog
+		if payload.Type == agent.AgentEventTypeFallback {
+			cmds = append(cmds, util.ReportWarn(payload.Progress))
+		}
+
+		if payload.Type == agent.AgentEventTypeCompact && payload.SessionID == a.selectedSessionID {
+			cmds = append(cmds, util.ReportInfo(payload.Progress))
+		}
+
+		if errors.Is(payload.Error, agent.ErrBudgetExceeded) && payload.Message.SessionID == a.selectedSessionID {
+			cmds = append(cmds, util.CmdHandler(dialogs.OpenDialogMsg{
+				Model: budget.NewBudgetDialogCmp(a.app.CoderAgent, payload.Message.SessionID, payload.Error),
+			}))
+		}
+
+		// Let the user review the plan the agent came up with in plan mode
+		if payload.Done && payload.Type == agent.AgentEventTypeResponse && payload.Message.SessionID == a.selectedSessionID {
+			sess, err := a.app.Sessions.Get(context.Background(), a.selectedSessionID)
+			if err == nil && sess.Mode == session.ModePlan {
+				if steps := plan.ParseSteps(payload.Message.Content().Text); len(steps) > 0 {
 					cmds = append(cmds, util.CmdHandler(dialogs.OpenDialogMsg{
-						Model: compact.NewCompactDialogCmp(a.app.CoderAgent, a.selectedSessionID, false),
+						Model: plan.NewPlanDialogCmp(sess.ID, steps),
 					}))
 				}
 			}
@@ -626,8 +672,9 @@ func (a *appModel) View() tea.View {
 	return view
 }
 
-// New creates and initializes a new TUI application model.
-func New(app *app.App) tea.Model {
+// New creates the TUI model. When initialSession is not nil, it is opened
+// on startup.
+func New(app *app.App, initialSession *session.Session) tea.Model {
 	chatPage := chat.New(app)
 	keyMap := DefaultKeyMap()
 	keyMap.pageBindings = chatPage.Bindings()
@@ -645,6 +692,8 @@ func New(app *app.App) tea.Model {
 
 		dialog:      dialogs.NewDialogCmp(),
 		completions: completions.New(),
+
+		initialSession: initialSession,
 	}
 
 	return model
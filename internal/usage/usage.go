@@ -0,0 +1,268 @@
+// Package usage aggregates the token usage and cost recorded on assistant
+// messages into reports.
+package usage
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/db"
+)
+
+// Record is the usage of a single model call.
+type Record struct {
+	SessionID        string
+	SessionTitle     string
+	Model            string
+	Provider         string
+	Time             time.Time
+	InputTokens      int64
+	OutputTokens     int64
+	CacheReadTokens  int64
+	CacheWriteTokens int64
+	Cost             float64
+}
+
+// Load returns the usage recorded since the given time, oldest first.
+func Load(ctx context.Context, q db.Querier, since time.Time) ([]Record, error) {
+	rows, err := q.ListMessageUsage(ctx, since.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load usage: %w", err)
+	}
+	records := make([]Record, len(rows))
+	for i, row := range rows {
+		records[i] = Record{
+			SessionID:        row.SessionID,
+			SessionTitle:     row.SessionTitle,
+			Model:            row.Model.String,
+			Provider:         row.Provider.String,
+			Time:             time.Unix(row.CreatedAt, 0),
+			InputTokens:      row.InputTokens,
+			OutputTokens:     row.OutputTokens,
+			CacheReadTokens:  row.CacheReadTokens,
+			CacheWriteTokens: row.CacheWriteTokens,
+			Cost:             row.Cost,
+		}
+	}
+	return records, nil
+}
+
+// GroupBy is the dimension usage is aggregated by.
+type GroupBy string
+
+const (
+	GroupByDay      GroupBy = "day"
+	GroupByWeek     GroupBy = "week"
+	GroupBySession  GroupBy = "session"
+	GroupByModel    GroupBy = "model"
+	GroupByProvider GroupBy = "provider"
+)
+
+// ParseGroupBy validates a grouping name.
+func ParseGroupBy(value string) (GroupBy, error) {
+	switch g := GroupBy(strings.ToLower(strings.TrimSpace(value))); g {
+	case GroupByDay, GroupByWeek, GroupBySession, GroupByModel, GroupByProvider:
+		return g, nil
+	case "":
+		return GroupByDay, nil
+	default:
+		return "", fmt.Errorf("invalid grouping %q (expected day, week, session, model or provider)", value)
+	}
+}
+
+// Row is the aggregated usage of one group.
+type Row struct {
+	Key              string  `json:"key"`
+	Title            string  `json:"title,omitempty"`
+	Calls            int     `json:"calls"`
+	InputTokens      int64   `json:"input_tokens"`
+	OutputTokens     int64   `json:"output_tokens"`
+	CacheReadTokens  int64   `json:"cache_read_tokens"`
+	CacheWriteTokens int64   `json:"cache_write_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+func (r *Row) add(rec Record) {
+	r.Calls++
+	r.InputTokens += rec.InputTokens
+	r.OutputTokens += rec.OutputTokens
+	r.CacheReadTokens += rec.CacheReadTokens
+	r.CacheWriteTokens += rec.CacheWriteTokens
+	r.Cost += rec.Cost
+}
+
+// Report is usage aggregated by one dimension.
+type Report struct {
+	GroupBy GroupBy `json:"group_by"`
+	Rows    []Row   `json:"rows"`
+	Total   Row     `json:"total"`
+}
+
+// Aggregate groups records by the given dimension. Time based groups are
+// sorted chronologically, all others by descending cost.
+func Aggregate(records []Record, by GroupBy) Report {
+	report := Report{GroupBy: by, Rows: []Row{}, Total: Row{Key: "total"}}
+	index := make(map[string]int)
+	for _, rec := range records {
+		key, title := groupKey(rec, by)
+		i, ok := index[key]
+		if !ok {
+			i = len(report.Rows)
+			index[key] = i
+			report.Rows = append(report.Rows, Row{Key: key, Title: title})
+		}
+		report.Rows[i].add(rec)
+		report.Total.add(rec)
+	}
+
+	switch by {
+	case GroupByDay, GroupByWeek:
+		slices.SortFunc(report.Rows, func(a, b Row) int {
+			return strings.Compare(a.Key, b.Key)
+		})
+	default:
+		slices.SortStableFunc(report.Rows, func(a, b Row) int {
+			return cmp.Compare(b.Cost, a.Cost)
+		})
+	}
+	return report
+}
+
+func groupKey(rec Record, by GroupBy) (key, title string) {
+	switch by {
+	case GroupByWeek:
+		year, week := rec.Time.Local().ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week), ""
+	case GroupBySession:
+		return rec.SessionID, rec.SessionTitle
+	case GroupByModel:
+		return orUnknown(rec.Provider) + "/" + orUnknown(rec.Model), ""
+	case GroupByProvider:
+		return orUnknown(rec.Provider), ""
+	default:
+		return rec.Time.Local().Format("2006-01-02"), ""
+	}
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+// Format is a report output format.
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatCSV   Format = "csv"
+	FormatJSON  Format = "json"
+)
+
+// ParseFormat validates a format name.
+func ParseFormat(value string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(value))); f {
+	case FormatTable, FormatCSV, FormatJSON:
+		return f, nil
+	case "":
+		return FormatTable, nil
+	default:
+		return "", fmt.Errorf("invalid format %q (expected table, csv or json)", value)
+	}
+}
+
+// Write renders the report in the given format.
+func Write(w io.Writer, format Format, report Report) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, report)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	default:
+		return WriteTable(w, report)
+	}
+}
+
+// WriteTable renders the report as an aligned table followed by a total.
+func WriteTable(w io.Writer, report Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\t", strings.ToUpper(string(report.GroupBy)))
+	if report.GroupBy == GroupBySession {
+		fmt.Fprint(tw, "TITLE\t")
+	}
+	fmt.Fprintln(tw, "CALLS\tINPUT\tOUTPUT\tCACHE READ\tCACHE WRITE\tCOST")
+	for _, row := range append(report.Rows, report.Total) {
+		key := row.Key
+		if report.GroupBy == GroupBySession && row.Key != report.Total.Key {
+			key = row.Key[:min(8, len(row.Key))]
+		}
+		fmt.Fprintf(tw, "%s\t", key)
+		if report.GroupBy == GroupBySession {
+			fmt.Fprintf(tw, "%s\t", truncate(row.Title, 40))
+		}
+		fmt.Fprintf(
+			tw,
+			"%d\t%d\t%d\t%d\t%d\t$%.4f\n",
+			row.Calls,
+			row.InputTokens,
+			row.OutputTokens,
+			row.CacheReadTokens,
+			row.CacheWriteTokens,
+			row.Cost,
+		)
+	}
+	return tw.Flush()
+}
+
+// WriteCSV renders the report as CSV with a header row. The total is not
+// included so the output can be loaded as is.
+func WriteCSV(w io.Writer, report Report) error {
+	cw := csv.NewWriter(w)
+	header := []string{string(report.GroupBy), "calls", "input_tokens", "output_tokens", "cache_read_tokens", "cache_write_tokens", "cost"}
+	if report.GroupBy == GroupBySession {
+		header = slices.Insert(header, 1, "title")
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, row := range report.Rows {
+		record := []string{
+			row.Key,
+			strconv.Itoa(row.Calls),
+			strconv.FormatInt(row.InputTokens, 10),
+			strconv.FormatInt(row.OutputTokens, 10),
+			strconv.FormatInt(row.CacheReadTokens, 10),
+			strconv.FormatInt(row.CacheWriteTokens, 10),
+			strconv.FormatFloat(row.Cost, 'f', 6, 64),
+		}
+		if report.GroupBy == GroupBySession {
+			record = slices.Insert(record, 1, row.Title)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func truncate(s string, limit int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
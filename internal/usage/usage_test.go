@@ -0,0 +1,109 @@
+package usage
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testRecords() []Record {
+	day1 := time.Date(2025, 10, 13, 10, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+	return []Record{
+		{SessionID: "s1", SessionTitle: "First", Provider: "anthropic", Model: "claude", Time: day1, InputTokens: 100, OutputTokens: 10, CacheReadTokens: 5, Cost: 0.5},
+		{SessionID: "s1", SessionTitle: "First", Provider: "anthropic", Model: "claude", Time: day1, InputTokens: 200, OutputTokens: 20, CacheWriteTokens: 7, Cost: 1},
+		{SessionID: "s2", SessionTitle: "Second", Provider: "openai", Model: "gpt", Time: day2, InputTokens: 50, OutputTokens: 5, Cost: 2},
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("by day", func(t *testing.T) {
+		t.Parallel()
+		report := Aggregate(testRecords(), GroupByDay)
+		require.Len(t, report.Rows, 2)
+		require.Equal(t, "2025-10-13", report.Rows[0].Key)
+		require.Equal(t, 2, report.Rows[0].Calls)
+		require.Equal(t, int64(300), report.Rows[0].InputTokens)
+		require.Equal(t, int64(5), report.Rows[0].CacheReadTokens)
+		require.Equal(t, int64(7), report.Rows[0].CacheWriteTokens)
+		require.InDelta(t, 1.5, report.Rows[0].Cost, 1e-9)
+		require.Equal(t, "2025-10-14", report.Rows[1].Key)
+
+		require.Equal(t, 3, report.Total.Calls)
+		require.InDelta(t, 3.5, report.Total.Cost, 1e-9)
+	})
+
+	t.Run("by week", func(t *testing.T) {
+		t.Parallel()
+		report := Aggregate(testRecords(), GroupByWeek)
+		require.Len(t, report.Rows, 1)
+		require.Equal(t, "2025-W42", report.Rows[0].Key)
+	})
+
+	t.Run("by model sorts by cost", func(t *testing.T) {
+		t.Parallel()
+		report := Aggregate(testRecords(), GroupByModel)
+		require.Len(t, report.Rows, 2)
+		require.Equal(t, "openai/gpt", report.Rows[0].Key)
+		require.Equal(t, "anthropic/claude", report.Rows[1].Key)
+	})
+
+	t.Run("by session keeps titles", func(t *testing.T) {
+		t.Parallel()
+		report := Aggregate(testRecords(), GroupBySession)
+		require.Equal(t, "s2", report.Rows[0].Key)
+		require.Equal(t, "Second", report.Rows[0].Title)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+		report := Aggregate(nil, GroupByProvider)
+		require.NotNil(t, report.Rows)
+		require.Zero(t, report.Total.Calls)
+	})
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+	report := Aggregate(testRecords(), GroupBySession)
+
+	var csvOut bytes.Buffer
+	require.NoError(t, Write(&csvOut, FormatCSV, report))
+	lines := strings.Split(strings.TrimSpace(csvOut.String()), "\n")
+	require.Equal(t, []string{
+		"session,title,calls,input_tokens,output_tokens,cache_read_tokens,cache_write_tokens,cost",
+		"s2,Second,1,50,5,0,0,2.000000",
+		"s1,First,2,300,30,5,7,1.500000",
+	}, lines)
+
+	var jsonOut bytes.Buffer
+	require.NoError(t, Write(&jsonOut, FormatJSON, report))
+	var decoded Report
+	require.NoError(t, json.Unmarshal(jsonOut.Bytes(), &decoded))
+	require.Equal(t, report, decoded)
+
+	var table bytes.Buffer
+	require.NoError(t, Write(&table, FormatTable, report))
+	require.Contains(t, table.String(), "SESSION")
+	require.Contains(t, table.String(), "$3.5000")
+}
+
+func TestParseGroupBy(t *testing.T) {
+	t.Parallel()
+	by, err := ParseGroupBy("")
+	require.NoError(t, err)
+	require.Equal(t, GroupByDay, by)
+
+	by, err = ParseGroupBy("Model")
+	require.NoError(t, err)
+	require.Equal(t, GroupByModel, by)
+
+	_, err = ParseGroupBy("month")
+	require.Error(t, err)
+}